@@ -135,11 +135,10 @@ func sweet(tcs []*toolchain) (err error) {
 		if err != nil {
 			return fmt.Errorf("searching for results for %s in %s: %v", tc.Name, resultsDir, err)
 		}
-		fmt.Printf("toolchain: %s\n", tc.Name)
 		for _, match := range matches {
-			// Print pkg and shortname tags because Sweet won't do it.
+			// Print the shortname tag because Sweet won't do it.
+			// Sweet prints pkg, toolchain and other configuration itself.
 			benchName := filepath.Base(filepath.Dir(match))
-			fmt.Printf("pkg: golang.org/x/benchmarks/sweet/benchmarks/%s\n", benchName)
 			fmt.Printf("shortname: sweet_%s\n", strings.ReplaceAll(benchName, "-", "_"))
 
 			// Dump results file.
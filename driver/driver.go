@@ -14,28 +14,38 @@ package driver // import "golang.org/x/benchmarks/driver"
 
 import (
 	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"runtime/pprof"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"golang.org/x/benchmarks/stats"
 )
 
 var (
-	flake     = flag.Int("flake", 0, "test flakiness of a benchmark")
-	benchNum  = flag.Int("benchnum", 1, "number of benchmark runs")
-	benchMem  = flag.Int("benchmem", 64, "approx RSS value to aim at in benchmarks, in MB")
-	benchTime = flag.Duration("benchtime", 5*time.Second, "run enough iterations of each benchmark to take the specified time")
-	affinity  = flag.Int("affinity", 0, "process affinity (passed to an OS-specific function like sched_setaffinity/SetProcessAffinityMask)")
-	tmpDir    = flag.String("tmpdir", os.TempDir(), "dir for temporary files")
-	genSvg    = flag.Bool("svg", false, "generate svg profiles")
+	flake      = flag.Int("flake", 0, "test flakiness of a benchmark")
+	benchNum   = flag.Int("benchnum", 1, "number of benchmark runs")
+	benchMem   = flag.Int("benchmem", 64, "approx RSS value to aim at in benchmarks, in MB")
+	benchTime  = flag.Duration("benchtime", 5*time.Second, "run enough iterations of each benchmark to take the specified time")
+	affinity   = flag.Int("affinity", 0, "process affinity (passed to an OS-specific function like sched_setaffinity/SetProcessAffinityMask)")
+	tmpDir     = flag.String("tmpdir", os.TempDir(), "dir for temporary files")
+	genSvg     = flag.Bool("svg", false, "generate svg profiles with 'go tool pprof', instead of summaries")
+	profileTop = flag.Int("profile-top", 50, "number of functions in profile summaries, or 0 for all")
+	quantiles  = flag.String("latency-quantiles", "50,95,99", "comma-separated `percentiles` of latencies to report")
+	jsonOut    = flag.Bool("json", false, "print results to stdout as JSON objects, one per line, and any other output to stderr")
 
 	BenchTime time.Duration
 	WorkDir   string
@@ -54,6 +64,10 @@ func Main(name string, f func() Result) {
 	// Copy to public variables, so that benchmarks can access the values.
 	BenchTime = *benchTime
 	WorkDir = *tmpDir
+	var err error
+	if latencyQuantiles, err = parseQuantiles(*quantiles); err != nil {
+		log.Fatal(err)
+	}
 
 	if *affinity != 0 {
 		setProcessAffinity(*affinity)
@@ -66,23 +80,37 @@ func Main(name string, f func() Result) {
 		return
 	}
 
-	fmt.Print("pkg: golang.org/x/benchmarks\n")
-	fmt.Printf("goos: %s\ngoarch: %s\n\n", runtime.GOOS, runtime.GOARCH)
+	r := &reporter{w: os.Stdout, json: *jsonOut}
+	if !r.json {
+		fmt.Fprintf(r.w, "pkg: %s\ngoos: %s\ngoarch: %s\n\n", pkg, runtime.GOOS, runtime.GOARCH)
+	}
 
 	stopTrace := startTrace()
 	defer stopTrace()
 
 	for i := 0; i < *benchNum; i++ {
 		res := f()
-		report(name, res)
+		r.report(name, res)
 	}
 }
 
+const pkg = "golang.org/x/benchmarks"
+
 func BenchMem() int {
 	usedBenchMem = true
 	return *benchMem
 }
 
+// Output returns where benchmarks should print anything other than their
+// results: stdout, or stderr with -json, so that stdout holds only the
+// JSON results.
+func Output() io.Writer {
+	if *jsonOut {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
 func setupWatchdog() {
 	t := *benchTime
 	// Be somewhat conservative, and build benchmark does not care about benchTime.
@@ -101,20 +129,20 @@ func setupWatchdog() {
 }
 
 // testFlakiness runs the function N+2 times and prints metrics diffs between
-// the second and subsequent runs.
+// the second and subsequent runs to stderr.
 func testFlakiness(f func() Result, N int) {
 	res := make([]Result, N+2)
 	for i := range res {
 		res[i] = f()
 	}
-	fmt.Printf("\n")
-	for k, v := range res[0].Metrics {
-		fmt.Printf("%v:\t", k)
+	fmt.Fprintf(os.Stderr, "\n")
+	for k, v := range res[0].metrics() {
+		fmt.Fprintf(os.Stderr, "%v:\t", k)
 		for i := 2; i < len(res); i++ {
-			d := 100*float64(v)/float64(res[i].Metrics[k]) - 100
-			fmt.Printf(" %+.2f%%", d)
+			d := 100*v/res[i].metrics()[k] - 100
+			fmt.Fprintf(os.Stderr, " %+.2f%%", d)
 		}
-		fmt.Printf("\n")
+		fmt.Fprintf(os.Stderr, "\n")
 	}
 }
 
@@ -124,35 +152,105 @@ type Result struct {
 	Duration time.Duration // total run duration
 	RunTime  uint64        // ns/op
 	Metrics  map[string]uint64
-	Files    map[string]string
+	Files    map[string]string // profiles and other files, by kind, such as "cpuprof"
+
+	// FloatMetrics holds metrics that aren't whole numbers, such as
+	// ratios. A metric must not be in both Metrics and FloatMetrics.
+	FloatMetrics map[string]float64
 }
 
 func MakeResult() Result {
-	return Result{Metrics: make(map[string]uint64), Files: make(map[string]string)}
+	return Result{Metrics: make(map[string]uint64), FloatMetrics: make(map[string]float64), Files: make(map[string]string)}
 }
 
-func report(name string, res Result) {
-	for name, path := range res.Files {
-		fmt.Printf("# %s=%s\n", name, path)
+// metrics returns all of the metrics of res.
+func (res *Result) metrics() map[string]float64 {
+	m := make(map[string]float64, len(res.Metrics)+len(res.FloatMetrics))
+	for k, v := range res.Metrics {
+		m[k] = float64(v)
+	}
+	for k, v := range res.FloatMetrics {
+		m[k] = v
 	}
+	return m
+}
+
+// reporter prints results, either in the Go benchmark format, or as JSON.
+type reporter struct {
+	w    io.Writer
+	json bool
+
+	// files is the files of the last result, which, in the benchmark
+	// format, are configuration lines that apply until they're changed.
+	files map[string]string
+}
+
+// jsonResult is the JSON form of a result.
+type jsonResult struct {
+	Name    string             `json:"name"`
+	Procs   int                `json:"procs"`
+	Iters   uint64             `json:"iters"`
+	Config  map[string]string  `json:"config"`
+	Metrics map[string]float64 `json:"metrics"`
+	Files   map[string]string  `json:"files,omitempty"`
+}
 
+func (r *reporter) report(name string, res Result) {
 	if usedBenchMem {
 		name = fmt.Sprintf("%s/benchmem-MB=%d", name, *benchMem)
 	}
-	fmt.Printf("Benchmark%s-%d %8d\t%10d ns/op", name, runtime.GOMAXPROCS(-1), res.N, res.RunTime)
-	var metrics []string
-	for metric := range res.Metrics {
-		if metric == "ns/op" {
-			// Already reported from res.RunTime.
-			continue
+	procs := runtime.GOMAXPROCS(-1)
+	metrics := res.metrics()
+	metrics["ns/op"] = float64(res.RunTime)
+
+	if r.json {
+		b, err := json.Marshal(&jsonResult{
+			Name:    name,
+			Procs:   procs,
+			Iters:   res.N,
+			Config:  map[string]string{"pkg": pkg, "goos": runtime.GOOS, "goarch": runtime.GOARCH},
+			Metrics: metrics,
+			Files:   res.Files,
+		})
+		if err != nil {
+			log.Fatalf("Failed to encode result: %v", err)
+		}
+		fmt.Fprintf(r.w, "%s\n", b)
+		return
+	}
+
+	// Report each file as a configuration line, such as
+	// "cpuprof-file: /tmp/2.prof.txt", and clear those of the last
+	// result that this one doesn't have.
+	var kinds []string
+	for kind := range r.files {
+		if _, ok := res.Files[kind]; !ok {
+			kinds = append(kinds, kind)
+		}
+	}
+	for kind, path := range res.Files {
+		if r.files[kind] != path {
+			kinds = append(kinds, kind)
 		}
-		metrics = append(metrics, metric)
 	}
-	sort.Strings(metrics)
-	for _, metric := range metrics {
-		fmt.Printf("\t%10d %s", res.Metrics[metric], metric)
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(r.w, "%s-file: %s\n", kind, res.Files[kind])
 	}
-	fmt.Printf("\n")
+	r.files = res.Files
+
+	fmt.Fprintf(r.w, "Benchmark%s-%d %8d\t%10d ns/op", name, procs, res.N, res.RunTime)
+	names := make([]string, 0, len(metrics))
+	for metric := range metrics {
+		if metric != "ns/op" {
+			names = append(names, metric)
+		}
+	}
+	sort.Strings(names)
+	for _, metric := range names {
+		fmt.Fprintf(r.w, "\t%10s %s", strconv.FormatFloat(metrics[metric], 'f', -1, 64), metric)
+	}
+	fmt.Fprintf(r.w, "\n")
 }
 
 // Benchmark runs f several times, collects stats,
@@ -160,45 +258,45 @@ func report(name string, res Result) {
 func Benchmark(f func(uint64)) Result {
 	res := runBenchmark(f)
 
-	cpuprof := processProfile(os.Args[0], res.Files["cpuprof"])
+	cpuprof, memprof0, memprof := res.Files["cpuprof"], res.Files["memprof0"], res.Files["memprof"]
 	delete(res.Files, "cpuprof")
-	if cpuprof != "" {
-		res.Files["cpuprof"] = cpuprof
-	}
-
-	memprof := processProfile("--lines", "--unit=byte", "--alloc_space", "--base", res.Files["memprof0"], os.Args[0], res.Files["memprof"])
-	delete(res.Files, "memprof")
 	delete(res.Files, "memprof0")
-	if memprof != "" {
-		res.Files["memprof"] = memprof
+	delete(res.Files, "memprof")
+
+	if !*genSvg {
+		// Summarize the profiles as the top functions by CPU time and by
+		// allocations during the run.
+		summarizeProfile(&res, "cpuprof", cpuprof, "", "cpu")
+		summarizeProfile(&res, "memprof", memprof, memprof0, "alloc_space", "alloc_objects")
+		return res
 	}
 
+	if svg := processProfile(os.Args[0], cpuprof); svg != "" {
+		res.Files["cpuprof"] = svg
+	}
+	if svg := processProfile("--lines", "--unit=byte", "--alloc_space", "--base", memprof0, os.Args[0], memprof); svg != "" {
+		res.Files["memprof"] = svg
+	}
 	return res
 }
 
-// processProfile invokes 'go tool pprof' with the specified args
+// processProfile invokes 'go tool pprof --svg' with the specified args
 // and returns name of the resulting file, or an empty string.
 func processProfile(args ...string) string {
-	fname := "prof.txt"
-	typ := "--text"
-	if *genSvg {
-		fname = "prof.svg"
-		typ = "--svg"
-	}
-	proff, err := os.Create(tempFilename(fname))
+	proff, err := os.Create(tempFilename("prof.svg"))
 	if err != nil {
 		log.Printf("Failed to create profile file: %v", err)
 		return ""
 	}
 	defer proff.Close()
 	var proflog bytes.Buffer
-	cmdargs := append([]string{"tool", "pprof", typ}, args...)
+	cmdargs := append([]string{"tool", "pprof", "--svg"}, args...)
 	cmd := exec.Command("go", cmdargs...)
 	cmd.Stdout = proff
 	cmd.Stderr = &proflog
 	err = cmd.Run()
 	if err != nil {
-		log.Printf("go tool pprof cpuprof failed: %v\n%v", err, proflog.String())
+		log.Printf("go tool pprof failed: %v\n%v", err, proflog.String())
 		return "" // Deliberately ignore the error.
 	}
 	return proff.Name()
@@ -217,7 +315,7 @@ func runBenchmark(f func(uint64)) Result {
 
 // runBenchmarkOnce runs f once and collects all performance metrics and profiles.
 func runBenchmarkOnce(f func(uint64), N uint64) Result {
-	latencyInit(N)
+	latencyInit()
 	runtime.GC()
 	mstats0 := new(runtime.MemStats)
 	runtime.ReadMemStats(mstats0)
@@ -276,6 +374,10 @@ func runBenchmarkOnce(f func(uint64), N uint64) Result {
 }
 
 // Parallel is a public helper function that runs f N times in P*GOMAXPROCS goroutines.
+// It records the latency of each call of f, which is reported as the
+// quantiles of the distribution of latencies selected by -latency-quantiles,
+// and saved in full as the "latency" file of the result. Each goroutine
+// records into its own LatencyRecorder, so they don't contend.
 func Parallel(N uint64, P int, f func()) {
 	numProcs := P * runtime.GOMAXPROCS(0)
 	var wg sync.WaitGroup
@@ -283,58 +385,112 @@ func Parallel(N uint64, P int, f func()) {
 	for p := 0; p < numProcs; p++ {
 		go func() {
 			defer wg.Done()
+			r := NewLatencyRecorder()
+			defer r.Flush()
 			for int64(atomic.AddUint64(&N, ^uint64(0))) >= 0 {
+				t0 := time.Now()
 				f()
+				r.Note(t0)
 			}
 		}()
 	}
 	wg.Wait()
 }
 
-// perfLatency collects and reports information about latencies.
+// latency is the distribution of the latencies recorded by LatencyNote and
+// LatencyRecorders in the current run, in nanoseconds.
 var latency struct {
-	data latencyData
-	idx  int32
+	sync.Mutex
+	d *stats.TDigest
 }
 
-type latencyData []uint64
-
-func (p latencyData) Len() int           { return len(p) }
-func (p latencyData) Less(i, j int) bool { return p[i] < p[j] }
-func (p latencyData) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
-
-func latencyInit(N uint64) {
-	// Use fixed size slice to:
-	// - bound the amount of memory consumed
-	// - eliminate variance in runs that use different number of iterations
-	latency.data = make(latencyData, 1e6)
-	latency.idx = 0
+func latencyInit() {
+	latency.Lock()
+	latency.d = stats.NewTDigest(0)
+	latency.Unlock()
 }
 
+// LatencyNote records the latency of an operation that started at t.
+//
+// Deprecated: LatencyNote serializes all its callers, which skews the
+// latencies of concurrent operations. Use Parallel, which records the
+// latencies of its calls, or a LatencyRecorder per goroutine.
 func LatencyNote(t time.Time) {
 	d := time.Since(t)
-	if int(atomic.LoadInt32(&latency.idx)) >= len(latency.data) {
-		return
+	latency.Lock()
+	if latency.d != nil {
+		latency.d.Add(float64(d))
 	}
-	i := atomic.AddInt32(&latency.idx, 1) - 1
-	if int(i) >= len(latency.data) {
-		return
+	latency.Unlock()
+}
+
+// A LatencyRecorder records latencies of operations in one goroutine,
+// without contending with other goroutines. The latencies are added to
+// those of the current run when it's flushed.
+type LatencyRecorder struct {
+	d *stats.TDigest
+}
+
+// NewLatencyRecorder returns a new, empty LatencyRecorder.
+func NewLatencyRecorder() *LatencyRecorder {
+	return &LatencyRecorder{d: stats.NewTDigest(0)}
+}
+
+// Note records the latency of an operation that started at t.
+func (r *LatencyRecorder) Note(t time.Time) {
+	r.d.Add(float64(time.Since(t)))
+}
+
+// Flush adds the latencies recorded by r to those of the current run,
+// and empties r.
+func (r *LatencyRecorder) Flush() {
+	latency.Lock()
+	if latency.d != nil {
+		latency.d.Merge(r.d)
 	}
-	latency.data[i] = uint64(d)
+	latency.Unlock()
+	r.d = stats.NewTDigest(0)
 }
 
+// latencyCollect adds the selected quantiles of the latencies to res, as
+// metrics such as "P99-ns/op", and the latency file.
 func latencyCollect(res *Result) {
-	cnt := int(latency.idx)
-	if cnt == 0 {
+	latency.Lock()
+	defer latency.Unlock()
+	if latency.d == nil || latency.d.Count() == 0 {
+		return
+	}
+	for _, p := range latencyQuantiles {
+		name := "P" + strconv.FormatFloat(p, 'f', -1, 64) + "-ns/op"
+		res.Metrics[name] = uint64(math.Round(latency.d.Quantile(p / 100)))
+	}
+	b, err := json.Marshal(latency.d)
+	if err != nil {
+		log.Printf("Failed to encode latencies: %v", err)
+		return
+	}
+	fname := tempFilename("latency.json")
+	if err := os.WriteFile(fname, b, 0666); err != nil {
+		log.Printf("Failed to write latencies: %v", err)
 		return
 	}
-	if cnt > len(latency.data) {
-		cnt = len(latency.data)
+	res.Files["latency"] = fname
+}
+
+// latencyQuantiles are the quantiles of latencies reported, as
+// percentages, parsed from -latency-quantiles.
+var latencyQuantiles = []float64{50, 95, 99}
+
+func parseQuantiles(s string) ([]float64, error) {
+	var qs []float64
+	for _, f := range strings.Split(s, ",") {
+		q, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil || !(q >= 0 && q <= 100) {
+			return nil, fmt.Errorf("invalid latency quantile %q: must be a percentage", f)
+		}
+		qs = append(qs, q)
 	}
-	sort.Sort(latency.data[:cnt])
-	res.Metrics["P50-ns/op"] = latency.data[cnt*50/100]
-	res.Metrics["P95-ns/op"] = latency.data[cnt*95/100]
-	res.Metrics["P99-ns/op"] = latency.data[cnt*99/100]
+	return qs, nil
 }
 
 // chooseN chooses the next number of iterations for benchmark.
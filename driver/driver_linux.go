@@ -39,6 +39,11 @@ func RunUnderProfiler(args ...string) (string, string) {
 	return perf1, perf2
 }
 
+// perfReport runs 'perf report' with args on perf.data, and returns the
+// name of a file with the first lines of its output, or "" on failure.
+// Unlike the profiles of the benchmark itself, which are summarized
+// in-process, this shells out: perf.data profiles another process, and
+// there's no Go package to read it.
 func perfReport(args ...string) string {
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
@@ -0,0 +1,123 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package driver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"golang.org/x/benchmarks/stats"
+)
+
+func testResult(files map[string]string) Result {
+	res := MakeResult()
+	res.N = 10
+	res.RunTime = 1500
+	res.Metrics["ns/op"] = 1500
+	res.Metrics["allocs/op"] = 123456789
+	res.FloatMetrics["hit-ratio"] = 0.25
+	res.Files = files
+	return res
+}
+
+func TestReportText(t *testing.T) {
+	var buf bytes.Buffer
+	r := &reporter{w: &buf}
+	r.report("Foo", testResult(map[string]string{"cpuprof": "/tmp/1.prof.txt", "memprof": "/tmp/2.prof.txt"}))
+	r.report("Foo", testResult(map[string]string{"cpuprof": "/tmp/3.prof.txt"}))
+	r.report("Foo", testResult(map[string]string{"cpuprof": "/tmp/3.prof.txt"}))
+
+	line := fmt.Sprintf("BenchmarkFoo-%d       10\t      1500 ns/op\t 123456789 allocs/op\t      0.25 hit-ratio\n", runtime.GOMAXPROCS(-1))
+	expected := "cpuprof-file: /tmp/1.prof.txt\nmemprof-file: /tmp/2.prof.txt\n" + line +
+		"cpuprof-file: /tmp/3.prof.txt\nmemprof-file: \n" + line +
+		line
+	if got := buf.String(); got != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestReportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	r := &reporter{w: &buf, json: true}
+	r.report("Foo", testResult(map[string]string{"cpuprof": "/tmp/1.prof.txt"}))
+	r.report("Foo", testResult(nil))
+
+	dec := json.NewDecoder(&buf)
+	for i, files := range []map[string]string{{"cpuprof": "/tmp/1.prof.txt"}, nil} {
+		var got jsonResult
+		if err := dec.Decode(&got); err != nil {
+			t.Fatalf("decoding result %d: %v", i, err)
+		}
+		expected := jsonResult{
+			Name:    "Foo",
+			Procs:   runtime.GOMAXPROCS(-1),
+			Iters:   10,
+			Config:  map[string]string{"pkg": pkg, "goos": runtime.GOOS, "goarch": runtime.GOARCH},
+			Metrics: map[string]float64{"ns/op": 1500, "allocs/op": 123456789, "hit-ratio": 0.25},
+			Files:   files,
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("result %d is %+v, expected %+v", i, got, expected)
+		}
+	}
+}
+
+func TestParallel(t *testing.T) {
+	*tmpDir = t.TempDir()
+	defer func(qs []float64) { latencyQuantiles = qs }(latencyQuantiles)
+	latencyQuantiles = []float64{50, 99.9}
+
+	// More than the old limit of 1e6 samples.
+	const N = 2e6
+	latencyInit()
+	Parallel(N, 2, func() {})
+	r := NewLatencyRecorder()
+	r.Note(time.Now())
+	r.Flush()
+	r.Flush() // Flushing again adds nothing.
+	res := MakeResult()
+	latencyCollect(&res)
+
+	for _, m := range []string{"P50-ns/op", "P99.9-ns/op"} {
+		if _, ok := res.Metrics[m]; !ok {
+			t.Errorf("metric %s is missing from %v", m, res.Metrics)
+		}
+	}
+	if res.Metrics["P50-ns/op"] > res.Metrics["P99.9-ns/op"] {
+		t.Errorf("P50 latency %d is greater than P99.9 latency %d", res.Metrics["P50-ns/op"], res.Metrics["P99.9-ns/op"])
+	}
+	b, err := os.ReadFile(res.Files["latency"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d stats.TDigest
+	if err := json.Unmarshal(b, &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Count() != N+1 {
+		t.Errorf("latency file has %v latencies, expected %v", d.Count(), N+1)
+	}
+}
+
+func TestParseQuantiles(t *testing.T) {
+	qs, err := parseQuantiles("50, 99.9,100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []float64{50, 99.9, 100}; !reflect.DeepEqual(qs, expected) {
+		t.Errorf("parseQuantiles = %v, expected %v", qs, expected)
+	}
+	for _, bad := range []string{"", "50,", "p99", "101", "-1"} {
+		if _, err := parseQuantiles(bad); err == nil {
+			t.Errorf("parseQuantiles(%q) succeeded, expected an error", bad)
+		}
+	}
+}
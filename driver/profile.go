@@ -0,0 +1,255 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package driver
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/google/pprof/profile"
+)
+
+// profileTable attributes one type of sample in a profile, such as CPU
+// time or allocated bytes, to the functions with the greatest flat values.
+type profileTable struct {
+	SampleType string         `json:"sampleType"`
+	Unit       string         `json:"unit"`
+	Total      int64          `json:"total"`
+	Functions  []functionStat `json:"functions"`
+}
+
+// functionStat is the value attributed to a function in a profile: Flat
+// to the function itself, and Cum to it and the functions it calls.
+type functionStat struct {
+	Name string `json:"name"`
+	Flat int64  `json:"flat"`
+	Cum  int64  `json:"cum"`
+}
+
+// summarizeProfile summarizes the samples of each of the given types in
+// the profile at path, less those in the profile at base, if it's not
+// empty, and adds the summary to res as the file kind, in text, and the
+// file kind+"-json". Any errors are logged.
+func summarizeProfile(res *Result, kind, path, base string, sampleTypes ...string) {
+	tables, err := profileTables(path, base, sampleTypes, *profileTop)
+	if err != nil {
+		log.Printf("Failed to summarize %s: %v", kind, err)
+		return
+	}
+	txt, err := writeTempFile(kind+".txt", func(w io.Writer) error {
+		for i, t := range tables {
+			if i > 0 {
+				fmt.Fprintf(w, "\n")
+			}
+			t.writeText(w)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to write %s summary: %v", kind, err)
+		return
+	}
+	js, err := writeTempFile(kind+".json", func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(tables)
+	})
+	if err != nil {
+		log.Printf("Failed to write %s summary: %v", kind, err)
+		return
+	}
+	res.Files[kind] = txt
+	res.Files[kind+"-json"] = js
+}
+
+func writeTempFile(ext string, write func(io.Writer) error) (string, error) {
+	f, err := os.Create(tempFilename(ext))
+	if err != nil {
+		return "", err
+	}
+	w := bufio.NewWriter(f)
+	if err := write(w); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+func readProfile(path string) (*profile.Profile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return profile.Parse(f)
+}
+
+// profileTables returns a table of the top n functions for each of the
+// given sample types in the profile at path, less those in the profile at
+// base, if it's not empty.
+func profileTables(path, base string, sampleTypes []string, n int) ([]profileTable, error) {
+	p, err := readProfile(path)
+	if err != nil {
+		return nil, err
+	}
+	var bp *profile.Profile
+	if base != "" {
+		if bp, err = readProfile(base); err != nil {
+			return nil, err
+		}
+	}
+	var tables []profileTable
+	for _, st := range sampleTypes {
+		t, ok := newProfileTable(p, bp, st)
+		if !ok {
+			return nil, fmt.Errorf("%s has no %s samples", path, st)
+		}
+		sort.Slice(t.Functions, func(i, j int) bool {
+			a, b := t.Functions[i], t.Functions[j]
+			if a.Flat != b.Flat {
+				return a.Flat > b.Flat
+			}
+			if a.Cum != b.Cum {
+				return a.Cum > b.Cum
+			}
+			return a.Name < b.Name
+		})
+		if n > 0 && len(t.Functions) > n {
+			t.Functions = t.Functions[:n]
+		}
+		tables = append(tables, t)
+	}
+	return tables, nil
+}
+
+// newProfileTable returns the table of all functions for sampleType in p,
+// less those in base, if it's not nil.
+func newProfileTable(p, base *profile.Profile, sampleType string) (profileTable, bool) {
+	idx := sampleIndex(p, sampleType)
+	if idx < 0 {
+		return profileTable{}, false
+	}
+	t := profileTable{SampleType: sampleType, Unit: p.SampleType[idx].Unit}
+	stats := make(map[string]*functionStat)
+	t.Total = addSamples(stats, p, idx, 1)
+	if base != nil {
+		bidx := sampleIndex(base, sampleType)
+		if bidx < 0 {
+			return profileTable{}, false
+		}
+		t.Total += addSamples(stats, base, bidx, -1)
+	}
+	for _, s := range stats {
+		if s.Flat != 0 || s.Cum != 0 {
+			t.Functions = append(t.Functions, *s)
+		}
+	}
+	return t, true
+}
+
+func sampleIndex(p *profile.Profile, sampleType string) int {
+	for i, st := range p.SampleType {
+		if st.Type == sampleType {
+			return i
+		}
+	}
+	return -1
+}
+
+// addSamples adds sign times the values at idx of the samples in p to the
+// functions in stats, and returns their total. The flat value of a sample
+// is attributed to its innermost function, including inlined functions,
+// and the cumulative value to each function in its stack, once.
+func addSamples(stats map[string]*functionStat, p *profile.Profile, idx int, sign int64) int64 {
+	get := func(name string) *functionStat {
+		s := stats[name]
+		if s == nil {
+			s = &functionStat{Name: name}
+			stats[name] = s
+		}
+		return s
+	}
+	var total int64
+	seen := make(map[string]bool)
+	for _, sample := range p.Sample {
+		v := sign * sample.Value[idx]
+		if v == 0 {
+			continue
+		}
+		total += v
+		for k := range seen {
+			delete(seen, k)
+		}
+		leaf := true
+		for _, loc := range sample.Location {
+			lines := loc.Line
+			if len(lines) == 0 {
+				// Unsymbolized.
+				lines = []profile.Line{{Function: &profile.Function{Name: fmt.Sprintf("%#x", loc.Address)}}}
+			}
+			for _, line := range lines {
+				if line.Function == nil {
+					continue
+				}
+				name := line.Function.Name
+				if leaf {
+					get(name).Flat += v
+					leaf = false
+				}
+				if !seen[name] {
+					seen[name] = true
+					get(name).Cum += v
+				}
+			}
+		}
+	}
+	return total
+}
+
+// writeText writes t in the style of pprof's -text output.
+func (t *profileTable) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Type: %s\n", t.SampleType)
+	fmt.Fprintf(w, "Total: %s\n", formatProfileValue(t.Total, t.Unit))
+	fmt.Fprintf(w, "%10s %7s %7s %10s %7s\n", "flat", "flat%", "sum%", "cum", "cum%")
+	var sum int64
+	for _, f := range t.Functions {
+		sum += f.Flat
+		fmt.Fprintf(w, "%10s %7s %7s %10s %7s  %s\n",
+			formatProfileValue(f.Flat, t.Unit), percent(f.Flat, t.Total), percent(sum, t.Total),
+			formatProfileValue(f.Cum, t.Unit), percent(f.Cum, t.Total), f.Name)
+	}
+}
+
+func percent(v, total int64) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", 100*float64(v)/float64(total))
+}
+
+// formatProfileValue formats v, in the given unit, for people to read.
+func formatProfileValue(v int64, unit string) string {
+	switch unit {
+	case "nanoseconds":
+		return time.Duration(v).Round(10 * time.Microsecond).String()
+	case "bytes":
+		x := float64(v)
+		for _, u := range []string{"B", "kB", "MB", "GB"} {
+			if x < 1024 && x > -1024 || u == "GB" {
+				return fmt.Sprintf("%.4g%s", x, u)
+			}
+			x /= 1024
+		}
+	}
+	return fmt.Sprintf("%d", v)
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package driver
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/pprof/profile"
+)
+
+// testProfile returns a heap profile with a sample with the given values
+// for each stack, which list functions from the innermost out.
+func testProfile(stacks map[string][2]int64) *profile.Profile {
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "alloc_objects", Unit: "count"}, {Type: "alloc_space", Unit: "bytes"}},
+	}
+	funcs := make(map[string]*profile.Function)
+	for stack, v := range stacks {
+		s := &profile.Sample{Value: []int64{v[0], v[1]}}
+		for _, name := range strings.Split(stack, " ") {
+			f := funcs[name]
+			if f == nil {
+				f = &profile.Function{ID: uint64(len(funcs) + 1), Name: name}
+				funcs[name] = f
+				p.Function = append(p.Function, f)
+			}
+			loc := &profile.Location{ID: uint64(len(p.Location) + 1), Line: []profile.Line{{Function: f}}}
+			p.Location = append(p.Location, loc)
+			s.Location = append(s.Location, loc)
+		}
+		p.Sample = append(p.Sample, s)
+	}
+	return p
+}
+
+func writeProfile(t *testing.T, p *profile.Profile) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "prof")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := p.Write(f); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProfileTables(t *testing.T) {
+	base := writeProfile(t, testProfile(map[string][2]int64{
+		"alloc main.run main.main": {1, 100},
+	}))
+	path := writeProfile(t, testProfile(map[string][2]int64{
+		"alloc main.run main.main":             {4, 400},
+		"alloc main.helper main.run main.main": {2, 1000},
+		"main.run main.main":                   {1, 50},
+		// Recursion counts once towards cum.
+		"main.rec main.rec main.main": {1, 10},
+	}))
+
+	tables, err := profileTables(path, base, []string{"alloc_space"}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []profileTable{{
+		SampleType: "alloc_space",
+		Unit:       "bytes",
+		Total:      1360,
+		Functions: []functionStat{
+			{"alloc", 1300, 1300},
+			{"main.run", 50, 1350},
+			{"main.rec", 10, 10},
+		},
+	}}
+	if !reflect.DeepEqual(tables, expected) {
+		t.Errorf("got %+v, expected %+v", tables, expected)
+	}
+
+	if _, err := profileTables(path, "", []string{"cpu"}, 0); err == nil {
+		t.Errorf("summarizing missing sample type succeeded, expected an error")
+	}
+}
+
+func TestSummarizeProfile(t *testing.T) {
+	*tmpDir = t.TempDir()
+	path := writeProfile(t, testProfile(map[string][2]int64{
+		"alloc main.main": {3, 3 << 20},
+		"main.main":       {1, 1 << 20},
+	}))
+	res := MakeResult()
+	summarizeProfile(&res, "memprof", path, "", "alloc_space", "alloc_objects")
+
+	txt, err := os.ReadFile(res.Files["memprof"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `Type: alloc_space
+Total: 4MB
+      flat   flat%    sum%        cum    cum%
+       3MB  75.00%  75.00%        3MB  75.00%  alloc
+       1MB  25.00% 100.00%        4MB 100.00%  main.main
+
+Type: alloc_objects
+Total: 4
+      flat   flat%    sum%        cum    cum%
+         3  75.00%  75.00%          3  75.00%  alloc
+         1  25.00% 100.00%          4 100.00%  main.main
+`
+	if string(txt) != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", txt, expected)
+	}
+	if _, err := os.Stat(res.Files["memprof-json"]); err != nil {
+		t.Error(err)
+	}
+}
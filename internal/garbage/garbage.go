@@ -0,0 +1,126 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package garbage is the workload of the garbage benchmark, which stresses
+// the garbage collector. It repeatedly parses the net/http package with
+// go/parser and then discards the results.
+package garbage
+
+// The source of net/http was captured at git tag go1.5.2 by
+//go:generate sh -c "(echo 'package garbage'; echo 'var src = `'; bundle net/http http '' | sed 's/`/`+\"`\"+`/g'; echo '`') > nethttp.go"
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+type ParsedPackage *ast.File
+
+// Heap holds parsed packages, half of which are replaced as the benchmark
+// runs, and half of which represent an "old" generation.
+type Heap struct {
+	parsed []ParsedPackage
+	pkgMem int
+}
+
+// NewHeap returns a Heap of enough parsed packages to use about mem bytes
+// of heap, and warms up the GC.
+func NewHeap(mem int) *Heap {
+	pkgMem := PackageMemConsumption()
+	npkg := mem / pkgMem / 2 // 2 to account for GOGC=100
+	if npkg < 2 {
+		npkg = 2
+	}
+	h := &Heap{parsed: make([]ParsedPackage, npkg), pkgMem: pkgMem}
+	for n := 0; n < 2; n++ { // warmup GC
+		for i := range h.parsed {
+			h.parsed[i] = ParsePackage()
+		}
+	}
+	return h
+}
+
+// Len returns the number of parsed packages in h.
+func (h *Heap) Len() int {
+	return len(h.parsed)
+}
+
+// PackageMem returns the memory consumption of each parsed package in h.
+func (h *Heap) PackageMem() int {
+	return h.pkgMem
+}
+
+// Run parses the package N times, replacing packages in the young half of
+// h with the results.
+func (h *Heap) Run(N uint64) {
+	P := runtime.GOMAXPROCS(0)
+	// Create G goroutines, but only 2*P of them parse at the same time.
+	G := 1024
+	gate := make(chan bool, 2*P)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(G)
+	remain := int64(N)
+	pos := 0
+	for g := 0; g < G; g++ {
+		go func() {
+			defer wg.Done()
+			for atomic.AddInt64(&remain, -1) >= 0 {
+				gate <- true
+				p := ParsePackage()
+				mu.Lock()
+				// Overwrite only half of the array,
+				// the other part represents "old" generation.
+				h.parsed[pos%(len(h.parsed)/2)] = p
+				pos++
+				mu.Unlock()
+				<-gate
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// PackageMemConsumption returns memory consumption of a single parsed package.
+func PackageMemConsumption() int {
+	// One GC does not give precise results,
+	// because concurrent sweep may be still in progress.
+	runtime.GC()
+	runtime.GC()
+	ms0 := new(runtime.MemStats)
+	runtime.ReadMemStats(ms0)
+	const N = 10
+	var parsed [N]ParsedPackage
+	for i := range parsed {
+		parsed[i] = ParsePackage()
+	}
+	runtime.GC()
+	runtime.GC()
+	// Keep it alive.
+	if parsed[0] == nil {
+		fmt.Println(&parsed)
+	}
+	ms1 := new(runtime.MemStats)
+	runtime.ReadMemStats(ms1)
+	mem := int(ms1.Alloc-ms0.Alloc) / N
+	if mem < 1<<16 {
+		mem = 1 << 16
+	}
+	return mem
+}
+
+// ParsePackage parses and returns net/http package.
+func ParsePackage() ParsedPackage {
+	pkgs, err := parser.ParseFile(token.NewFileSet(), "net/http", src, parser.ParseComments)
+	if err != nil {
+		println("parse", err.Error())
+		panic("fail")
+	}
+	return pkgs
+}
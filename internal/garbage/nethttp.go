@@ -1,4 +1,4 @@
-package main
+package garbage
 
 var src = `
 // Code generated by golang.org/x/tools/cmd/bundle command:
@@ -0,0 +1,93 @@
+// Copyright 2014 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package httpbench is the workload of the HTTP benchmark, which examines
+// client/server http performance.
+package httpbench
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Server is a local HTTP server, and a client of it.
+type Server struct {
+	server *http.Server
+	client *http.Client
+}
+
+// NewServer starts a Server whose client keeps up to conns idle
+// connections to it, and checks that it responds.
+func NewServer(conns int) (*Server, error) {
+	// These environment variables affect net/http behavior,
+	// ensure that we get predictable results regardless of environment on the machine.
+	os.Setenv("HTTP_PROXY", "")
+	os.Setenv("http_proxy", "")
+	os.Setenv("NO_PROXY", "")
+	os.Setenv("no_proxy", "")
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		if l, err = net.Listen("tcp6", "[::1]:0"); err != nil {
+			return nil, fmt.Errorf("failed to listen: %v", err)
+		}
+	}
+	s := &Server{
+		server: &http.Server{
+			Addr:           "http://" + l.Addr().String(),
+			ReadTimeout:    10 * time.Second,
+			WriteTimeout:   10 * time.Second,
+			MaxHeaderBytes: 1 << 20,
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, "Hello world.\n")
+			}),
+		},
+		client: &http.Client{
+			Transport: &http.Transport{
+				// just what default client uses
+				Proxy: http.ProxyFromEnvironment,
+				// this leads to more stable numbers
+				MaxIdleConnsPerHost: conns,
+			},
+		},
+	}
+	go s.server.Serve(l)
+
+	if !s.Request() {
+		return nil, fmt.Errorf("server is not listening")
+	}
+	return s, nil
+}
+
+// Request makes one request of the server, and reports whether it
+// succeeded.
+func (s *Server) Request() bool {
+	res, err := s.client.Get(s.server.Addr)
+	if err != nil {
+		// Under heavy load with GOMAXPROCS>>1, it frequently fails
+		// with transient failures like:
+		// "dial tcp: cannot assign requested address"
+		// or:
+		// "ConnectEx tcp: Only one usage of each socket address
+		// (protocol/network address/port) is normally permitted".
+		// So we just log and continue,
+		// otherwise significant fraction of benchmarks will fail.
+		log.Printf("Get: %v", err)
+		return false
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		log.Fatalf("ReadAll: %v", err)
+	}
+	if s := string(b); s != "Hello world.\n" {
+		log.Fatalf("Got body: " + s)
+	}
+	return true
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// CI is a confidence interval for a statistic.
+type CI struct {
+	// Center is the estimate of the statistic from the sample.
+	Center float64
+
+	// Lo and Hi are the bounds of the interval.
+	Lo, Hi float64
+
+	// Confidence is the confidence level of the interval, which may
+	// differ from that requested. Intervals based on order statistics
+	// can only achieve certain levels, and are as narrow as possible
+	// while meeting the requested level, unless the sample is too small
+	// to do so, in which case the interval covers the whole sample and
+	// has a lower confidence level.
+	Confidence float64
+}
+
+// MedianCI returns a distribution-free confidence interval for the median
+// of the distribution from which xs is drawn, at the given confidence
+// level (such as 0.95), between two order statistics of xs. xs must not
+// be empty.
+func MedianCI(xs []float64, confidence float64) CI {
+	s := append([]float64(nil), xs...)
+	sort.Float64s(s)
+	n := len(s)
+
+	// The number of points below the median is binomially distributed,
+	// so the interval is [s[k], s[n-1-k]] for the greatest k for which
+	// P(B <= k) <= (1-confidence)/2, where B ~ Binomial(n, 1/2).
+	alpha := (1 - confidence) / 2
+	k, tail := 0, binomialCDF(0, n)
+	for 2*(k+1) <= n-1 {
+		next := binomialCDF(k+1, n)
+		if next > alpha {
+			break
+		}
+		k, tail = k+1, next
+	}
+	return CI{Center: sortedMedian(s), Lo: s[k], Hi: s[n-1-k], Confidence: 1 - 2*tail}
+}
+
+// binomialCDF returns P(B <= k) where B ~ Binomial(n, 1/2).
+func binomialCDF(k, n int) float64 {
+	var p float64
+	for i := 0; i <= k; i++ {
+		lc, _ := math.Lgamma(float64(n + 1))
+		li, _ := math.Lgamma(float64(i + 1))
+		lni, _ := math.Lgamma(float64(n - i + 1))
+		p += math.Exp(lc - li - lni - float64(n)*math.Ln2)
+	}
+	return p
+}
+
+// RatioCI returns a distribution-free confidence interval for the ratio
+// of the medians of the distributions from which ys and xs are drawn, at
+// the given confidence level, such as 0.95. It assumes that the two
+// distributions differ only in scale, as benchmark results from two
+// toolchains roughly do, in which case the ratio is the exponential of the
+// shift between the logs of the samples. The interval is the exponential
+// of the order statistics of the pairwise differences of the logs that
+// correspond to the critical values of MannWhitneyU (Moses' interval for
+// the Hodges-Lehmann estimator), and its Center is the exponential of that
+// estimator. All of the values must be positive, and both samples must be
+// non-empty.
+func RatioCI(xs, ys []float64, confidence float64) CI {
+	lx, ly := logs(xs), logs(ys)
+	diffs := pairDiffs(lx, ly)
+	n1, n2 := len(xs), len(ys)
+	alpha := (1 - confidence) / 2
+
+	// The interval is [diffs[k], diffs[N-1-k]] for the greatest k for
+	// which P(U <= k) <= alpha.
+	N := n1 * n2
+	var k int
+	var tail float64
+	if n1 <= exactUMax && n2 <= exactUMax {
+		cdf := uCDF(n1, n2)
+		tail = cdf[0]
+		for 2*(k+1) <= N-1 && cdf[k+1] <= alpha {
+			k++
+			tail = cdf[k]
+		}
+	} else {
+		mean := float64(N) / 2
+		sigma := math.Sqrt(float64(N) * float64(n1+n2+1) / 12)
+		z := math.Sqrt2 * math.Erfinv(1-2*alpha)
+		k = int(math.Floor(mean - z*sigma - 0.5))
+		if k < 0 {
+			k = 0
+		} else if 2*k > N-1 {
+			k = (N - 1) / 2
+		}
+		tail = normalCDF((float64(k) + 0.5 - mean) / sigma)
+	}
+	return CI{
+		Center:     math.Exp(sortedMedian(diffs)),
+		Lo:         math.Exp(diffs[k]),
+		Hi:         math.Exp(diffs[N-1-k]),
+		Confidence: 1 - 2*tail,
+	}
+}
+
+func logs(xs []float64) []float64 {
+	l := make([]float64, len(xs))
+	for i, x := range xs {
+		l[i] = math.Log(x)
+	}
+	return l
+}
+
+func normalCDF(z float64) float64 {
+	return math.Erfc(-z/math.Sqrt2) / 2
+}
+
+// BootstrapOptions configures a bootstrap.
+type BootstrapOptions struct {
+	// Resamples is the number of times the samples are resampled. If
+	// zero, 1000 is used.
+	Resamples int
+
+	// Rand is the source of the resamples. If nil, a source with a fixed
+	// seed is used, so that results are reproducible.
+	Rand *rand.Rand
+}
+
+func (o *BootstrapOptions) setDefaults() {
+	if o.Resamples <= 0 {
+		o.Resamples = 1000
+	}
+	if o.Rand == nil {
+		o.Rand = rand.New(rand.NewSource(1))
+	}
+}
+
+// BootstrapMedianCI returns a percentile bootstrap confidence interval for
+// the median of the distribution from which xs is drawn, at the given
+// confidence level, such as 0.95. Unlike MedianCI, it can achieve any
+// confidence level, but only approximately, and it's unreliable for small
+// samples. xs must not be empty.
+func BootstrapMedianCI(xs []float64, confidence float64, opts BootstrapOptions) CI {
+	opts.setDefaults()
+	return bootstrap(Median(xs), confidence, opts, func(resample func([]float64) []float64) float64 {
+		return sortedMedian(resample(xs))
+	})
+}
+
+// BootstrapRatioCI returns a percentile bootstrap confidence interval for
+// the ratio of the median of the distribution from which ys is drawn to
+// that of xs, at the given confidence level, such as 0.95. Unlike RatioCI,
+// it makes no assumptions about the shapes of the distributions. Both
+// samples must be non-empty.
+func BootstrapRatioCI(xs, ys []float64, confidence float64, opts BootstrapOptions) CI {
+	opts.setDefaults()
+	return bootstrap(Median(ys)/Median(xs), confidence, opts, func(resample func([]float64) []float64) float64 {
+		mx := sortedMedian(resample(xs))
+		return sortedMedian(resample(ys)) / mx
+	})
+}
+
+// bootstrap returns the percentile bootstrap interval of the statistic
+// computed by stat from resamples of the samples, with the given estimate
+// from the original samples. resample returns a sorted resample of a
+// sample, which is only valid until it's next called.
+func bootstrap(center, confidence float64, opts BootstrapOptions, stat func(resample func([]float64) []float64) float64) CI {
+	var buf []float64
+	resample := func(xs []float64) []float64 {
+		buf = buf[:0]
+		for range xs {
+			buf = append(buf, xs[opts.Rand.Intn(len(xs))])
+		}
+		sort.Float64s(buf)
+		return buf
+	}
+	stats := make([]float64, opts.Resamples)
+	for i := range stats {
+		stats[i] = stat(resample)
+	}
+	sort.Float64s(stats)
+	alpha := (1 - confidence) / 2
+	lo := int(math.Floor(alpha * float64(len(stats))))
+	hi := int(math.Ceil((1-alpha)*float64(len(stats)))) - 1
+	if hi >= len(stats) {
+		hi = len(stats) - 1
+	}
+	if lo > hi {
+		lo = hi
+	}
+	return CI{Center: center, Lo: stats[lo], Hi: stats[hi], Confidence: confidence}
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestMedianCI(t *testing.T) {
+	xs := []float64{10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+	ci := MedianCI(xs, 0.95)
+	if ci.Center != 5.5 || ci.Lo != 2 || ci.Hi != 9 || math.Abs(ci.Confidence-(1-22.0/1024)) > 1e-9 {
+		t.Errorf("MedianCI() = %+v, expected [2, 9] around 5.5 at 97.85%%", ci)
+	}
+
+	// Too few points for the requested confidence.
+	ci = MedianCI([]float64{3, 1, 2}, 0.95)
+	if ci.Lo != 1 || ci.Hi != 3 || ci.Confidence != 0.75 {
+		t.Errorf("MedianCI() for 3 points = %+v, expected [1, 3] at 75%%", ci)
+	}
+}
+
+func TestRatioCI(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{10, 60} {
+		var xs, ys []float64
+		for i := 0; i < n; i++ {
+			x := 100 * math.Exp(0.1*r.NormFloat64())
+			xs = append(xs, x)
+			ys = append(ys, 2*x)
+		}
+		ci := RatioCI(xs, ys, 0.95)
+		if math.Abs(ci.Center-2) > 1e-9 || !(ci.Lo < 2 && 2 < ci.Hi) || ci.Confidence < 0.94 {
+			t.Errorf("RatioCI() with %d points = %+v, expected an interval around 2", n, ci)
+		}
+		if ci.Hi/ci.Lo > 1.5 {
+			t.Errorf("RatioCI() with %d points = %+v, expected a narrower interval", n, ci)
+		}
+	}
+}
+
+func TestBootstrapCI(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var xs, ys []float64
+	for i := 0; i < 30; i++ {
+		xs = append(xs, 100+r.NormFloat64())
+		ys = append(ys, 150+r.NormFloat64())
+	}
+	ci := BootstrapMedianCI(xs, 0.95, BootstrapOptions{})
+	if !(ci.Lo <= ci.Center && ci.Center <= ci.Hi) || ci.Lo < 99 || ci.Hi > 101 {
+		t.Errorf("BootstrapMedianCI() = %+v, expected an interval around 100", ci)
+	}
+	if again := BootstrapMedianCI(xs, 0.95, BootstrapOptions{}); again != ci {
+		t.Errorf("BootstrapMedianCI() = %+v, then %+v, expected the same", ci, again)
+	}
+	ci = BootstrapRatioCI(xs, ys, 0.95, BootstrapOptions{Resamples: 500})
+	if !(ci.Lo <= ci.Center && ci.Center <= ci.Hi) || ci.Lo < 1.48 || ci.Hi > 1.52 {
+		t.Errorf("BootstrapRatioCI() = %+v, expected an interval around 1.5", ci)
+	}
+}
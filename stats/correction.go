@@ -0,0 +1,71 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// A Correction is a method of correcting p-values for multiple
+// comparisons, such as when comparing many benchmarks between two
+// toolchains, where some p-values will be small by chance alone.
+type Correction int
+
+const (
+	// Bonferroni controls the probability of any false positive (the
+	// family-wise error rate) by multiplying each p-value by the number
+	// of comparisons.
+	Bonferroni Correction = iota
+
+	// Holm controls the family-wise error rate like Bonferroni, but is
+	// uniformly more powerful.
+	Holm
+
+	// BenjaminiHochberg controls the expected proportion of false
+	// positives among the comparisons found to be significant (the
+	// false discovery rate), which is more powerful still, and usually
+	// more appropriate for benchmarks.
+	BenjaminiHochberg
+)
+
+// AdjustPValues returns ps adjusted for multiple comparisons by the given
+// method, such that each adjusted p-value may be compared directly with
+// the desired significance level.
+func AdjustPValues(ps []float64, method Correction) []float64 {
+	n := len(ps)
+	adjusted := make([]float64, n)
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return ps[order[i]] < ps[order[j]] })
+
+	switch method {
+	case Bonferroni:
+		for i, p := range ps {
+			adjusted[i] = math.Min(1, p*float64(n))
+		}
+	case Holm:
+		// Multiply the i'th smallest by n-i, keeping them in order.
+		var max float64
+		for rank, i := range order {
+			max = math.Max(max, math.Min(1, ps[i]*float64(n-rank)))
+			adjusted[i] = max
+		}
+	case BenjaminiHochberg:
+		// Multiply the i'th smallest by n/(i+1), keeping them in order
+		// from the largest down.
+		min := 1.0
+		for rank := n - 1; rank >= 0; rank-- {
+			i := order[rank]
+			min = math.Min(min, ps[i]*float64(n)/float64(rank+1))
+			adjusted[i] = min
+		}
+	default:
+		panic("unknown correction")
+	}
+	return adjusted
+}
@@ -0,0 +1,31 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAdjustPValues(t *testing.T) {
+	ps := []float64{0.01, 0.04, 0.03, 0.005}
+	for _, test := range []struct {
+		method   Correction
+		expected []float64
+	}{
+		// These match R's p.adjust.
+		{Bonferroni, []float64{0.04, 0.16, 0.12, 0.02}},
+		{Holm, []float64{0.03, 0.06, 0.06, 0.02}},
+		{BenjaminiHochberg, []float64{0.02, 0.04, 0.04, 0.02}},
+	} {
+		got := AdjustPValues(ps, test.method)
+		for i := range got {
+			if math.Abs(got[i]-test.expected[i]) > 1e-12 {
+				t.Errorf("AdjustPValues(%v, %d) = %v, expected %v", ps, test.method, got, test.expected)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+)
+
+// DetectorOptions configures a Detector.
+type DetectorOptions struct {
+	// MinSize is the minimum number of points on either side of a
+	// changepoint. A change is reported once at least MinSize points
+	// after it have been added, and after it no more changes are
+	// reported until another MinSize points have been added. If zero, 5
+	// is used.
+	MinSize int
+
+	// Window is the maximum number of points kept in the history. Only
+	// points since the last changepoint are compared, so this also
+	// bounds the segment before a change. It must be at least
+	// 2*MinSize. If zero, 100 is used.
+	Window int
+
+	// Alpha is the significance level at which changes are reported.
+	// Since every point added is tested for a change, this should be much
+	// smaller than for a one-off test. If zero, 0.01 is used.
+	Alpha float64
+
+	// Permutations is the number of random permutations of the history
+	// used to test the significance of each change. If zero, 999 is used.
+	Permutations int
+
+	// Rand is the source of the permutations. If nil, a source with a
+	// fixed seed is used, so that results are reproducible.
+	Rand *rand.Rand
+}
+
+// Detector finds changepoints in a stream of points as they are added,
+// using a sliding version of EDM-X: each time a point is added, the last
+// MinSize points are compared with the median of the points before them,
+// back to the last changepoint or the start of the window, which is
+// maintained incrementally. Only if they're all on the same side of it,
+// which without a change happens for a fraction 2^(1-MinSize) of points,
+// is the change tested with a permutation test, as in EDivisive. So adding
+// a point takes O(Window) time, and for that fraction of points, another
+// O(Permutations * Window * log(Window)) time.
+//
+// Unlike EDMX, Detector doesn't normalize its input, since the range of a
+// stream isn't known in advance, so the values it reports are in the units
+// of the input. For the same reason, it keeps its median in heaps rather
+// than an IntervalTree, which requires normalized input.
+type Detector struct {
+	opts DetectorOptions
+
+	// hist is the history since the last changepoint, up to Window
+	// points, and start is the index in the stream of hist[0].
+	hist  []float64
+	start int
+
+	// before is the running median of all but the last MinSize points of
+	// hist.
+	before runningMedian
+
+	// pending is the index in the stream of the point at which a
+	// possible change was first seen, or -1 if there's none.
+	pending int
+
+	scratch []float64
+}
+
+// NewDetector returns a new Detector with the given options.
+func NewDetector(opts DetectorOptions) *Detector {
+	if opts.MinSize <= 0 {
+		opts.MinSize = 5
+	}
+	if opts.Window <= 0 {
+		opts.Window = 100
+	}
+	if opts.Window < 2*opts.MinSize {
+		panic("window smaller than 2*MinSize")
+	}
+	if opts.Alpha <= 0 {
+		opts.Alpha = 0.01
+	}
+	if opts.Permutations <= 0 {
+		opts.Permutations = 999
+	}
+	if opts.Rand == nil {
+		opts.Rand = rand.New(rand.NewSource(1))
+	}
+	return &Detector{opts: opts, pending: -1}
+}
+
+// Len returns the number of points added to d.
+func (d *Detector) Len() int {
+	return d.start + len(d.hist)
+}
+
+// Add adds the next point in the stream to d. If it completes a change, it
+// returns the change and true. The Index of the change is its index in the
+// stream, its Before median is that of the points in the window before the
+// change, and its Stat is the EDM-X statistic of the change in the units
+// of the input.
+func (d *Detector) Add(x float64) (Changepoint, bool) {
+	min := d.opts.MinSize
+	d.hist = append(d.hist, x)
+	if len(d.hist) > min {
+		d.before.add(d.hist[len(d.hist)-1-min])
+	}
+	if len(d.hist) > d.opts.Window {
+		d.before.remove(d.hist[0])
+		d.hist = d.hist[1:]
+		d.start++
+	}
+	if d.before.len() < min {
+		return Changepoint{}, false
+	}
+
+	if d.pending < 0 {
+		// Test for a change before the last MinSize points. It's
+		// probably not at exactly that point, so wait until there are
+		// enough points to locate it.
+		if d.mayChange() && d.significant(len(d.hist)-min) <= d.opts.Alpha {
+			d.pending = d.Len() - 1
+		}
+		return Changepoint{}, false
+	}
+	if d.Len()-1 < d.pending+min {
+		return Changepoint{}, false
+	}
+
+	// Locate the change among the points since it was first seen, with
+	// at least MinSize points either side, as the split which minimizes
+	// the total absolute deviation from the medians either side.
+	d.pending = -1
+	lo := len(d.hist) - 2*min
+	if lo < min {
+		lo = min
+	}
+	best, bestCost := -1, math.Inf(1)
+	for i := lo; i <= len(d.hist)-min; i++ {
+		before, after := d.hist[:i], d.hist[i:]
+		cost := deviation(before, Median(before)) + deviation(after, Median(after))
+		if cost < bestCost {
+			best, bestCost = i, cost
+		}
+	}
+	p := d.significant(best)
+	if p > d.opts.Alpha {
+		// A false alarm.
+		return Changepoint{}, false
+	}
+	before, after := d.hist[:best], d.hist[best:]
+	cp := Changepoint{
+		Index:  d.start + best,
+		Before: Median(before),
+		After:  Median(after),
+		PValue: p,
+	}
+	diff := cp.After - cp.Before
+	cp.Stat = float64(len(before)*len(after)) / float64(len(d.hist)) * diff * diff
+	cp.EffectSize = effectSize(before, after, cp.Before, cp.After)
+
+	// Start again from the change.
+	d.hist = append(d.hist[:0], after...)
+	d.start = cp.Index
+	d.before.reset()
+	for _, y := range d.hist[:len(d.hist)-min] {
+		d.before.add(y)
+	}
+	return cp, true
+}
+
+// mayChange reports whether the last MinSize points in the history may
+// follow a change, which is the case if they're all above or all below
+// the median of the points before them.
+func (d *Detector) mayChange() bool {
+	m := d.before.median()
+	var above, below int
+	for _, y := range d.hist[len(d.hist)-d.opts.MinSize:] {
+		if y > m {
+			above++
+		} else if y < m {
+			below++
+		}
+	}
+	return above == d.opts.MinSize || below == d.opts.MinSize
+}
+
+// significant returns the p-value of the difference between the medians
+// of the history before and after i, from a permutation test. It stops
+// early once the p-value must be greater than the significance level.
+func (d *Detector) significant(i int) float64 {
+	diff := math.Abs(Median(d.hist[:i]) - Median(d.hist[i:]))
+	limit := int(d.opts.Alpha * float64(d.opts.Permutations+1))
+	var exceeded int
+	for r := 0; r < d.opts.Permutations; r++ {
+		d.scratch = append(d.scratch[:0], d.hist...)
+		d.opts.Rand.Shuffle(len(d.scratch), func(i, j int) {
+			d.scratch[i], d.scratch[j] = d.scratch[j], d.scratch[i]
+		})
+		if math.Abs(Median(d.scratch[:i])-Median(d.scratch[i:])) >= diff {
+			exceeded++
+			if exceeded >= limit {
+				return 1
+			}
+		}
+	}
+	return float64(exceeded+1) / float64(d.opts.Permutations+1)
+}
+
+// deviation returns the total absolute deviation of xs from m.
+func deviation(xs []float64, m float64) float64 {
+	var sum float64
+	for _, x := range xs {
+		sum += math.Abs(x - m)
+	}
+	return sum
+}
+
+// runningMedian maintains the median of a set of points, to which points
+// may be added and from which they may be removed. It's split between two
+// heaps, as in EDMX.
+type runningMedian struct {
+	lo maxHeap
+	hi minHeap
+}
+
+func (m *runningMedian) len() int {
+	return m.lo.Len() + m.hi.Len()
+}
+
+func (m *runningMedian) add(x float64) {
+	addToHeaps(&m.hi, &m.lo, x)
+}
+
+// remove removes a point equal to x, which must be present.
+func (m *runningMedian) remove(x float64) {
+	if m.lo.Len() > 0 && x <= m.lo[0] {
+		for i, y := range m.lo {
+			if y == x {
+				heap.Remove(&m.lo, i)
+				break
+			}
+		}
+	} else {
+		for i, y := range m.hi {
+			if y == x {
+				heap.Remove(&m.hi, i)
+				break
+			}
+		}
+	}
+	if m.hi.Len() > m.lo.Len()+1 {
+		heap.Push(&m.lo, heap.Pop(&m.hi))
+	} else if m.lo.Len() > m.hi.Len()+1 {
+		heap.Push(&m.hi, heap.Pop(&m.lo))
+	}
+}
+
+func (m *runningMedian) median() float64 {
+	return getMedian(m.hi, m.lo)
+}
+
+func (m *runningMedian) reset() {
+	m.lo, m.hi = m.lo[:0], m.hi[:0]
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestDetector(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	d := NewDetector(DetectorOptions{})
+	var got []int
+	for i, x := range levels(r, 40, 10, 20, 12, 30) {
+		cp, ok := d.Add(x)
+		if !ok {
+			continue
+		}
+		if i < cp.Index+5 {
+			t.Errorf("change at %d reported after only %d more points", cp.Index, i-cp.Index)
+		}
+		if cp.PValue > 0.01 {
+			t.Errorf("change at %d has p-value %v, expected <= 0.01", cp.Index, cp.PValue)
+		}
+		if (cp.After > cp.Before) != (cp.EffectSize > 0) {
+			t.Errorf("change at %d from %v to %v has effect size %v", cp.Index, cp.Before, cp.After, cp.EffectSize)
+		}
+		got = append(got, cp.Index)
+	}
+	if expected := []int{40, 80, 120}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Detector found changes at %v, expected %v", got, expected)
+	}
+	if n := d.Len(); n != 160 {
+		t.Errorf("d.Len() = %d, expected 160", n)
+	}
+}
+
+func TestDetectorNoise(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	const window = 50
+	d := NewDetector(DetectorOptions{Window: window})
+	var alarms, gated int
+	for i := 0; i < 5000; i++ {
+		if _, ok := d.Add(10 + r.NormFloat64()); ok {
+			alarms++
+		}
+		if len(d.hist) > window || (len(d.hist) > d.opts.MinSize && d.before.len()+d.opts.MinSize != len(d.hist)) {
+			t.Fatalf("after %d points, history has %d points, and median %d, expected at most %d",
+				i+1, len(d.hist), d.before.len()+d.opts.MinSize, window)
+		}
+		if d.before.len() >= d.opts.MinSize && d.mayChange() {
+			gated++
+		}
+	}
+	if alarms > 10 {
+		t.Errorf("Detector found %d changes in noise, expected at most 10", alarms)
+	}
+	// Without a change, the last MinSize points are all on one side of
+	// the median before them for about 2^(1-MinSize) of points.
+	if frac := float64(gated) / 5000; frac > 0.1 {
+		t.Errorf("%.1f%% of points in noise were tested for a change, expected about 6%%", 100*frac)
+	}
+	if c := cap(d.hist); c > 4*window {
+		t.Errorf("history has capacity %d, expected memory bounded by the window", c)
+	}
+}
+
+func TestRunningMedian(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var m runningMedian
+	var points []float64
+	for i := 0; i < 1000; i++ {
+		if len(points) > 0 && r.Intn(3) == 0 {
+			j := r.Intn(len(points))
+			m.remove(points[j])
+			points = append(points[:j], points[j+1:]...)
+		} else {
+			// Use few distinct values, to test duplicates.
+			x := float64(r.Intn(20))
+			m.add(x)
+			points = append(points, x)
+		}
+		if len(points) == 0 {
+			continue
+		}
+		if got, expected := m.median(), Median(points); got != expected {
+			t.Fatalf("[%d] running median = %v, expected %v", i, got, expected)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// EDivisiveOptions configures EDivisive.
+type EDivisiveOptions struct {
+	// MinSize is the minimum number of points in a segment, that is,
+	// between changepoints or the ends of the input, like the window
+	// width ∂ of EDMX. It must be at least 2. If zero, 5 is used.
+	MinSize int
+
+	// Alpha is the significance level at which changepoints are
+	// accepted. If zero, 0.05 is used.
+	Alpha float64
+
+	// Permutations is the number of random permutations of the input
+	// used to test the significance of each changepoint. If zero, 199 is
+	// used.
+	Permutations int
+
+	// Rand is the source of the permutations. If nil, a source with a
+	// fixed seed is used, so that results are reproducible.
+	Rand *rand.Rand
+}
+
+// Changepoint is a change in the distribution of a series.
+type Changepoint struct {
+	// Index is the index of the first point after the change.
+	Index int
+
+	// Before and After are the medians of the segments before and after
+	// the change, up to the adjacent changepoints.
+	Before, After float64
+
+	// Stat is the statistic of the change (see EDivisive).
+	Stat float64
+
+	// PValue is the fraction of random permutations of the segmented
+	// input in which a change at least as strong was found.
+	PValue float64
+
+	// EffectSize is the difference between After and Before relative to
+	// the spread of the two segments, as a robust analogue of Cohen's d:
+	// the spread of each segment is its median absolute deviation scaled
+	// to estimate the standard deviation of a normal distribution, and
+	// the two are pooled. It's ±Inf if neither segment has any spread.
+	EffectSize float64
+}
+
+// EDivisive finds all of the changepoints in input by hierarchical
+// E-divisive segmentation, as described in
+// https://arxiv.org/abs/1306.4933. Unlike EDM and EDMX, which look for a
+// single change in the median, it uses the energy distance between the
+// points on either side of a change, so it finds changes in any aspect of
+// the distribution, and takes O(n²) time for each permutation.
+//
+// At each step, the most likely changepoint in any segment of the input is
+// tested for significance with a permutation test, in which the points in
+// each segment are shuffled. If it's significant, the segment is split in
+// two, and the process repeats. Otherwise, no more changepoints are found.
+// The changepoints are returned in order of their indices.
+func EDivisive(input []float64, opts EDivisiveOptions) []Changepoint {
+	if opts.MinSize <= 0 {
+		opts.MinSize = 5
+	} else if opts.MinSize < 2 {
+		opts.MinSize = 2
+	}
+	if opts.Alpha <= 0 {
+		opts.Alpha = 0.05
+	}
+	if opts.Permutations <= 0 {
+		opts.Permutations = 199
+	}
+	if opts.Rand == nil {
+		opts.Rand = rand.New(rand.NewSource(1))
+	}
+	if len(input) < 2*opts.MinSize || isConstant(input) {
+		return nil
+	}
+	z := normalize(append([]float64(nil), input...))
+
+	// bounds holds the start of each segment, and the end of the input.
+	bounds := []int{0, len(z)}
+	var found []Changepoint
+	scratch := make([]float64, len(z))
+	for {
+		loc, stat := bestSplit(z, bounds, opts.MinSize)
+		if loc < 0 {
+			break
+		}
+
+		// Test the significance of the changepoint against permutations
+		// of the points within each segment.
+		var exceeded int
+		for r := 0; r < opts.Permutations; r++ {
+			copy(scratch, z)
+			for i := 0; i+1 < len(bounds); i++ {
+				seg := scratch[bounds[i]:bounds[i+1]]
+				opts.Rand.Shuffle(len(seg), func(i, j int) { seg[i], seg[j] = seg[j], seg[i] })
+			}
+			if _, permStat := bestSplit(scratch, bounds, opts.MinSize); permStat >= stat {
+				exceeded++
+			}
+		}
+		p := float64(exceeded+1) / float64(opts.Permutations+1)
+		if p > opts.Alpha {
+			break
+		}
+
+		found = append(found, Changepoint{Index: loc, Stat: stat, PValue: p})
+		bounds = append(bounds, loc)
+		sort.Ints(bounds)
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].Index < found[j].Index })
+	for i := range found {
+		cp := &found[i]
+		lo, hi := 0, len(input)
+		if i > 0 {
+			lo = found[i-1].Index
+		}
+		if i+1 < len(found) {
+			hi = found[i+1].Index
+		}
+		before, after := input[lo:cp.Index], input[cp.Index:hi]
+		cp.Before, cp.After = Median(before), Median(after)
+		cp.EffectSize = effectSize(before, after, cp.Before, cp.After)
+	}
+	return found
+}
+
+// bestSplit returns the location and statistic of the most likely
+// changepoint within any of the segments of z delimited by bounds. The
+// location is -1 if no segment is large enough to split.
+func bestSplit(z []float64, bounds []int, minSize int) (int, float64) {
+	bestLoc, bestStat := -1, math.Inf(-1)
+	for i := 0; i+1 < len(bounds); i++ {
+		lo, hi := bounds[i], bounds[i+1]
+		if hi-lo < 2*minSize {
+			continue
+		}
+		loc, stat := splitStat(z[lo:hi], minSize)
+		if loc >= 0 && stat > bestStat {
+			bestLoc, bestStat = lo+loc, stat
+		}
+	}
+	return bestLoc, bestStat
+}
+
+// splitStat returns the location in z of the most likely changepoint, with
+// at least minSize points on either side, and its statistic, which is the
+// energy distance between the points on either side, weighted by the sizes
+// of the two sides:
+//
+//	(m n / (m + n)) (2 E|X - Y| - E|X - X'| - E|Y - Y'|)
+//
+// where X and X' are drawn from the m points before the changepoint and Y
+// and Y' from the n points after it. It takes O(len(z)²) time.
+func splitStat(z []float64, minSize int) (int, float64) {
+	n := len(z)
+	// left[i] is the sum of the distances between pairs of points in
+	// z[:i], and right[i] those in z[i:].
+	left := make([]float64, n+1)
+	right := make([]float64, n+1)
+	for i := 1; i < n; i++ {
+		var d float64
+		for j := 0; j < i; j++ {
+			d += math.Abs(z[i] - z[j])
+		}
+		left[i+1] = left[i] + d
+	}
+	for i := n - 2; i >= 0; i-- {
+		var d float64
+		for j := i + 1; j < n; j++ {
+			d += math.Abs(z[i] - z[j])
+		}
+		right[i] = right[i+1] + d
+	}
+	total := left[n]
+
+	bestLoc, bestStat := -1, math.Inf(-1)
+	for i := minSize; i <= n-minSize; i++ {
+		m, k := float64(i), float64(n-i)
+		between := total - left[i] - right[i]
+		stat := 2*between/(m*k) - left[i]/(m*(m-1)/2) - right[i]/(k*(k-1)/2)
+		stat *= m * k / (m + k)
+		if stat > bestStat {
+			bestLoc, bestStat = i, stat
+		}
+	}
+	return bestLoc, bestStat
+}
+
+// effectSize returns the difference between the medians of a and b
+// relative to their pooled robust spread (see Changepoint.EffectSize).
+func effectSize(a, b []float64, ma, mb float64) float64 {
+	// 1.4826 scales the MAD to estimate the standard deviation of a
+	// normal distribution.
+	sa, sb := 1.4826*mad(a, ma), 1.4826*mad(b, mb)
+	var pooled float64
+	if n := len(a) + len(b) - 2; n > 0 {
+		pooled = math.Sqrt((sa*sa*float64(len(a)-1) + sb*sb*float64(len(b)-1)) / float64(n))
+	}
+	diff := mb - ma
+	if pooled == 0 {
+		if diff == 0 {
+			return 0
+		}
+		return math.Inf(int(math.Copysign(1, diff)))
+	}
+	return diff / pooled
+}
+
+// mad returns the median absolute deviation of xs from m.
+func mad(xs []float64, m float64) float64 {
+	d := make([]float64, len(xs))
+	for i, x := range xs {
+		d[i] = math.Abs(x - m)
+	}
+	return Median(d)
+}
+
+func isConstant(xs []float64) bool {
+	for _, x := range xs {
+		if x != xs[0] {
+			return false
+		}
+	}
+	return true
+}
@@ -216,3 +216,49 @@ func EDMInt(input []int, delta int) int {
 	e := &edm{z: toFloat(input), delta: delta}
 	return e.calc()
 }
+
+// Segment finds all of the changepoints in input by binary segmentation
+// with detect, such as EDM or EDMX, which finds the single most likely
+// changepoint in a series given the window width ∂. Each segment of at
+// least 2∂ points that isn't constant is split at the changepoint detect
+// finds in it, if accept returns true for it, and then each side is
+// searched in turn. accept is passed the medians of the segment on either
+// side of the changepoint, and its Stat, PValue and EffectSize are zero.
+//
+// The changepoints are returned in order of their indices, with Before and
+// After set to the medians up to the adjacent changepoints.
+func Segment(input []float64, delta int, detect func([]float64, int) int, accept func(Changepoint) bool) []Changepoint {
+	var found []Changepoint
+	var split func(lo, hi int)
+	split = func(lo, hi int) {
+		seg := input[lo:hi]
+		if len(seg) < 2*delta || isConstant(seg) {
+			return
+		}
+		i := detect(seg, delta)
+		if i <= 0 || i >= len(seg) {
+			return
+		}
+		cp := Changepoint{Index: lo + i, Before: Median(seg[:i]), After: Median(seg[i:])}
+		if !accept(cp) {
+			return
+		}
+		split(lo, cp.Index)
+		found = append(found, cp)
+		split(cp.Index, hi)
+	}
+	split(0, len(input))
+
+	for i := range found {
+		lo, hi := 0, len(input)
+		if i > 0 {
+			lo = found[i-1].Index
+		}
+		if i+1 < len(found) {
+			hi = found[i+1].Index
+		}
+		found[i].Before = Median(input[lo:found[i].Index])
+		found[i].After = Median(input[found[i].Index:hi])
+	}
+	return found
+}
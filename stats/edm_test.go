@@ -5,6 +5,8 @@
 package stats
 
 import (
+	"math"
+	"math/rand"
 	"reflect"
 	"testing"
 )
@@ -44,36 +46,38 @@ func TestMedianResolution(t *testing.T) {
 	}
 }
 
+// paperInput is the test case used in the paper.
+var paperInput = []float64{
+	105.08333, 90.90000, 763.90000, 83.36667, 78.36667, 80.58333,
+	76.36667, 210.98333, 78.00000, 77.51667, 83.01667, 89.23333,
+	84.86667, 653.16667, 70.91667, 72.83333, 75.91667, 73.53333,
+	548.86667, 66.23333, 73.45000, 66.96667, 71.11667, 68.31667,
+	285.38333, 317.20000, 63.28333, 64.08333, 60.50000, 550.88333,
+	399.68333, 75.90000, 115.35000, 78.93333, 88.68333, 475.53333,
+	30.11667, 31.51667, 34.08333, 39.55000, 47.51667, 423.63333,
+	52.55000, 50.21667, 61.41667, 56.61667, 64.41667, 742.30000,
+	165.85000, 122.88333, 122.21667, 114.66667, 565.96667, 134.70000,
+	141.16667, 160.78333, 168.48333, 458.65000, 513.28333, 154.36667,
+	130.66667, 125.93333, 127.25000, 615.58333, 122.90000, 97.45000,
+	122.76667, 115.10000, 111.95000, 442.78333, 113.83333, 116.11667,
+	128.70000, 135.03333, 138.75000, 153.38333, 143.58333, 161.50000,
+	168.11667, 152.25000, 147.11667, 163.91667, 161.10000, 146.95000,
+	132.65000, 127.28333, 116.10000, 92.28333, 54.88333, 111.35000,
+	114.98333, 110.98333, 1015.35000, 774.58333, 232.65000, 134.61667,
+	130.25000, 98.66667, 102.40000, 184.86667, 258.76667, 70.33333,
+	81.38333, 81.10000, 89.21667, 536.96667, 85.83333, 95.63333,
+	76.10000, 94.38333, 73.25000, 346.70000, 65.38333, 84.73333,
+	140.56667, 120.60000, 121.38333, 359.23333, 55.28333, 54.55000,
+	52.18333, 56.20000, 112.11667, 208.53333, 49.40000, 49.06667,
+	56.06667, 54.01667, 63.51667, 344.41667, 42.06667, 55.36667,
+	55.96667, 55.85000, 56.30000, 46.56667, 49.25000, 43.90000,
+	357.61667, 44.10000, 44.68333, 43.13333, 40.55000, 452.20000,
+	47.06667, 40.00000, 42.35000, 48.36667, 44.86667, 48.51667,
+	244.01667, 50.16667, 48.73333, 47.91667, 51.96667, 343.33333,
+	35.25000, 45.33333, 46.86667, 48.78333}
+
 func TestEDMXGolden(t *testing.T) {
-	// This test case is used in the paper.
-	input := []float64{
-		105.08333, 90.90000, 763.90000, 83.36667, 78.36667, 80.58333,
-		76.36667, 210.98333, 78.00000, 77.51667, 83.01667, 89.23333,
-		84.86667, 653.16667, 70.91667, 72.83333, 75.91667, 73.53333,
-		548.86667, 66.23333, 73.45000, 66.96667, 71.11667, 68.31667,
-		285.38333, 317.20000, 63.28333, 64.08333, 60.50000, 550.88333,
-		399.68333, 75.90000, 115.35000, 78.93333, 88.68333, 475.53333,
-		30.11667, 31.51667, 34.08333, 39.55000, 47.51667, 423.63333,
-		52.55000, 50.21667, 61.41667, 56.61667, 64.41667, 742.30000,
-		165.85000, 122.88333, 122.21667, 114.66667, 565.96667, 134.70000,
-		141.16667, 160.78333, 168.48333, 458.65000, 513.28333, 154.36667,
-		130.66667, 125.93333, 127.25000, 615.58333, 122.90000, 97.45000,
-		122.76667, 115.10000, 111.95000, 442.78333, 113.83333, 116.11667,
-		128.70000, 135.03333, 138.75000, 153.38333, 143.58333, 161.50000,
-		168.11667, 152.25000, 147.11667, 163.91667, 161.10000, 146.95000,
-		132.65000, 127.28333, 116.10000, 92.28333, 54.88333, 111.35000,
-		114.98333, 110.98333, 1015.35000, 774.58333, 232.65000, 134.61667,
-		130.25000, 98.66667, 102.40000, 184.86667, 258.76667, 70.33333,
-		81.38333, 81.10000, 89.21667, 536.96667, 85.83333, 95.63333,
-		76.10000, 94.38333, 73.25000, 346.70000, 65.38333, 84.73333,
-		140.56667, 120.60000, 121.38333, 359.23333, 55.28333, 54.55000,
-		52.18333, 56.20000, 112.11667, 208.53333, 49.40000, 49.06667,
-		56.06667, 54.01667, 63.51667, 344.41667, 42.06667, 55.36667,
-		55.96667, 55.85000, 56.30000, 46.56667, 49.25000, 43.90000,
-		357.61667, 44.10000, 44.68333, 43.13333, 40.55000, 452.20000,
-		47.06667, 40.00000, 42.35000, 48.36667, 44.86667, 48.51667,
-		244.01667, 50.16667, 48.73333, 47.91667, 51.96667, 343.33333,
-		35.25000, 45.33333, 46.86667, 48.78333}
+	input := paperInput
 	if v := EDMX(input, 24); v != 95 {
 		t.Errorf("EDMX() = %v, expected 95", v)
 	}
@@ -81,3 +85,136 @@ func TestEDMXGolden(t *testing.T) {
 		t.Errorf("EDM() = %v, expected 47", v)
 	}
 }
+
+// levels returns n points at each of the given levels in turn, with
+// normally distributed noise.
+func levels(r *rand.Rand, n int, ls ...float64) []float64 {
+	var out []float64
+	for _, l := range ls {
+		for i := 0; i < n; i++ {
+			out = append(out, l+r.NormFloat64())
+		}
+	}
+	return out
+}
+
+// shiftTest is a series with changepoints at known indexes.
+type shiftTest struct {
+	name     string
+	input    []float64
+	minSize  int
+	expected []int
+}
+
+// shiftTests returns the series with changepoints shared by the tests of
+// EDivisive and EDMX.
+func shiftTests() []shiftTest {
+	r := rand.New(rand.NewSource(42))
+	return []shiftTest{
+		{"paper", paperInput, 24, []int{47, 118}},
+		{"one shift", levels(r, 30, 10, 15), 0, []int{30}},
+		{"several shifts", levels(r, 25, 10, 20, 12, 30), 0, []int{25, 50, 75}},
+		{"there and back", levels(r, 20, 10, 14, 10), 0, []int{20, 40}},
+		{"noise", levels(r, 100, 10), 0, nil},
+		{"constant", []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}, 0, nil},
+		{"too short", []float64{1, 2, 3, 4, 100, 101, 102, 103}, 0, nil},
+	}
+}
+
+func TestEDMXShifts(t *testing.T) {
+	// EDMX and EDM find a single changepoint, which should be within
+	// delta of a shift. With several shifts, EDMX's approximate statistic
+	// may prefer a point between two of them, so it need only fall within
+	// the shifts.
+	for _, test := range shiftTests() {
+		if test.name == "paper" || len(test.expected) == 0 {
+			continue
+		}
+		delta := 5
+		near := func(v int) bool {
+			for _, e := range test.expected {
+				if v >= e-delta && v <= e+delta {
+					return true
+				}
+			}
+			return false
+		}
+		first, last := test.expected[0], test.expected[len(test.expected)-1]
+		if v := EDMX(test.input, delta); len(test.expected) > 2 && (v < first || v > last) {
+			t.Errorf("%s: EDMX() = %v, expected between %v and %v", test.name, v, first, last)
+		} else if len(test.expected) <= 2 && !near(v) {
+			t.Errorf("%s: EDMX() = %v, expected within %v of one of %v", test.name, v, delta, test.expected)
+		}
+		if v := EDM(test.input, delta); !near(v) {
+			t.Errorf("%s: EDM() = %v, expected within %v of one of %v", test.name, v, delta, test.expected)
+		}
+	}
+}
+
+func TestSegment(t *testing.T) {
+	// Accept any change of at least 15% in the median.
+	accept := func(cp Changepoint) bool {
+		return math.Abs(cp.After-cp.Before) >= 0.15*math.Abs(cp.Before)
+	}
+	for _, test := range shiftTests() {
+		if test.name == "paper" {
+			continue
+		}
+		cps := Segment(test.input, 5, EDM, accept)
+		if len(cps) != len(test.expected) {
+			t.Errorf("%s: Segment() found %d changepoints %+v, expected %v", test.name, len(cps), cps, test.expected)
+			continue
+		}
+		for i, cp := range cps {
+			if e := test.expected[i]; cp.Index < e-2 || cp.Index > e+2 {
+				t.Errorf("%s: changepoint %d at %d, expected %d±2", test.name, i, cp.Index, e)
+			}
+			lo, hi := 0, len(test.input)
+			if i > 0 {
+				lo = cps[i-1].Index
+			}
+			if i+1 < len(cps) {
+				hi = cps[i+1].Index
+			}
+			if before, after := Median(test.input[lo:cp.Index]), Median(test.input[cp.Index:hi]); cp.Before != before || cp.After != after {
+				t.Errorf("%s: changepoint %d has medians %v, %v, expected %v, %v", test.name, i, cp.Before, cp.After, before, after)
+			}
+		}
+	}
+}
+
+func TestEDivisive(t *testing.T) {
+	for _, test := range shiftTests() {
+		cps := EDivisive(test.input, EDivisiveOptions{MinSize: test.minSize})
+		var got []int
+		for _, cp := range cps {
+			got = append(got, cp.Index)
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: EDivisive() found changepoints at %v, expected %v", test.name, got, test.expected)
+			continue
+		}
+		for _, cp := range cps {
+			if cp.PValue > 0.05 {
+				t.Errorf("%s: changepoint at %d has p-value %v, expected <= 0.05", test.name, cp.Index, cp.PValue)
+			}
+			if (cp.After > cp.Before) != (cp.EffectSize > 0) {
+				t.Errorf("%s: changepoint at %d from %v to %v has effect size %v", test.name, cp.Index, cp.Before, cp.After, cp.EffectSize)
+			}
+		}
+	}
+}
+
+func TestEDivisiveOptions(t *testing.T) {
+	input := levels(rand.New(rand.NewSource(1)), 10, 10, 11, 10)
+	// With the minimum number of permutations, no p-value can be small
+	// enough.
+	if cps := EDivisive(input, EDivisiveOptions{Permutations: 9}); cps != nil {
+		t.Errorf("EDivisive() with 9 permutations = %+v, expected none", cps)
+	}
+	// Segments of at least 15 points can't fit either side of the
+	// shifts.
+	if cps := EDivisive(input, EDivisiveOptions{MinSize: 15}); len(cps) > 1 {
+		t.Errorf("EDivisive() with MinSize 15 = %+v, expected at most one changepoint", cps)
+	}
+}
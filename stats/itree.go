@@ -10,25 +10,54 @@ import (
 
 // IntervalTree is a structure used to make a calculation of running medians quick.
 // The structure is described in the Section 8/Appendix of the paper.
+//
+// The tree divides its domain into 1<<d equal intervals, and counts the
+// elements in each, so quantiles are approximate, to within the width of
+// an interval.
 type IntervalTree struct {
 	d    int
 	vals []int
+
+	// lo and hi are the bounds of the domain. If abs is set, elements
+	// are replaced by their absolute values, as EDM requires.
+	lo, hi float64
+	abs    bool
 }
 
-// NewIntervalTree creates a new IntervalTree of depth d.
+// NewIntervalTree creates a new IntervalTree of depth d. Its domain is
+// [0, 1], and it stores the absolute value of each element, which suits
+// the distances between normalized points that EDM stores in it.
 func NewIntervalTree(d int) *IntervalTree {
+	it := NewIntervalTreeDomain(d, 0, 1)
+	it.abs = true
+	return it
+}
+
+// NewIntervalTreeDomain creates a new IntervalTree of depth d with domain
+// [lo, hi]. Elements outside the domain are counted as if they were at its
+// nearest end.
+func NewIntervalTreeDomain(d int, lo, hi float64) *IntervalTree {
 	if d < 0 {
 		panic("invalid depth")
 	}
+	if !(lo < hi) {
+		panic("invalid domain")
+	}
 	return &IntervalTree{
 		d:    d,
 		vals: make([]int, (1<<(d+1))-1),
+		lo:   lo,
+		hi:   hi,
 	}
 }
 
 // walk is a generic function on interval trees to add or remove elements.
 func (it *IntervalTree) walk(v float64, update int) {
-	v = math.Abs(v)
+	if it.abs {
+		v = math.Abs(v)
+	}
+	// Map the domain onto [0, 1].
+	v = (v - it.lo) / (it.hi - it.lo)
 	mid, inc := 0.5, 0.25
 	idx := 0
 	// Update the levels in the tree.
@@ -55,8 +84,14 @@ func (it *IntervalTree) Remove(v float64) {
 	it.walk(v, -1)
 }
 
-// Median returns the current median.
+// Median returns the current median, as described in the paper. It's
+// coarser than Quantile(0.5), but is what EDM expects.
 func (it *IntervalTree) Median() float64 {
+	return it.lo + it.median()*(it.hi-it.lo)
+}
+
+// median returns the median in [0, 1].
+func (it *IntervalTree) median() float64 {
 	// If empty, special case and return 0.
 	numElements := it.NumElements()
 	if numElements == 0 {
@@ -89,7 +124,87 @@ func (it *IntervalTree) Median() float64 {
 	return (u-l)/2. + l
 }
 
+// Quantile returns the current q-quantile, for q in [0, 1], as the middle
+// of the interval containing it. For example, Quantile(0.99) is the 99th
+// percentile. If the tree is empty, it returns the lower bound of its
+// domain.
+func (it *IntervalTree) Quantile(q float64) float64 {
+	numElements := it.NumElements()
+	if numElements == 0 {
+		return it.lo
+	}
+
+	l, u := 0., 1.
+	// k is the rank of the quantile among the elements, from 1.
+	k := int(math.Ceil(q * float64(numElements)))
+	if k < 1 {
+		k = 1
+	} else if k > numElements {
+		k = numElements
+	}
+	for i := 0; 2*i+1 < len(it.vals); {
+		j := 2*i + 1
+		if v := it.vals[j]; v >= k {
+			i = j
+			u = (l + u) / 2.
+		} else {
+			k -= v
+			i = j + 1
+			l = (l + u) / 2.
+		}
+	}
+	return it.lo + ((u-l)/2.+l)*(it.hi-it.lo)
+}
+
 // NumElements returns the number of elements in the tree.
 func (it *IntervalTree) NumElements() int {
 	return it.vals[0]
 }
+
+// SlidingWindow maintains the quantiles of the last n elements added to
+// it, in an IntervalTree.
+type SlidingWindow struct {
+	tree *IntervalTree
+	buf  []float64 // A ring of the elements in the window.
+	next int       // The index in buf of the next element.
+	full bool
+}
+
+// NewSlidingWindow creates a new SlidingWindow of the last n elements,
+// using an IntervalTree of depth d with domain [lo, hi] (see
+// NewIntervalTreeDomain).
+func NewSlidingWindow(n, d int, lo, hi float64) *SlidingWindow {
+	if n <= 0 {
+		panic("invalid window size")
+	}
+	return &SlidingWindow{
+		tree: NewIntervalTreeDomain(d, lo, hi),
+		buf:  make([]float64, n),
+	}
+}
+
+// Add adds an element to the window, evicting the oldest if the window is
+// full.
+func (w *SlidingWindow) Add(v float64) {
+	if w.full {
+		w.tree.Remove(w.buf[w.next])
+	}
+	w.tree.Insert(v)
+	w.buf[w.next] = v
+	w.next++
+	if w.next == len(w.buf) {
+		w.next = 0
+		w.full = true
+	}
+}
+
+// Quantile returns the q-quantile of the elements in the window (see
+// IntervalTree.Quantile).
+func (w *SlidingWindow) Quantile(q float64) float64 {
+	return w.tree.Quantile(q)
+}
+
+// NumElements returns the number of elements in the window.
+func (w *SlidingWindow) NumElements() int {
+	return w.tree.NumElements()
+}
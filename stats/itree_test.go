@@ -5,6 +5,7 @@
 package stats
 
 import (
+	"math"
 	"reflect"
 	"testing"
 )
@@ -43,3 +44,63 @@ func TestIntervalTree(t *testing.T) {
 		}
 	}
 }
+
+func TestIntervalTreeQuantile(t *testing.T) {
+	t.Parallel()
+	tree := NewIntervalTreeDomain(10, 0, 1000)
+	if v := tree.Quantile(0.5); v != 0 {
+		t.Errorf("tree.Quantile(0.5) = %f, expected 0", v)
+	}
+	for i := 1; i <= 1000; i++ {
+		tree.Insert(float64(i))
+	}
+	// The intervals are less than 1 wide.
+	for _, test := range []struct {
+		q, expected float64
+	}{
+		{0, 1},
+		{0.01, 10},
+		{0.5, 500},
+		{0.99, 990},
+		{1, 1000},
+	} {
+		if v := tree.Quantile(test.q); math.Abs(v-test.expected) > 1 {
+			t.Errorf("tree.Quantile(%v) = %f, expected %f", test.q, v, test.expected)
+		}
+	}
+	// Values outside the domain are counted at its ends.
+	tree.Insert(-50)
+	tree.Insert(5000)
+	if v := tree.Quantile(0); v > 1 {
+		t.Errorf("tree.Quantile(0) = %f, expected 0", v)
+	}
+	if v := tree.Quantile(1); v < 999 {
+		t.Errorf("tree.Quantile(1) = %f, expected 1000", v)
+	}
+
+	// Unlike NewIntervalTree, negative values are kept.
+	tree = NewIntervalTreeDomain(8, -1, 1)
+	tree.Insert(-0.5)
+	if v := tree.Quantile(0.5); math.Abs(v+0.5) > 0.01 {
+		t.Errorf("tree.Quantile(0.5) = %f, expected -0.5", v)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	t.Parallel()
+	w := NewSlidingWindow(100, 12, 0, 1)
+	for i := 0; i < 1000; i++ {
+		// The values drift up, so the window's quantiles do too.
+		w.Add(float64(i%100)/1000 + float64(i)/2000)
+		if n, expected := w.NumElements(), i+1; expected <= 100 && n != expected || expected > 100 && n != 100 {
+			t.Fatalf("after %d elements, w.NumElements() = %d", i+1, n)
+		}
+	}
+	// The window holds elements 900 to 999.
+	if v := w.Quantile(0); math.Abs(v-0.45) > 0.001 {
+		t.Errorf("w.Quantile(0) = %f, expected 0.45", v)
+	}
+	if v := w.Quantile(0.99); math.Abs(v-(0.099+0.999/2)) > 0.002 {
+		t.Errorf("w.Quantile(0.99) = %f, expected %f", v, 0.099+0.999/2)
+	}
+}
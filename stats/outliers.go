@@ -0,0 +1,239 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// MADOutliers returns the indices of the points in xs that are outliers
+// by the modified z-score of Iglewicz and Hoaglin: those further than k
+// times the median absolute deviation (scaled to estimate the standard
+// deviation of a normal distribution) from the median. If k is zero, 3.5
+// is used. Unlike the mean and standard deviation, the median and MAD
+// aren't themselves affected by the outliers, so a single wild point
+// can't hide itself.
+//
+// If more than half of the points are equal, so that the MAD is zero, the
+// mean absolute deviation is used instead.
+func MADOutliers(xs []float64, k float64) []int {
+	if k == 0 {
+		k = 3.5
+	}
+	if len(xs) == 0 {
+		return nil
+	}
+	m := Median(xs)
+	spread := 1.4826 * mad(xs, m)
+	if spread == 0 {
+		// 1.2533 scales the mean absolute deviation to estimate the
+		// standard deviation of a normal distribution.
+		spread = 1.2533 * deviation(xs, m) / float64(len(xs))
+	}
+	if spread == 0 {
+		return nil
+	}
+	var out []int
+	for i, x := range xs {
+		if math.Abs(x-m) > k*spread {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// TukeyFences returns Tukey's fences for xs: the points k times the
+// interquartile range below the first quartile and above the third. If k
+// is zero, 1.5 is used, and points beyond the fences are conventionally
+// outliers; with a k of 3, they are far out. xs must not be empty.
+func TukeyFences(xs []float64, k float64) (lo, hi float64) {
+	if k == 0 {
+		k = 1.5
+	}
+	s := append([]float64(nil), xs...)
+	sort.Float64s(s)
+	q1, q3 := sortedQuantile(s, 0.25), sortedQuantile(s, 0.75)
+	return q1 - k*(q3-q1), q3 + k*(q3-q1)
+}
+
+// TukeyOutliers returns the indices of the points in xs that lie beyond
+// TukeyFences(xs, k).
+func TukeyOutliers(xs []float64, k float64) []int {
+	if len(xs) == 0 {
+		return nil
+	}
+	lo, hi := TukeyFences(xs, k)
+	var out []int
+	for i, x := range xs {
+		if x < lo || x > hi {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// BimodalFit is the result of fitting a mixture of two normal
+// distributions to a sample (see FitBimodal).
+type BimodalFit struct {
+	// Bimodal is whether the sample is likely bimodal.
+	Bimodal bool
+
+	// Means, StdDevs and Weights describe the two components of the
+	// mixture, in order of mean. The weights sum to 1.
+	Means, StdDevs, Weights [2]float64
+
+	// DeltaBIC is the Bayesian information criterion of a single normal
+	// distribution less that of the mixture. The greater it is, the
+	// better the mixture explains the sample, despite its extra
+	// parameters.
+	DeltaBIC float64
+
+	// Separation is Ashman's D for the two components: the distance
+	// between their means relative to their spread. The components are
+	// clearly separated if it's greater than 2.
+	Separation float64
+}
+
+// FitBimodal fits a mixture of two normal distributions to xs by
+// expectation maximization, to test whether xs is bimodal, as benchmark
+// results are, for example, when a run sometimes takes a slow path. xs is
+// considered bimodal if it has at least 10 points, the mixture is
+// strongly preferred to a single normal distribution (DeltaBIC > 10), its
+// components are clearly separated (Separation > 2), and each component
+// has at least 10% of the weight, so that a few outliers (see MADOutliers)
+// don't count as a mode.
+func FitBimodal(xs []float64) BimodalFit {
+	n := len(xs)
+	var fit BimodalFit
+	if n < 4 {
+		return fit
+	}
+	s := append([]float64(nil), xs...)
+	sort.Float64s(s)
+	if s[0] == s[n-1] {
+		fit.Means = [2]float64{s[0], s[0]}
+		fit.Weights = [2]float64{1, 0}
+		return fit
+	}
+
+	// Start from the split of the sorted points with the least total
+	// squared deviation from the means of each side.
+	var sum, sumSq float64
+	for _, x := range s {
+		sum += x
+		sumSq += x * x
+	}
+	best, bestSSE := 2, math.Inf(1)
+	var lsum, lsumSq float64
+	for i := 1; i < n-1; i++ {
+		lsum += s[i-1]
+		lsumSq += s[i-1] * s[i-1]
+		if i < 2 || n-i < 2 {
+			continue
+		}
+		rsum, rsumSq := sum-lsum, sumSq-lsumSq
+		sse := lsumSq - lsum*lsum/float64(i) + rsumSq - rsum*rsum/float64(n-i)
+		if sse < bestSSE {
+			best, bestSSE = i, sse
+		}
+	}
+	mean1, sd1 := meanStdDev(s)
+	// Don't let a component collapse onto a single point.
+	floor := 1e-6 * sd1
+	for c, part := range [2][]float64{s[:best], s[best:]} {
+		fit.Means[c], fit.StdDevs[c] = meanStdDev(part)
+		fit.StdDevs[c] = math.Max(fit.StdDevs[c], floor)
+		fit.Weights[c] = float64(len(part)) / float64(n)
+	}
+
+	resp := make([]float64, n) // The responsibility of the first component.
+	ll := math.Inf(-1)
+	for iter := 0; iter < 500; iter++ {
+		// Expectation.
+		var next float64
+		for i, x := range s {
+			p0 := fit.Weights[0] * normalPDF(x, fit.Means[0], fit.StdDevs[0])
+			p1 := fit.Weights[1] * normalPDF(x, fit.Means[1], fit.StdDevs[1])
+			if p0+p1 == 0 {
+				// Far from both; assign it to the nearer.
+				resp[i] = 0
+				if math.Abs(x-fit.Means[0]) < math.Abs(x-fit.Means[1]) {
+					resp[i] = 1
+				}
+				next += -745 // About log of the smallest float64.
+				continue
+			}
+			resp[i] = p0 / (p0 + p1)
+			next += math.Log(p0 + p1)
+		}
+		// Maximization.
+		for c := 0; c < 2; c++ {
+			var w, m, v float64
+			for i, x := range s {
+				r := resp[i]
+				if c == 1 {
+					r = 1 - r
+				}
+				w += r
+				m += r * x
+			}
+			if w == 0 {
+				return fit
+			}
+			m /= w
+			for i, x := range s {
+				r := resp[i]
+				if c == 1 {
+					r = 1 - r
+				}
+				v += r * (x - m) * (x - m)
+			}
+			fit.Weights[c] = w / float64(n)
+			fit.Means[c] = m
+			fit.StdDevs[c] = math.Max(math.Sqrt(v/w), floor)
+		}
+		if next-ll < 1e-9*math.Abs(next) {
+			ll = next
+			break
+		}
+		ll = next
+	}
+	if fit.Means[0] > fit.Means[1] {
+		fit.Means[0], fit.Means[1] = fit.Means[1], fit.Means[0]
+		fit.StdDevs[0], fit.StdDevs[1] = fit.StdDevs[1], fit.StdDevs[0]
+		fit.Weights[0], fit.Weights[1] = fit.Weights[1], fit.Weights[0]
+	}
+
+	var ll1 float64
+	for _, x := range s {
+		ll1 += math.Log(normalPDF(x, mean1, sd1))
+	}
+	logN := math.Log(float64(n))
+	fit.DeltaBIC = (2*logN - 2*ll1) - (5*logN - 2*ll)
+	fit.Separation = math.Sqrt2 * (fit.Means[1] - fit.Means[0]) /
+		math.Sqrt(fit.StdDevs[0]*fit.StdDevs[0]+fit.StdDevs[1]*fit.StdDevs[1])
+	fit.Bimodal = n >= 10 && fit.DeltaBIC > 10 && fit.Separation > 2 &&
+		math.Min(fit.Weights[0], fit.Weights[1]) >= 0.1
+	return fit
+}
+
+// meanStdDev returns the mean and (maximum likelihood) standard deviation
+// of xs.
+func meanStdDev(xs []float64) (mean, sd float64) {
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	for _, x := range xs {
+		sd += (x - mean) * (x - mean)
+	}
+	return mean, math.Sqrt(sd / float64(len(xs)))
+}
+
+func normalPDF(x, mean, sd float64) float64 {
+	z := (x - mean) / sd
+	return math.Exp(-z*z/2) / (sd * math.Sqrt(2*math.Pi))
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestMADOutliers(t *testing.T) {
+	for _, test := range []struct {
+		xs       []float64
+		expected []int
+	}{
+		{[]float64{10, 10.1, 9.9, 10.2, 9.8, 10, 30}, []int{6}},
+		{[]float64{10, 10.1, 9.9, 10.2, 9.8, 10, 10.3}, nil},
+		{[]float64{1, 100, 10, 10.1, 9.9, 10.2, 9.8}, []int{0, 1}},
+		// The MAD is zero, so the mean absolute deviation is used.
+		{[]float64{5, 5, 5, 5, 5, 5, 5, 5, 5, 6}, []int{9}},
+		{[]float64{5, 5, 5}, nil},
+		{nil, nil},
+	} {
+		if got := MADOutliers(test.xs, 0); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("MADOutliers(%v) = %v, expected %v", test.xs, got, test.expected)
+		}
+	}
+}
+
+func TestTukeyOutliers(t *testing.T) {
+	xs := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 30}
+	lo, hi := TukeyFences(xs, 0)
+	// The quartiles are 3.25 and 7.75.
+	if lo != 3.25-1.5*4.5 || hi != 7.75+1.5*4.5 {
+		t.Errorf("TukeyFences() = %v, %v, expected %v, %v", lo, hi, 3.25-1.5*4.5, 7.75+1.5*4.5)
+	}
+	if got := TukeyOutliers(xs, 0); !reflect.DeepEqual(got, []int{9}) {
+		t.Errorf("TukeyOutliers() = %v, expected [9]", got)
+	}
+	if got := TukeyOutliers(xs, 5); got != nil {
+		t.Errorf("TukeyOutliers() with k = 5 = %v, expected none", got)
+	}
+}
+
+func TestFitBimodal(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var unimodal, bimodal, outlier []float64
+	for i := 0; i < 40; i++ {
+		unimodal = append(unimodal, 100+r.NormFloat64())
+		if i%3 == 0 {
+			bimodal = append(bimodal, 120+r.NormFloat64())
+		} else {
+			bimodal = append(bimodal, 100+r.NormFloat64())
+		}
+		outlier = append(outlier, 100+r.NormFloat64())
+	}
+	outlier[7] = 150
+
+	fit := FitBimodal(bimodal)
+	if !fit.Bimodal {
+		t.Errorf("FitBimodal() of a bimodal sample = %+v, expected bimodal", fit)
+	}
+	if math.Abs(fit.Means[0]-100) > 1 || math.Abs(fit.Means[1]-120) > 1 || math.Abs(fit.Weights[1]-14.0/40) > 0.01 {
+		t.Errorf("FitBimodal() = %+v, expected modes at 100 and 120 with weights 26/40 and 14/40", fit)
+	}
+	for name, xs := range map[string][]float64{
+		"unimodal":    unimodal,
+		"one outlier": outlier,
+		"constant":    {1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+		"short":       {1, 2, 10, 11},
+	} {
+		if fit := FitBimodal(xs); fit.Bimodal {
+			t.Errorf("FitBimodal() of %s sample = %+v, expected not bimodal", name, fit)
+		}
+	}
+}
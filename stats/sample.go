@@ -0,0 +1,63 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// Median returns the median of xs, which must not be empty.
+func Median(xs []float64) float64 {
+	s := append([]float64(nil), xs...)
+	sort.Float64s(s)
+	return sortedMedian(s)
+}
+
+func sortedMedian(s []float64) float64 {
+	if len(s)%2 == 1 {
+		return s[len(s)/2]
+	}
+	return (s[len(s)/2-1] + s[len(s)/2]) / 2
+}
+
+// Geomean returns the geometric mean of xs, which is the appropriate way
+// to summarize ratios, such as the ratios of medians of each benchmark
+// between two toolchains: a benchmark that gets twice as fast cancels out
+// one that gets twice as slow. It returns NaN if xs is empty or contains
+// values that aren't positive.
+func Geomean(xs []float64) float64 {
+	if len(xs) == 0 {
+		return math.NaN()
+	}
+	var sum float64
+	for _, x := range xs {
+		if !(x > 0) {
+			return math.NaN()
+		}
+		sum += math.Log(x)
+	}
+	return math.Exp(sum / float64(len(xs)))
+}
+
+// Quantile returns the q-quantile of xs, for q in [0, 1], interpolating
+// linearly between the points either side of it. xs must not be empty.
+func Quantile(xs []float64, q float64) float64 {
+	s := append([]float64(nil), xs...)
+	sort.Float64s(s)
+	return sortedQuantile(s, q)
+}
+
+func sortedQuantile(s []float64, q float64) float64 {
+	pos := q * float64(len(s)-1)
+	if pos <= 0 {
+		return s[0]
+	}
+	if pos >= float64(len(s)-1) {
+		return s[len(s)-1]
+	}
+	i := int(pos)
+	return s[i] + (s[i+1]-s[i])*(pos-float64(i))
+}
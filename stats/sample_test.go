@@ -0,0 +1,56 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMedian(t *testing.T) {
+	for _, test := range []struct {
+		xs       []float64
+		expected float64
+	}{
+		{[]float64{1}, 1},
+		{[]float64{3, 1, 2}, 2},
+		{[]float64{4, 1, 3, 2}, 2.5},
+	} {
+		if v := Median(test.xs); v != test.expected {
+			t.Errorf("Median(%v) = %v, expected %v", test.xs, v, test.expected)
+		}
+	}
+}
+
+func TestGeomean(t *testing.T) {
+	if v := Geomean([]float64{2, 0.5}); math.Abs(v-1) > 1e-12 {
+		t.Errorf("Geomean(2, 0.5) = %v, expected 1", v)
+	}
+	if v := Geomean([]float64{1, 2, 4}); math.Abs(v-2) > 1e-12 {
+		t.Errorf("Geomean(1, 2, 4) = %v, expected 2", v)
+	}
+	for _, xs := range [][]float64{nil, {1, 0}, {1, -1}} {
+		if v := Geomean(xs); !math.IsNaN(v) {
+			t.Errorf("Geomean(%v) = %v, expected NaN", xs, v)
+		}
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	xs := []float64{4, 1, 3, 2, 5}
+	for _, test := range []struct {
+		q, expected float64
+	}{
+		{0, 1},
+		{0.25, 2},
+		{0.5, 3},
+		{0.9, 4.6},
+		{1, 5},
+	} {
+		if v := Quantile(xs, test.q); math.Abs(v-test.expected) > 1e-12 {
+			t.Errorf("Quantile(%v, %v) = %v, expected %v", xs, test.q, v, test.expected)
+		}
+	}
+}
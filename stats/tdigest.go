@@ -0,0 +1,295 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// TDigest is a compact sketch of a distribution, from which its quantiles
+// can be estimated, as described in https://arxiv.org/abs/1902.04023. It's
+// most accurate at the extreme quantiles, such as the 99th percentile of a
+// latency distribution.
+//
+// TDigests may be merged, so that, for example, the distributions of
+// latencies from several runs of a benchmark, or several processes, can be
+// combined and their quantiles estimated, which can't be done from the
+// quantiles of each. They have a stable binary encoding and JSON encoding,
+// so they may be saved and merged later.
+type TDigest struct {
+	compression float64
+	centroids   []centroid // Merged, in order of mean.
+	buf         []centroid // Not yet merged.
+	count       float64    // The total weight, including buf.
+	min, max    float64
+}
+
+// centroid summarizes a group of points by their mean.
+type centroid struct {
+	mean, weight float64
+}
+
+// DefaultCompression is the compression of a TDigest created with
+// NewTDigest(0).
+const DefaultCompression = 100
+
+// NewTDigest creates a new, empty TDigest with the given compression,
+// which bounds the number of centroids it keeps to at most about that,
+// and so trades size for accuracy. If compression is zero,
+// DefaultCompression is used.
+func NewTDigest(compression float64) *TDigest {
+	if compression == 0 {
+		compression = DefaultCompression
+	}
+	if !(compression >= 1) {
+		panic("invalid compression")
+	}
+	return &TDigest{compression: compression, min: math.Inf(1), max: math.Inf(-1)}
+}
+
+// Count returns the number of points added to t, including those merged
+// from other TDigests.
+func (t *TDigest) Count() float64 {
+	return t.count
+}
+
+// Min and Max return the least and greatest points added to t. They return
+// +Inf and -Inf if t is empty.
+func (t *TDigest) Min() float64 { return t.min }
+func (t *TDigest) Max() float64 { return t.max }
+
+// Add adds a point to t.
+func (t *TDigest) Add(x float64) {
+	t.AddWeighted(x, 1)
+}
+
+// AddWeighted adds a point with the given positive weight to t, as if it
+// had been added that many times.
+func (t *TDigest) AddWeighted(x, weight float64) {
+	if math.IsNaN(x) || !(weight > 0) {
+		return
+	}
+	t.buf = append(t.buf, centroid{x, weight})
+	t.count += weight
+	t.min = math.Min(t.min, x)
+	t.max = math.Max(t.max, x)
+	if len(t.buf) >= 4*int(t.compression) {
+		t.compress()
+	}
+}
+
+// Merge adds all of the points in o to t.
+func (t *TDigest) Merge(o *TDigest) {
+	if o.count == 0 {
+		return
+	}
+	t.buf = append(t.buf, o.centroids...)
+	t.buf = append(t.buf, o.buf...)
+	t.count += o.count
+	t.min = math.Min(t.min, o.min)
+	t.max = math.Max(t.max, o.max)
+	t.compress()
+}
+
+// compress merges buf into the centroids, merging adjacent centroids as
+// long as the difference of the scale function across each is at most 1.
+func (t *TDigest) compress() {
+	if len(t.buf) == 0 {
+		return
+	}
+	all := append(t.centroids, t.buf...)
+	sort.SliceStable(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+	t.buf = t.buf[:0]
+
+	merged := all[:1]
+	var before float64 // The weight of the centroids before the last.
+	kLo := t.scale(0)
+	for _, c := range all[1:] {
+		last := &merged[len(merged)-1]
+		if t.scale((before+last.weight+c.weight)/t.count)-kLo <= 1 {
+			last.weight += c.weight
+			last.mean += (c.mean - last.mean) * c.weight / last.weight
+			continue
+		}
+		before += last.weight
+		kLo = t.scale(before / t.count)
+		merged = append(merged, c)
+	}
+	t.centroids = merged
+}
+
+// scale is the scale function k₁ of the paper, which makes centroids
+// smaller towards the extremes.
+func (t *TDigest) scale(q float64) float64 {
+	return t.compression / (2 * math.Pi) * math.Asin(2*math.Min(1, q)-1)
+}
+
+// Quantile returns an estimate of the q-quantile of the points in t, for q
+// in [0, 1], by interpolating between the means of the centroids. It
+// returns NaN if t is empty.
+func (t *TDigest) Quantile(q float64) float64 {
+	if t.count == 0 {
+		return math.NaN()
+	}
+	t.compress()
+	if q <= 0 {
+		return t.min
+	}
+	if q >= 1 {
+		return t.max
+	}
+
+	// Each centroid's mean is at the middle of its weight, and the min and
+	// max are at the ends.
+	target := q * t.count
+	prevAt, prevMean := 0.0, t.min
+	var at float64
+	for _, c := range t.centroids {
+		mid := at + c.weight/2
+		if target < mid {
+			return interpolate(target, prevAt, mid, prevMean, c.mean)
+		}
+		at += c.weight
+		prevAt, prevMean = mid, c.mean
+	}
+	return interpolate(target, prevAt, t.count, prevMean, t.max)
+}
+
+// interpolate returns the value at x on the line from (x0, y0) to (x1, y1).
+func interpolate(x, x0, x1, y0, y1 float64) float64 {
+	if x1 <= x0 {
+		return y1
+	}
+	return y0 + (y1-y0)*(x-x0)/(x1-x0)
+}
+
+// tdigestVersion is the version of the binary encoding of TDigests.
+const tdigestVersion = 1
+
+// MarshalBinary encodes t as follows, with all numbers in little-endian
+// order:
+//
+//	version     byte (1)
+//	compression float64
+//	min, max    float64
+//	n           uvarint
+//	centroids   n × (mean float64, weight float64), in order of mean
+func (t *TDigest) MarshalBinary() ([]byte, error) {
+	t.compress()
+	b := make([]byte, 0, 1+3*8+binary.MaxVarintLen64+16*len(t.centroids))
+	b = append(b, tdigestVersion)
+	b = appendFloat(b, t.compression)
+	b = appendFloat(b, t.min)
+	b = appendFloat(b, t.max)
+	var n [binary.MaxVarintLen64]byte
+	b = append(b, n[:binary.PutUvarint(n[:], uint64(len(t.centroids)))]...)
+	for _, c := range t.centroids {
+		b = appendFloat(b, c.mean)
+		b = appendFloat(b, c.weight)
+	}
+	return b, nil
+}
+
+func appendFloat(b []byte, f float64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+	return append(b, buf[:]...)
+}
+
+func readFloat(b []byte) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(b))
+}
+
+var errShortTDigest = errors.New("stats: TDigest encoding is too short")
+
+// UnmarshalBinary decodes a TDigest encoded by MarshalBinary into t.
+func (t *TDigest) UnmarshalBinary(b []byte) error {
+	if len(b) < 1+3*8 {
+		return errShortTDigest
+	}
+	if b[0] != tdigestVersion {
+		return fmt.Errorf("stats: unknown TDigest encoding version %d", b[0])
+	}
+	d := tdigestJSON{Compression: readFloat(b[1:]), Min: readFloat(b[9:]), Max: readFloat(b[17:])}
+	n, l := binary.Uvarint(b[25:])
+	if l <= 0 {
+		return errShortTDigest
+	}
+	cs := b[25+l:]
+	if n > uint64(len(cs))/16 || uint64(len(cs)) != 16*n {
+		return fmt.Errorf("stats: TDigest encoding has %d bytes of centroids, expected %d centroids", len(cs), n)
+	}
+	d.Centroids = make([][2]float64, n)
+	for i := range d.Centroids {
+		d.Centroids[i] = [2]float64{readFloat(cs[16*i:]), readFloat(cs[16*i+8:])}
+	}
+	return t.set(&d)
+}
+
+// tdigestJSON is the JSON encoding of a TDigest. The min and max are
+// omitted if it's empty.
+type tdigestJSON struct {
+	Compression float64      `json:"compression"`
+	Min         float64      `json:"min,omitempty"`
+	Max         float64      `json:"max,omitempty"`
+	Centroids   [][2]float64 `json:"centroids"` // Pairs of mean and weight.
+}
+
+// MarshalJSON encodes t as a JSON object with its compression, min, max,
+// and centroids, each as an array of its mean and weight.
+func (t *TDigest) MarshalJSON() ([]byte, error) {
+	t.compress()
+	d := tdigestJSON{Compression: t.compression, Centroids: make([][2]float64, len(t.centroids))}
+	if t.count > 0 {
+		d.Min, d.Max = t.min, t.max
+	}
+	for i, c := range t.centroids {
+		d.Centroids[i] = [2]float64{c.mean, c.weight}
+	}
+	return json.Marshal(&d)
+}
+
+// UnmarshalJSON decodes a TDigest encoded by MarshalJSON into t.
+func (t *TDigest) UnmarshalJSON(b []byte) error {
+	var d tdigestJSON
+	if err := json.Unmarshal(b, &d); err != nil {
+		return err
+	}
+	if len(d.Centroids) == 0 {
+		d.Min, d.Max = math.Inf(1), math.Inf(-1)
+	}
+	return t.set(&d)
+}
+
+// set sets t to the decoded d, after checking that it's valid.
+func (t *TDigest) set(d *tdigestJSON) error {
+	if !(d.Compression >= 1) {
+		return fmt.Errorf("stats: invalid TDigest compression %v", d.Compression)
+	}
+	nt := TDigest{compression: d.Compression, min: d.Min, max: d.Max}
+	nt.centroids = make([]centroid, len(d.Centroids))
+	for i, c := range d.Centroids {
+		if !(c[1] > 0) || math.IsNaN(c[0]) {
+			return fmt.Errorf("stats: invalid TDigest centroid %v", c)
+		}
+		nt.centroids[i] = centroid{c[0], c[1]}
+		nt.count += c[1]
+	}
+	sort.SliceStable(nt.centroids, func(i, j int) bool { return nt.centroids[i].mean < nt.centroids[j].mean })
+	if n := len(nt.centroids); n > 0 {
+		// The min and max must bound the centroids, since quantiles are
+		// interpolated between them.
+		if !(nt.min <= nt.centroids[0].mean && nt.centroids[n-1].mean <= nt.max) {
+			return fmt.Errorf("stats: invalid TDigest min %v and max %v for centroid means from %v to %v", nt.min, nt.max, nt.centroids[0].mean, nt.centroids[n-1].mean)
+		}
+	}
+	*t = nt
+	return nil
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// checkQuantiles checks the quantiles of t against those of the sorted
+// points xs.
+func checkQuantiles(t *testing.T, name string, d *TDigest, xs []float64) {
+	t.Helper()
+	for _, test := range []struct {
+		q, tolerance float64 // The tolerance is in quantiles.
+	}{
+		{0.001, 0.0005},
+		{0.01, 0.001},
+		{0.1, 0.005},
+		{0.5, 0.01},
+		{0.9, 0.005},
+		{0.99, 0.001},
+		{0.999, 0.0005},
+	} {
+		v := d.Quantile(test.q)
+		// Find the quantile of v among xs.
+		q := float64(sort.SearchFloat64s(xs, v)) / float64(len(xs))
+		if math.Abs(q-test.q) > test.tolerance {
+			t.Errorf("%s: Quantile(%v) = %v, which is the %v quantile", name, test.q, v, q)
+		}
+	}
+	if v := d.Quantile(0); v != xs[0] {
+		t.Errorf("%s: Quantile(0) = %v, expected the min %v", name, v, xs[0])
+	}
+	if v := d.Quantile(1); v != xs[len(xs)-1] {
+		t.Errorf("%s: Quantile(1) = %v, expected the max %v", name, v, xs[len(xs)-1])
+	}
+}
+
+func TestTDigest(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, dist := range []struct {
+		name string
+		gen  func() float64
+	}{
+		{"uniform", r.Float64},
+		{"exponential", r.ExpFloat64},
+		{"normal", r.NormFloat64},
+	} {
+		d := NewTDigest(0)
+		xs := make([]float64, 100000)
+		for i := range xs {
+			xs[i] = dist.gen()
+			d.Add(xs[i])
+		}
+		sort.Float64s(xs)
+		checkQuantiles(t, dist.name, d, xs)
+		if d.Count() != float64(len(xs)) {
+			t.Errorf("%s: Count() = %v, expected %d", dist.name, d.Count(), len(xs))
+		}
+		if n := len(d.centroids); n > 2*DefaultCompression {
+			t.Errorf("%s: digest has %d centroids, expected at most %d", dist.name, n, 2*DefaultCompression)
+		}
+	}
+}
+
+func TestTDigestSmall(t *testing.T) {
+	d := NewTDigest(0)
+	if v := d.Quantile(0.5); !math.IsNaN(v) {
+		t.Errorf("Quantile() of an empty digest = %v, expected NaN", v)
+	}
+	// With few points, each is its own centroid, so the quantiles are
+	// exact at the points.
+	for _, x := range []float64{5, 1, 4, 2, 3} {
+		d.Add(x)
+	}
+	for _, test := range []struct {
+		q, expected float64
+	}{
+		{0, 1},
+		{0.3, 2},
+		{0.5, 3},
+		{0.7, 4},
+		{1, 5},
+	} {
+		if v := d.Quantile(test.q); v != test.expected {
+			t.Errorf("Quantile(%v) = %v, expected %v", test.q, v, test.expected)
+		}
+	}
+}
+
+func TestTDigestMerge(t *testing.T) {
+	// Merge the latencies of 10 runs, each of which is a little
+	// different.
+	r := rand.New(rand.NewSource(1))
+	var xs []float64
+	merged := NewTDigest(0)
+	for run := 0; run < 10; run++ {
+		d := NewTDigest(0)
+		scale := 1 + float64(run)/10
+		for i := 0; i < 10000; i++ {
+			x := scale * r.ExpFloat64()
+			d.Add(x)
+			xs = append(xs, x)
+		}
+		merged.Merge(d)
+	}
+	sort.Float64s(xs)
+	checkQuantiles(t, "merged", merged, xs)
+	if merged.Count() != float64(len(xs)) {
+		t.Errorf("Count() = %v, expected %d", merged.Count(), len(xs))
+	}
+}
+
+// sameDigest reports whether a and b hold the same merged centroids.
+func sameDigest(a, b *TDigest) bool {
+	a.compress()
+	b.compress()
+	if a.compression != b.compression || a.count != b.count || a.min != b.min || a.max != b.max || len(a.centroids) != len(b.centroids) {
+		return false
+	}
+	for i := range a.centroids {
+		if a.centroids[i] != b.centroids[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTDigestEncoding(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	d := NewTDigest(50)
+	for i := 0; i < 10000; i++ {
+		d.AddWeighted(r.NormFloat64(), float64(1+i%3))
+	}
+
+	b, err := d.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromBinary TDigest
+	if err := fromBinary.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	j, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON TDigest
+	if err := json.Unmarshal(j, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range []*TDigest{&fromBinary, &fromJSON} {
+		if !sameDigest(got, d) {
+			t.Errorf("decoded %+v, expected %+v", got, d)
+		}
+	}
+
+	// The encodings of an empty digest round-trip too.
+	empty := NewTDigest(0)
+	b, _ = empty.MarshalBinary()
+	j, _ = json.Marshal(empty)
+	if err := fromBinary.UnmarshalBinary(b); err != nil || !sameDigest(&fromBinary, empty) {
+		t.Errorf("decoded empty digest as %+v, %v", &fromBinary, err)
+	}
+	if err := json.Unmarshal(j, &fromJSON); err != nil || !sameDigest(&fromJSON, empty) {
+		t.Errorf("decoded empty digest from %s as %+v, %v", j, &fromJSON, err)
+	}
+
+	// Invalid encodings are rejected.
+	b, _ = d.MarshalBinary()
+	// A count of 1<<60 centroids, whose size in bytes overflows to 0.
+	overflow := make([]byte, 25+binary.MaxVarintLen64)
+	copy(overflow, b[:25])
+	overflow = overflow[:25+binary.PutUvarint(overflow[25:], 1<<60)]
+	// The min and max swapped.
+	swapped := append([]byte(nil), b...)
+	copy(swapped[9:17], b[17:25])
+	copy(swapped[17:25], b[9:17])
+	for name, bad := range map[string][]byte{
+		"empty":     nil,
+		"version":   append([]byte{2}, b[1:]...),
+		"truncated": b[:len(b)-1],
+		"overflow":  overflow,
+		"swapped":   swapped,
+	} {
+		if err := fromBinary.UnmarshalBinary(bad); err == nil {
+			t.Errorf("UnmarshalBinary() of %s encoding succeeded, expected an error", name)
+		}
+	}
+	for _, bad := range []string{
+		`{"compression": 0, "centroids": []}`,
+		`{"compression": 100, "min": 1, "max": 1, "centroids": [[1, 0]]}`,
+		`{"compression": 100, "min": 2, "max": 1, "centroids": [[1.5, 1]]}`,
+		`{"compression": 100, "min": 2, "max": 3, "centroids": [[1, 1], [2.5, 1]]}`,
+		`{"compression": 100, "min": 1, "max": 2, "centroids": [[1, 1], [2.5, 1]]}`,
+		`{"compression": 100, "centroids": [[1, 1]]}`,
+	} {
+		if err := json.Unmarshal([]byte(bad), &fromJSON); err == nil {
+			t.Errorf("UnmarshalJSON(%s) succeeded, expected an error", bad)
+		}
+	}
+}
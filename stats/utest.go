@@ -0,0 +1,189 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// UTest is the result of a Mann-Whitney U test.
+type UTest struct {
+	// N1 and N2 are the sizes of the two samples.
+	N1, N2 int
+
+	// U is the number of pairs of points, one from each sample, in which
+	// the point from the first sample is greater, counting ties as half.
+	U float64
+
+	// P is the two-sided p-value of the test.
+	P float64
+
+	// Exact is whether P was computed from the exact distribution of U,
+	// rather than its normal approximation.
+	Exact bool
+}
+
+// exactUMax is the largest sample size for which MannWhitneyU computes
+// exact p-values.
+const exactUMax = 49
+
+// MannWhitneyU performs a two-sided Mann-Whitney U test (also known as
+// the Wilcoxon rank-sum test) of whether xs and ys are drawn from the same
+// distribution, against the alternative that one tends to be greater than
+// the other. It makes no assumptions about the distribution, so it's
+// suitable for benchmark results, which are rarely normal.
+//
+// If both samples have fewer than 50 points and there are no ties, the
+// p-value is exact. Otherwise, it uses the normal approximation (see
+// MannWhitneyUApprox).
+func MannWhitneyU(xs, ys []float64) UTest {
+	u, ties := uStat(xs, ys)
+	if len(xs) <= exactUMax && len(ys) <= exactUMax && ties == 0 {
+		return UTest{N1: len(xs), N2: len(ys), U: u, P: uExactP(u, len(xs), len(ys)), Exact: true}
+	}
+	return UTest{N1: len(xs), N2: len(ys), U: u, P: uNormalP(u, len(xs), len(ys), ties)}
+}
+
+// MannWhitneyUApprox is like MannWhitneyU, but always uses the normal
+// approximation to the distribution of U, with corrections for ties and
+// continuity.
+func MannWhitneyUApprox(xs, ys []float64) UTest {
+	u, ties := uStat(xs, ys)
+	return UTest{N1: len(xs), N2: len(ys), U: u, P: uNormalP(u, len(xs), len(ys), ties)}
+}
+
+// uStat returns the U statistic of xs, and the tie correction term, which
+// is the sum of t³-t over each group of t tied values.
+func uStat(xs, ys []float64) (u, ties float64) {
+	type obs struct {
+		v float64
+		x bool
+	}
+	all := make([]obs, 0, len(xs)+len(ys))
+	for _, v := range xs {
+		all = append(all, obs{v, true})
+	}
+	for _, v := range ys {
+		all = append(all, obs{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+
+	// Sum the ranks of xs, giving tied values their average rank.
+	var rx float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		t := float64(j - i)
+		ties += t*t*t - t
+		for k := i; k < j; k++ {
+			if all[k].x {
+				rx += rank
+			}
+		}
+		i = j
+	}
+	n1 := float64(len(xs))
+	return rx - n1*(n1+1)/2, ties
+}
+
+// uNormalP returns the two-sided p-value of u using the normal
+// approximation.
+func uNormalP(u float64, n1, n2 int, ties float64) float64 {
+	m1, m2 := float64(n1), float64(n2)
+	n := m1 + m2
+	if n < 2 {
+		return 1
+	}
+	sigma := math.Sqrt(m1 * m2 / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sigma == 0 {
+		return 1
+	}
+	z := (math.Abs(u-m1*m2/2) - 0.5) / sigma
+	if z < 0 {
+		z = 0
+	}
+	return math.Erfc(z / math.Sqrt2)
+}
+
+// uExactP returns the two-sided p-value of u using the exact distribution
+// of U for samples of sizes n1 and n2 without ties.
+func uExactP(u float64, n1, n2 int) float64 {
+	cdf := uCDF(n1, n2)
+	k := int(u)
+	lower := cdf[k]
+	upper := 1.0
+	if k > 0 {
+		upper = 1 - cdf[k-1]
+	}
+	return math.Min(1, 2*math.Min(lower, upper))
+}
+
+// uCDF returns the cumulative distribution of U for samples of sizes n1
+// and n2 without ties: cdf[u] is the probability that U <= u.
+func uCDF(n1, n2 int) []float64 {
+	// p[n][u] is the probability that U = u for samples of m and n
+	// points, for each m in turn, from the recurrence
+	//
+	//	p(m, n, u) = m/(m+n) p(m-1, n, u-n) + n/(m+n) p(m, n-1, u)
+	//
+	// since the greatest point is from the first sample with probability
+	// m/(m+n), in which case it's greater than all n points of the
+	// second.
+	max := n1 * n2
+	prev := make([][]float64, n2+1)
+	cur := make([][]float64, n2+1)
+	for n := range prev {
+		prev[n] = make([]float64, max+1)
+		cur[n] = make([]float64, max+1)
+		prev[n][0] = 1
+	}
+	for m := 1; m <= n1; m++ {
+		for u := range cur[0] {
+			cur[0][u] = 0
+		}
+		cur[0][0] = 1
+		for n := 1; n <= n2; n++ {
+			pm, pn := float64(m)/float64(m+n), float64(n)/float64(m+n)
+			for u := 0; u <= m*n; u++ {
+				v := pn * cur[n-1][u]
+				if u >= n {
+					v += pm * prev[n][u-n]
+				}
+				cur[n][u] = v
+			}
+		}
+		prev, cur = cur, prev
+	}
+	cdf := prev[n2]
+	for u := 1; u <= max; u++ {
+		cdf[u] += cdf[u-1]
+	}
+	return cdf
+}
+
+// HodgesLehmann returns the Hodges-Lehmann estimate of the shift from xs to
+// ys, which is the median of the differences y-x over all pairs of points
+// from the two samples. It's the shift that best explains a difference
+// found by MannWhitneyU. Both samples must be non-empty.
+func HodgesLehmann(xs, ys []float64) float64 {
+	return Median(pairDiffs(xs, ys))
+}
+
+// pairDiffs returns the differences y-x over all pairs of points from xs
+// and ys, in order.
+func pairDiffs(xs, ys []float64) []float64 {
+	diffs := make([]float64, 0, len(xs)*len(ys))
+	for _, x := range xs {
+		for _, y := range ys {
+			diffs = append(diffs, y-x)
+		}
+	}
+	sort.Float64s(diffs)
+	return diffs
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMannWhitneyU(t *testing.T) {
+	tests := []struct {
+		xs, ys []float64
+		u, p   float64
+		exact  bool
+	}{
+		// The p-values were computed with R's wilcox.test.
+		{[]float64{1, 2, 3}, []float64{4, 5, 6}, 0, 0.1, true},
+		{[]float64{4, 5, 6}, []float64{1, 2, 3}, 9, 0.1, true},
+		{[]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10}, 0, 0.007937, true},
+		{[]float64{1, 3, 5, 7, 9}, []float64{2, 4, 6, 8, 10}, 10, 0.6905, true},
+		{[]float64{1, 2, 2, 3}, []float64{2, 3, 4, 5}, 2.5, 0.1367, false},
+		{[]float64{1, 1, 1}, []float64{1, 1, 1}, 4.5, 1, false},
+	}
+	for _, test := range tests {
+		r := MannWhitneyU(test.xs, test.ys)
+		if r.U != test.u || math.Abs(r.P-test.p) > 1e-4 || r.Exact != test.exact {
+			t.Errorf("MannWhitneyU(%v, %v) = %+v, expected U %v, P %v, Exact %v", test.xs, test.ys, r, test.u, test.p, test.exact)
+		}
+	}
+
+	// With exact=FALSE, correct=TRUE.
+	if r := MannWhitneyUApprox([]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10}); math.Abs(r.P-0.01219) > 1e-4 || r.Exact {
+		t.Errorf("MannWhitneyUApprox() = %+v, expected P 0.01219", r)
+	}
+
+	same := []float64{10, 11, 12, 13, 14, 15, 16, 17, 18, 19}
+	shifted := []float64{20, 21, 22, 23, 24, 25, 26, 27, 28, 29}
+	if p := MannWhitneyU(same, shifted).P; p >= 0.001 {
+		t.Errorf("p-value for disjoint samples is %v, expected < 0.001", p)
+	}
+	interleaved := []float64{10.5, 11.5, 12.5, 13.5, 14.5, 15.5, 16.5, 17.5, 18.5, 19.5}
+	if p := MannWhitneyU(same, interleaved).P; p < 0.5 {
+		t.Errorf("p-value for interleaved samples is %v, expected >= 0.5", p)
+	}
+}
+
+func TestMannWhitneyUExactSize(t *testing.T) {
+	// The p-value is exact for samples of up to exactUMax points.
+	for _, n := range []int{exactUMax, exactUMax + 1} {
+		xs, ys := make([]float64, n), make([]float64, n)
+		for i := range xs {
+			xs[i] = float64(2 * i)
+			ys[i] = float64(2*i + 1)
+		}
+		if r, want := MannWhitneyU(xs, ys), n <= exactUMax; r.Exact != want {
+			t.Errorf("MannWhitneyU with %d points: Exact is %v, expected %v", n, r.Exact, want)
+		}
+	}
+}
+
+func TestUCDF(t *testing.T) {
+	// The distribution of U is symmetric, and sums to 1.
+	for _, n := range [][2]int{{1, 1}, {3, 4}, {10, 7}, {49, 49}} {
+		cdf := uCDF(n[0], n[1])
+		max := n[0] * n[1]
+		if math.Abs(cdf[max]-1) > 1e-9 {
+			t.Errorf("uCDF(%d, %d) sums to %v, expected 1", n[0], n[1], cdf[max])
+		}
+		for u := 0; u < max; u++ {
+			if math.Abs(cdf[u]-(1-cdf[max-u-1])) > 1e-9 {
+				t.Errorf("uCDF(%d, %d) is not symmetric at %d", n[0], n[1], u)
+				break
+			}
+		}
+	}
+}
+
+func TestHodgesLehmann(t *testing.T) {
+	if v := HodgesLehmann([]float64{1, 2, 3}, []float64{4, 5, 6}); v != 3 {
+		t.Errorf("HodgesLehmann() = %v, expected 3", v)
+	}
+	// It's robust to outliers.
+	if v := HodgesLehmann([]float64{1, 2, 3, 4}, []float64{2, 3, 4, 1000}); v != 1 {
+		t.Errorf("HodgesLehmann() with an outlier = %v, expected 1", v)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Build is a port of the legacy build benchmark, which measures
+// 'go build -a -p $GOMAXPROCS cmd/go', and the size of the resulting binary.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"golang.org/x/benchmarks/sweet/benchmarks/internal/cgroups"
+	"golang.org/x/benchmarks/sweet/benchmarks/internal/driver"
+	"golang.org/x/benchmarks/sweet/benchmarks/internal/toolexec"
+	"golang.org/x/benchmarks/sweet/common"
+)
+
+var (
+	goTool string
+	tmpDir string
+)
+
+func init() {
+	driver.SetFlags(flag.CommandLine)
+	toolexec.SetFlags(flag.CommandLine)
+	flag.StringVar(&goTool, "go", "", "path to cmd/go binary")
+	flag.StringVar(&tmpDir, "tmp", "", "work directory")
+}
+
+func run() error {
+	const name = "Build"
+
+	// As in the legacy benchmark, build with the parallelism of
+	// GOMAXPROCS, which defaults to 1.
+	procs := os.Getenv("GOMAXPROCS")
+	if procs == "" {
+		procs = "1"
+	}
+	bin := filepath.Join(tmpDir, "go")
+	cmdArgs := []string{"build", "-o", bin, "-a", "-p", procs}
+	if toolexec.Needed() {
+		// Run the compiler and linker under this binary, so that
+		// diagnostic data, such as profiles for PGO, is collected
+		// from them.
+		toolexecFlags, err := toolexec.BuildFlags(name, tmpDir)
+		if err != nil {
+			return err
+		}
+		cmdArgs = append(cmdArgs, toolexecFlags...)
+	}
+	baseCmd := exec.Command(goTool, append(cmdArgs, "cmd/go")...)
+	baseCmd.Env = common.NewEnvFromEnviron().MustSet(
+		"GOROOT="+filepath.Dir(filepath.Dir(goTool)),
+		"GOMAXPROCS="+procs,
+	).Collapse()
+	baseCmd.Stdout = os.Stdout
+	baseCmd.Stderr = os.Stderr
+	cmd, err := cgroups.WrapCommand(baseCmd, "test.scope")
+	if err != nil {
+		return err
+	}
+	err = driver.RunBenchmark(name, func(d *driver.B) error {
+		if err := cmd.Run(); err != nil {
+			return err
+		}
+		d.StopTimer()
+		fi, err := os.Stat(bin)
+		if err != nil {
+			return err
+		}
+		d.Report("binary-bytes", uint64(fi.Size()))
+		return nil
+	}, driver.DoTime(true), driver.DoAvgRSS(cmd.RSSFunc()))
+	if err != nil || !toolexec.Needed() {
+		return err
+	}
+	return toolexec.Collect(name, tmpDir)
+}
+
+func main() {
+	flag.Parse()
+	if toolexec.Enabled() {
+		if err := toolexec.Run(tmpDir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if flag.NArg() != 0 || goTool == "" || tmpDir == "" {
+		fmt.Fprintf(os.Stderr, "usage: %s -go <go tool> -tmp <dir>\n", os.Args[0])
+		os.Exit(1)
+	}
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
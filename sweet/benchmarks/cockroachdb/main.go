@@ -16,6 +16,7 @@ import (
 	"golang.org/x/benchmarks/sweet/common/diagnostics"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"strconv"
@@ -439,14 +440,15 @@ func run(cfg *config) (err error) {
 		driver.DoPerf(true),
 	}
 	return driver.RunBenchmark(cfg.bench.reportName, func(d *driver.B) error {
-		// Set up diagnostics.
+		// Set up diagnostics, which we collect from the server.
+		diagPattern := diagnostics.ProcessPattern(cfg.bench.reportName, filepath.Base(cfg.cockroachdbBin))
 		var finishers []func() uint64
 		if driver.DiagnosticEnabled(diagnostics.CPUProfile) {
 			for _, inst := range instances {
 				finishers = append(finishers, server.PollDiagnostic(
 					inst.httpAddr(),
 					cfg.tmpDir,
-					cfg.bench.reportName,
+					diagPattern,
 					diagnostics.CPUProfile,
 				))
 			}
@@ -457,7 +459,7 @@ func run(cfg *config) (err error) {
 				stopTrace := server.PollDiagnostic(
 					inst.httpAddr(),
 					cfg.tmpDir,
-					cfg.bench.reportName,
+					diagPattern,
 					diagnostics.Trace,
 				)
 				finishers = append(finishers, func() uint64 {
@@ -477,7 +479,7 @@ func run(cfg *config) (err error) {
 					n, err := server.CollectDiagnostic(
 						inst.httpAddr(),
 						cfg.tmpDir,
-						cfg.bench.reportName,
+						diagPattern,
 						diagnostics.MemProfile,
 					)
 					if err != nil {
@@ -359,14 +359,15 @@ func run(cfg *config) (err error) {
 		driver.DoPerf(true),
 	}
 	return driver.RunBenchmark(cfg.bench.reportName, func(d *driver.B) error {
-		// Set up diagnostics.
+		// Set up diagnostics, which we collect from the server.
+		diagPattern := diagnostics.ProcessPattern(cfg.bench.reportName, filepath.Base(cfg.etcdBin))
 		var finishers []func() uint64
 		if driver.DiagnosticEnabled(diagnostics.CPUProfile) {
 			for _, inst := range instances {
 				finishers = append(finishers, server.PollDiagnostic(
 					inst.host(clientPort),
 					cfg.tmpDir,
-					cfg.bench.reportName,
+					diagPattern,
 					diagnostics.CPUProfile,
 				))
 			}
@@ -377,7 +378,7 @@ func run(cfg *config) (err error) {
 				stopTrace := server.PollDiagnostic(
 					inst.host(clientPort),
 					cfg.tmpDir,
-					cfg.bench.reportName,
+					diagPattern,
 					diagnostics.Trace,
 				)
 				finishers = append(finishers, func() uint64 {
@@ -397,7 +398,7 @@ func run(cfg *config) (err error) {
 					n, err := server.CollectDiagnostic(
 						inst.host(clientPort),
 						cfg.tmpDir,
-						cfg.bench.reportName,
+						diagPattern,
 						diagnostics.MemProfile,
 					)
 					if err != nil {
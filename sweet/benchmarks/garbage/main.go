@@ -0,0 +1,54 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Garbage is a port of the legacy garbage benchmark, which stresses the
+// garbage collector by repeatedly parsing the net/http package with
+// go/parser while keeping a fixed amount of the results live.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"golang.org/x/benchmarks/internal/garbage"
+	"golang.org/x/benchmarks/sweet/benchmarks/internal/driver"
+)
+
+var (
+	benchMem int
+	short    bool
+)
+
+func init() {
+	driver.SetFlags(flag.CommandLine)
+	flag.IntVar(&benchMem, "benchmem", 64, "approx RSS value to aim at, in MB")
+	flag.BoolVar(&short, "short", false, "whether to run a short version of this benchmark")
+}
+
+func run() error {
+	avail := (benchMem << 20) * 4 / 5 // 4/5 to account for non-heap memory
+	heap := garbage.NewHeap(avail)
+	ops := 500
+	if short {
+		ops = 20
+	}
+	return driver.RunBenchmark(fmt.Sprintf("Garbage/benchmem-MB=%d", benchMem), func(d *driver.B) error {
+		heap.Run(uint64(ops))
+		d.Ops(ops)
+		return nil
+	}, driver.InProcessMeasurementOptions...)
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 0 {
+		fmt.Fprintf(os.Stderr, "unexpected args\n")
+		os.Exit(1)
+	}
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"runtime/debug"
 	"time"
 
@@ -72,7 +73,7 @@ func main1() error {
 				continue
 			}
 			// runscCmd ensures these are created if necessary.
-			if err := driver.CopyDiagnosticData(cliCfg.profilePath(typ), typ, bench.name()); err != nil {
+			if err := driver.CopyDiagnosticData(cliCfg.profilePath(typ), typ, diagnostics.ProcessPattern(bench.name(), filepath.Base(cliCfg.runscPath))); err != nil {
 				return err
 			}
 		}
@@ -0,0 +1,63 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// HTTP is a port of the legacy HTTP benchmark, which makes requests of a
+// local HTTP server from 4*GOMAXPROCS goroutines, and measures their
+// latency.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+
+	"golang.org/x/benchmarks/internal/httpbench"
+	"golang.org/x/benchmarks/sweet/benchmarks/internal/driver"
+)
+
+var short bool
+
+func init() {
+	driver.SetFlags(flag.CommandLine)
+	flag.BoolVar(&short, "short", false, "whether to run a short version of this benchmark")
+}
+
+const procs = 4
+
+func run() error {
+	server, err := httpbench.NewServer(procs * runtime.GOMAXPROCS(0))
+	if err != nil {
+		return err
+	}
+	ops := 200000
+	if short {
+		ops = 1000
+	}
+	return driver.RunBenchmark("HTTP", func(d *driver.B) error {
+		latency := driver.ParallelLatency(ops, procs*runtime.GOMAXPROCS(0), func() {
+			server.Request()
+		})
+		d.StopTimer()
+
+		d.Report("p50-latency-ns", uint64(latency.Quantile(0.50)))
+		d.Report("p90-latency-ns", uint64(latency.Quantile(0.90)))
+		d.Report("p99-latency-ns", uint64(latency.Quantile(0.99)))
+		d.Report("p99.9-latency-ns", uint64(latency.Quantile(0.999)))
+		d.Ops(ops)
+		return nil
+	}, driver.InProcessMeasurementOptions...)
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 0 {
+		fmt.Fprintf(os.Stderr, "unexpected args\n")
+		os.Exit(1)
+	}
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -26,11 +26,13 @@ import (
 
 var (
 	coreDumpDir string
+	runIndex    int
 	diag        map[diagnostics.Type]*diagnostics.DriverConfig
 )
 
 func SetFlags(f *flag.FlagSet) {
 	f.StringVar(&coreDumpDir, "dump-cores", "", "dump a core file to the given directory after every benchmark run")
+	f.IntVar(&runIndex, diagnostics.RunIndexFlag, 0, "index of this run of the benchmark, used to name diagnostic data files")
 	diag = diagnostics.SetFlagsForDriver(f)
 }
 
@@ -446,9 +448,10 @@ func RunBenchmark(name string, f func(*B) error, opts ...RunOption) error {
 	stop := b.startRSSSampler()
 
 	// Make sure profile file(s) are created if necessary.
+	// Tag them with our own binary, since they're collected in-process.
 	for _, typ := range diagnostics.Types() {
 		if b.shouldCollectDiag(typ) {
-			f, err := newDiagnosticDataFile(typ, b.name)
+			f, err := newDiagnosticDataFile(typ, diagnostics.ProcessPattern(b.name, filepath.Base(os.Args[0])))
 			if err != nil {
 				return err
 			}
@@ -582,10 +585,34 @@ func PerfFlags() []string {
 	return strings.Split(diag[diagnostics.Perf].Flags, " ")
 }
 
+var (
+	diagSeqMu sync.Mutex
+	diagSeq   = make(map[string]int)
+)
+
+// newDiagnosticDataFile creates a new file for diagnostic data of type typ
+// named for pattern and the current run (see diagnostics.DataFileName).
+// Each file created for the same pattern and type in this run is given
+// the next sequence number.
 func newDiagnosticDataFile(typ diagnostics.Type, pattern string) (*os.File, error) {
 	cfg, ok := diag[typ]
 	if !ok || cfg.Dir == "" {
 		return nil, fmt.Errorf("this type of profile is not currently enabled")
 	}
-	return os.CreateTemp(cfg.Dir, pattern+"."+string(typ))
+	diagSeqMu.Lock()
+	defer diagSeqMu.Unlock()
+
+	key := pattern + "." + string(typ)
+	for {
+		seq := diagSeq[key]
+		diagSeq[key]++
+		name := filepath.Join(cfg.Dir, diagnostics.DataFileName(pattern, runIndex, seq, typ))
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			// Another process in this run, perhaps another instance
+			// of this benchmark binary, already claimed this name.
+			continue
+		}
+		return f, err
+	}
 }
@@ -0,0 +1,41 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package driver
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/benchmarks/stats"
+)
+
+// ParallelLatency calls f n times from g goroutines, and returns the
+// distribution of the latencies of the calls, in nanoseconds. Each
+// goroutine records its latencies in its own stats.TDigest, so that they
+// don't contend, and they're merged at the end.
+func ParallelLatency(n, g int, f func()) *stats.TDigest {
+	latency := stats.NewTDigest(0)
+	var mu sync.Mutex
+	remain := int64(n)
+	var wg sync.WaitGroup
+	for i := 0; i < g; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l := stats.NewTDigest(0)
+			for atomic.AddInt64(&remain, -1) >= 0 {
+				t0 := time.Now()
+				f()
+				l.Add(float64(time.Since(t0)))
+			}
+			mu.Lock()
+			latency.Merge(l)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return latency
+}
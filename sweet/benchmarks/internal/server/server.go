@@ -15,8 +15,8 @@ import (
 	"golang.org/x/benchmarks/sweet/common/diagnostics"
 )
 
-func CollectDiagnostic(host, tmpDir, benchName string, typ diagnostics.Type) (int64, error) {
-	f, err := os.CreateTemp(tmpDir, benchName+"."+string(typ))
+func CollectDiagnostic(host, tmpDir, pattern string, typ diagnostics.Type) (int64, error) {
+	f, err := os.CreateTemp(tmpDir, pattern+"."+string(typ))
 	if err != nil {
 		return 0, err
 	}
@@ -30,7 +30,7 @@ func CollectDiagnostic(host, tmpDir, benchName string, typ diagnostics.Type) (in
 	if err != nil {
 		return 0, err
 	}
-	return n, driver.CopyDiagnosticData(f.Name(), typ, benchName)
+	return n, driver.CopyDiagnosticData(f.Name(), typ, pattern)
 }
 
 func endpoint(typ diagnostics.Type) string {
@@ -45,7 +45,7 @@ func endpoint(typ diagnostics.Type) string {
 	panic("diagnostic " + string(typ) + " has no endpoint")
 }
 
-func PollDiagnostic(host, tmpDir, benchName string, typ diagnostics.Type) (stop func() uint64) {
+func PollDiagnostic(host, tmpDir, pattern string, typ diagnostics.Type) (stop func() uint64) {
 	// TODO(mknyszek): This is kind of a hack. We really should find a way to just
 	// enable diagnostic collection at a lower level for the entire server run.
 	var stopc chan struct{}
@@ -61,7 +61,7 @@ func PollDiagnostic(host, tmpDir, benchName string, typ diagnostics.Type) (stop
 				return
 			default:
 			}
-			n, err := CollectDiagnostic(host, tmpDir, benchName, typ)
+			n, err := CollectDiagnostic(host, tmpDir, pattern, typ)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "failed to read diagnostic %s: %v", typ, err)
 				return
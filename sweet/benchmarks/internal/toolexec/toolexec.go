@@ -0,0 +1,248 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package toolexec supports benchmarks of the go command, which run the
+// compiler and linker under the benchmark's own binary through
+// 'go build -toolexec', to collect diagnostic data from them, and to
+// measure the linker on its own.
+package toolexec
+
+import (
+	"flag"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/pprof/profile"
+	"golang.org/x/benchmarks/sweet/benchmarks/internal/driver"
+	"golang.org/x/benchmarks/sweet/common/diagnostics"
+	sprofile "golang.org/x/benchmarks/sweet/common/profile"
+)
+
+var (
+	enabled   bool
+	benchName string
+)
+
+// SetFlags registers the flags with which a benchmark binary is run as
+// a toolexec binary.
+func SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&enabled, "toolexec", false, "run as a toolexec binary")
+	f.StringVar(&benchName, "bench-name", "", "for -toolexec")
+}
+
+// Enabled reports whether the binary is being run as a toolexec binary,
+// in which case it should call Run instead of running its benchmark.
+func Enabled() bool {
+	return enabled
+}
+
+// toolTypes are the types of diagnostic data collected from the tools.
+var toolTypes = []diagnostics.Type{diagnostics.CPUProfile, diagnostics.MemProfile, diagnostics.Trace}
+
+// Needed reports whether any diagnostic data that can be collected from
+// the compiler and linker is enabled.
+func Needed() bool {
+	for _, typ := range toolTypes {
+		if driver.DiagnosticEnabled(typ) {
+			return true
+		}
+	}
+	return false
+}
+
+func resultsDir(tmpDir string) string {
+	return filepath.Join(tmpDir, "results")
+}
+
+// BuildFlags clears any stale results from tmpDir, and returns the flags
+// to pass to 'go build' for it to run the compiler and linker under this
+// binary, for the benchmark called name. The binary's own flags are
+// passed along, apart from -go and those for perf, which the tools don't
+// need.
+func BuildFlags(name, tmpDir string) ([]string, error) {
+	if err := os.RemoveAll(resultsDir(tmpDir)); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(resultsDir(tmpDir), 0777); err != nil {
+		return nil, err
+	}
+
+	selfPath, err := filepath.Abs(os.Args[0])
+	if err != nil {
+		return nil, err
+	}
+	selfCmd := []string{
+		selfPath, "-toolexec",
+		"-bench-name", name,
+	}
+	flag.CommandLine.Visit(func(f *flag.Flag) {
+		if f.Name == "go" || f.Name == "bench-name" || strings.HasPrefix(f.Name, "perf") {
+			// No need to pass this along.
+			return
+		}
+		selfCmd = append(selfCmd, "-"+f.Name, f.Value.String())
+	})
+	return []string{"-toolexec", strings.Join(selfCmd, " ")}, nil
+}
+
+// Collect writes out the diagnostic data collected into tmpDir from the
+// compiler and linker during the benchmark called name, as data for the
+// benchmarks name+"Compile" and name+"Link", and copies the linker's
+// results to stderr.
+func Collect(name, tmpDir string) error {
+	// Merge any CPU profiles produced, and write them out as the
+	// canonical profiles.
+	if driver.DiagnosticEnabled(diagnostics.CPUProfile) {
+		compileProfile, err := mergePprofProfiles(tmpDir, profilePrefix("compile", diagnostics.CPUProfile))
+		if err != nil {
+			return err
+		}
+		if err := driver.WritePprofProfile(compileProfile, diagnostics.CPUProfile, diagnostics.ProcessPattern(name+"Compile", "compile")); err != nil {
+			return err
+		}
+
+		linkProfile, err := mergePprofProfiles(tmpDir, profilePrefix("link", diagnostics.CPUProfile))
+		if err != nil {
+			return err
+		}
+		if err := driver.WritePprofProfile(linkProfile, diagnostics.CPUProfile, diagnostics.ProcessPattern(name+"Link", "link")); err != nil {
+			return err
+		}
+	}
+	if driver.DiagnosticEnabled(diagnostics.MemProfile) {
+		if err := copyPprofProfiles(tmpDir, "compile", diagnostics.MemProfile, diagnostics.ProcessPattern(name+"Compile", "compile")); err != nil {
+			return err
+		}
+		if err := copyPprofProfiles(tmpDir, "link", diagnostics.MemProfile, diagnostics.ProcessPattern(name+"Link", "link")); err != nil {
+			return err
+		}
+	}
+	if driver.DiagnosticEnabled(diagnostics.Trace) {
+		entries, err := os.ReadDir(tmpDir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if !strings.HasPrefix(entry.Name(), profilePrefix("compile", diagnostics.Trace)) {
+				continue
+			}
+			if err := driver.CopyDiagnosticData(filepath.Join(tmpDir, entry.Name()), diagnostics.Trace, diagnostics.ProcessPattern(name+"Compile", "compile")); err != nil {
+				return err
+			}
+		}
+	}
+	return printOtherResults(resultsDir(tmpDir))
+}
+
+func mergePprofProfiles(dir, prefix string) (*profile.Profile, error) {
+	profiles, err := sprofile.ReadDirPprof(dir, func(name string) bool {
+		return strings.HasPrefix(name, prefix)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return profile.Merge(profiles)
+}
+
+func copyPprofProfiles(dir, bin string, typ diagnostics.Type, finalPrefix string) error {
+	prefix := profilePrefix(bin, typ)
+	profiles, err := sprofile.ReadDirPprof(dir, func(name string) bool {
+		return strings.HasPrefix(name, prefix)
+	})
+	if err != nil {
+		return err
+	}
+	for _, profile := range profiles {
+		if err := driver.WritePprofProfile(profile, typ, finalPrefix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func profilePrefix(bin string, typ diagnostics.Type) string {
+	return bin + "-prof." + string(typ)
+}
+
+func printOtherResults(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(dir, name)
+		if strings.HasSuffix(name, ".results") {
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(os.Stderr, f); err != nil {
+				f.Close()
+				return err
+			}
+			f.Close()
+		}
+	}
+	return nil
+}
+
+// Run runs the tool named by the binary's arguments, collecting any
+// enabled diagnostic data from the compiler and linker into tmpDir, and
+// measuring the linker as the benchmark passed to BuildFlags, with the
+// suffix "Link".
+func Run(tmpDir string) error {
+	var benchSuffix string
+	benchmark := false
+	bin := filepath.Base(flag.Arg(0))
+	switch bin {
+	case "compile":
+	case "link":
+		benchSuffix = "Link"
+		benchmark = true
+	default:
+		cmd := exec.Command(flag.Args()[0], flag.Args()[1:]...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		return cmd.Run()
+	}
+	var extraFlags []string
+	for _, typ := range toolTypes {
+		if driver.DiagnosticEnabled(typ) {
+			if bin == "link" && typ == diagnostics.Trace {
+				// TODO(mknyszek): Traces are not supported for the linker.
+				continue
+			}
+			// Stake a claim for a filename.
+			f, err := os.CreateTemp(tmpDir, profilePrefix(bin, typ))
+			if err != nil {
+				return err
+			}
+			f.Close()
+			flag := "-" + string(typ)
+			if typ == diagnostics.Trace {
+				flag += "profile" // The compiler flag is -traceprofile.
+			}
+			extraFlags = append(extraFlags, flag, f.Name())
+		}
+	}
+	cmd := exec.Command(flag.Args()[0], append(extraFlags, flag.Args()[1:]...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if benchmark {
+		name := benchName + benchSuffix
+		f, err := os.Create(filepath.Join(resultsDir(tmpDir), name+".results"))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return driver.RunBenchmark(name, func(d *driver.B) error {
+			return cmd.Run()
+		}, driver.DoTime(true), driver.WriteResultsTo(f))
+	}
+	return cmd.Run()
+}
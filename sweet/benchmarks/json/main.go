@@ -0,0 +1,55 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// JSON is a port of the legacy JSON benchmark, which marshals and
+// unmarshals a ~2MB JSON string with a tree-like object hierarchy, in
+// 4*GOMAXPROCS goroutines.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+
+	"golang.org/x/benchmarks/internal/jsonbench"
+	"golang.org/x/benchmarks/sweet/benchmarks/internal/driver"
+)
+
+var short bool
+
+func init() {
+	driver.SetFlags(flag.CommandLine)
+	flag.BoolVar(&short, "short", false, "whether to run a short version of this benchmark")
+}
+
+func run() error {
+	ops := 200
+	if short {
+		ops = 4
+	}
+	return driver.RunBenchmark("JSON", func(d *driver.B) error {
+		latency := driver.ParallelLatency(ops, 4*runtime.GOMAXPROCS(0), jsonbench.Op)
+		d.StopTimer()
+
+		// The same quantiles as the legacy benchmark reports by default.
+		d.Report("p50-latency-ns", uint64(latency.Quantile(0.50)))
+		d.Report("p95-latency-ns", uint64(latency.Quantile(0.95)))
+		d.Report("p99-latency-ns", uint64(latency.Quantile(0.99)))
+		d.Ops(ops)
+		return nil
+	}, driver.InProcessMeasurementOptions...)
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 0 {
+		fmt.Fprintf(os.Stderr, "unexpected args\n")
+		os.Exit(1)
+	}
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
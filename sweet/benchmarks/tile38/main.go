@@ -312,7 +312,7 @@ func run(cfg *config) (err error) {
 					err = r
 					return
 				}
-				if r := driver.WritePprofProfile(p, typ, benchName); r != nil {
+				if r := driver.WritePprofProfile(p, typ, diagnostics.ProcessPattern(benchName, filepath.Base(cfg.serverBin))); r != nil {
 					err = r
 					return
 				}
@@ -338,7 +338,7 @@ func run(cfg *config) (err error) {
 			stopTrace := server.PollDiagnostic(
 				fmt.Sprintf("%s:%d", cfg.host, pprofPort),
 				cfg.tmpDir,
-				benchName,
+				diagnostics.ProcessPattern(benchName, filepath.Base(cfg.serverBin)),
 				diagnostics.Trace,
 			)
 			defer func() {
@@ -72,6 +72,14 @@ func NewStorageWriter(bucket, version string, auth AuthOption, force bool) (*sto
 
 func NewStorageReader(bucket, version string, auth AuthOption) (*storage.Reader, error) {
 	ctx := context.Background()
+	o, err := newStorageObjectReadOnly(ctx, bucket, version, auth)
+	if err != nil {
+		return nil, err
+	}
+	return o.NewReader(ctx)
+}
+
+func newStorageObjectReadOnly(ctx context.Context, bucket, version string, auth AuthOption) (*storage.ObjectHandle, error) {
 	opts := []option.ClientOption{option.WithScopes(storage.ScopeReadOnly)}
 	switch auth {
 	case AuthAppDefault:
@@ -89,5 +97,5 @@ func NewStorageReader(bucket, version string, auth AuthOption) (*storage.Reader,
 	if err != nil {
 		return nil, err
 	}
-	return client.Bucket(bucket).Object(VersionArchiveName(version)).NewReader(ctx)
+	return client.Bucket(bucket).Object(VersionArchiveName(version)), nil
 }
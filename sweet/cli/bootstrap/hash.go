@@ -27,6 +27,22 @@ func (h Hashes) Put(version string, hash string, force bool) bool {
 	return true
 }
 
+func manifestKey(version string) string {
+	return version + "/manifest"
+}
+
+// GetManifest returns the hash of the manifest in the assets archive
+// for version, if one was recorded.
+func (h Hashes) GetManifest(version string) (string, bool) {
+	return h.Get(manifestKey(version))
+}
+
+// PutManifest records the hash of the manifest in the assets archive
+// for version.
+func (h Hashes) PutManifest(version string, hash string, force bool) bool {
+	return h.Put(manifestKey(version), hash, force)
+}
+
 func ReadHashesFile(hashfile string) (Hashes, error) {
 	f, err := os.Open(hashfile)
 	if os.IsNotExist(err) {
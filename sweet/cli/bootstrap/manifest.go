@@ -0,0 +1,147 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bootstrap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// ManifestName is the name of the manifest file at the root
+// of an assets archive.
+const ManifestName = "sweet-manifest.json"
+
+// Manifest describes the files in an assets archive.
+type Manifest struct {
+	// Files maps the slash-separated path of each file in the archive
+	// to its hash (see Hash and CanonicalizeHash).
+	Files map[string]string `json:"files"`
+}
+
+// NewManifest returns a new empty Manifest.
+func NewManifest() *Manifest {
+	return &Manifest{Files: make(map[string]string)}
+}
+
+// NewManifestFS returns a Manifest describing all the regular files
+// in fsys, other than a manifest at its root.
+func NewManifestFS(fsys fs.FS) (*Manifest, error) {
+	m := NewManifest()
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || name == ManifestName {
+			return nil
+		}
+		f, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		h := Hash()
+		if _, err := io.Copy(h, f); err != nil {
+			return fmt.Errorf("reading %s: %w", name, err)
+		}
+		m.Files[name] = CanonicalizeHash(h)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ReadManifest reads a Manifest in JSON form from r.
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	m := NewManifest()
+	if err := json.NewDecoder(r).Decode(m); err != nil {
+		return nil, fmt.Errorf("reading assets manifest: %w", err)
+	}
+	return m, nil
+}
+
+// ReadManifestFS reads the Manifest at the root of fsys.
+//
+// Returns an error wrapping fs.ErrNotExist if fsys has no manifest.
+func ReadManifestFS(fsys fs.FS) (*Manifest, error) {
+	f, err := fsys.Open(ManifestName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadManifest(f)
+}
+
+// Marshal returns the Manifest in JSON form.
+func (m *Manifest) Marshal() ([]byte, error) {
+	return json.MarshalIndent(m, "", "\t")
+}
+
+// Names returns the sorted names of the files in the Manifest that
+// are in the directory dir, recursively. If dir is empty, the names
+// of all files are returned.
+func (m *Manifest) Names(dir string) []string {
+	var names []string
+	for name := range m.Files {
+		if dir == "" || strings.HasPrefix(name, dir+"/") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CorruptFileError indicates that an assets file does not match
+// its hash in the Manifest.
+type CorruptFileError struct {
+	Name      string
+	Want, Got string
+}
+
+func (e *CorruptFileError) Error() string {
+	return fmt.Sprintf("assets file %s is corrupt: expected hash %s, got %s", e.Name, e.Want, e.Got)
+}
+
+// Check reads all of r and checks that it matches the hash of
+// the file called name in the Manifest.
+func (m *Manifest) Check(name string, r io.Reader) error {
+	want, ok := m.Files[name]
+	if !ok {
+		return fmt.Errorf("assets file %s is not in the manifest", name)
+	}
+	h := Hash()
+	if _, err := io.Copy(h, r); err != nil {
+		return fmt.Errorf("reading assets file %s: %w", name, err)
+	}
+	if got := CanonicalizeHash(h); got != want {
+		return &CorruptFileError{Name: name, Want: want, Got: got}
+	}
+	return nil
+}
+
+// Verify checks that each file in the Manifest under the directory dir
+// (or all files, if dir is empty) is present in fsys and matches its hash.
+func (m *Manifest) Verify(fsys fs.FS, dir string) error {
+	for _, name := range m.Names(dir) {
+		f, err := fsys.Open(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("assets file %s is missing", name)
+		} else if err != nil {
+			return fmt.Errorf("opening assets file %s: %w", name, err)
+		}
+		err = m.Check(name, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
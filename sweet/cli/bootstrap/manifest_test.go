@@ -0,0 +1,124 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bootstrap_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"golang.org/x/benchmarks/sweet/cli/bootstrap"
+)
+
+func hashOf(s string) string {
+	h := bootstrap.Hash()
+	h.Write([]byte(s))
+	return bootstrap.CanonicalizeHash(h)
+}
+
+func TestManifestVerify(t *testing.T) {
+	m := bootstrap.NewManifest()
+	m.Files["a/x.txt"] = hashOf("x")
+	m.Files["a/sub/y.txt"] = hashOf("y")
+	m.Files["b/z.txt"] = hashOf("z")
+
+	// Round-trip the manifest.
+	b, err := m.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err = bootstrap.ReadManifest(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m.Names("a"), []string{"a/sub/y.txt", "a/x.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names(%q) = %q, want %q", "a", got, want)
+	}
+
+	fsys := fstest.MapFS{
+		"a/x.txt":     {Data: []byte("x")},
+		"a/sub/y.txt": {Data: []byte("y")},
+		"b/z.txt":     {Data: []byte("corrupted")},
+	}
+	if err := m.Verify(fsys, "a"); err != nil {
+		t.Errorf("unexpected error verifying a: %v", err)
+	}
+	var cerr *bootstrap.CorruptFileError
+	if err := m.Verify(fsys, "b"); !errors.As(err, &cerr) || cerr.Name != "b/z.txt" {
+		t.Errorf("expected corrupt file error for b/z.txt, got %v", err)
+	}
+	delete(fsys, "a/sub/y.txt")
+	if err := m.Verify(fsys, ""); err == nil || !strings.Contains(err.Error(), "a/sub/y.txt") {
+		t.Errorf("expected error naming missing file a/sub/y.txt, got %v", err)
+	}
+
+	if _, err := bootstrap.ReadManifestFS(fsys); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist reading missing manifest, got %v", err)
+	}
+}
+
+func TestRandomAccessSource(t *testing.T) {
+	cache := t.TempDir()
+	const version = "v1.2.3"
+
+	// Write out an archive that's larger than a single read chunk,
+	// with a small file at the end.
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	big := strings.Repeat("0123456789abcdef", 1<<17)
+	for name, data := range map[string]string{"a/big": big, "b/small": "small"} {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(cache, bootstrap.VersionArchiveName(version))
+	if err := os.WriteFile(archive, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(bootstrap.NewCacheHandler(cache))
+	defer srv.Close()
+	for _, src := range []bootstrap.RandomAccessSource{
+		&bootstrap.FileSource{Path: cache},
+		&bootstrap.HTTPSource{BaseURL: srv.URL, Client: srv.Client()},
+	} {
+		ra, size, err := src.OpenReaderAt(version)
+		if err != nil {
+			t.Fatalf("opening %s: %v", src, err)
+		}
+		if size != int64(buf.Len()) {
+			t.Errorf("%s: got size %d, want %d", src, size, buf.Len())
+		}
+		zr, err := zip.NewReader(ra, size)
+		if err != nil {
+			t.Fatalf("%s: %v", src, err)
+		}
+		for name, want := range map[string]string{"b/small": "small", "a/big": big} {
+			b, err := fs.ReadFile(zr, name)
+			if err != nil {
+				t.Errorf("%s: reading %s: %v", src, name, err)
+			} else if string(b) != want {
+				t.Errorf("%s: %s has wrong contents", src, name)
+			}
+		}
+		ra.Close()
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bootstrap
+
+import (
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var archiveNameRegexp = regexp.MustCompile(`^assets-v\d+\.\d+\.\d+\.zip$`)
+
+// IsArchiveName reports whether name is the canonical name of
+// an assets archive (see VersionArchiveName).
+func IsArchiveName(name string) bool {
+	return archiveNameRegexp.MatchString(name)
+}
+
+// NewCacheHandler returns an http.Handler which serves the assets
+// archives in the cache directory cache, such that cache may be used
+// as an HTTPSource by other machines.
+//
+// Only assets archives are served; all other requests receive
+// a 404 response.
+func NewCacheHandler(cache string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		if !IsArchiveName(name) {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, filepath.Join(cache, name))
+	})
+}
@@ -0,0 +1,290 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Source is a location from which versioned assets archives
+// may be retrieved.
+type Source interface {
+	// Open returns a reader for the assets archive for the given version.
+	Open(version string) (io.ReadCloser, error)
+
+	// String returns a human-readable description of the Source.
+	String() string
+}
+
+// ReaderAtCloser is an io.ReaderAt that must be closed after use.
+type ReaderAtCloser interface {
+	io.ReaderAt
+	io.Closer
+}
+
+// RandomAccessSource is a Source that also supports random access into
+// assets archives, such that individual files may be retrieved from an
+// archive without retrieving the whole archive.
+type RandomAccessSource interface {
+	Source
+
+	// OpenReaderAt returns a random-access reader for the assets archive
+	// for the given version, along with the archive's size.
+	OpenReaderAt(version string) (ReaderAtCloser, int64, error)
+}
+
+// ParseSource returns a Source from a string description.
+//
+// The description takes one of the following forms:
+//
+//	gs://<bucket>          a GCS bucket, accessed with auth
+//	http(s)://<host>/<dir> a plain HTTP(S) mirror, such as one served by
+//	                       'sweet serve-assets'
+//	file://<path>          a local directory containing assets archives,
+//	                       or a path to an assets archive
+//	<path>                 same as file://<path>
+func ParseSource(s string, auth AuthOption) (Source, error) {
+	switch {
+	case strings.HasPrefix(s, "gs://"):
+		bucket := strings.TrimSuffix(strings.TrimPrefix(s, "gs://"), "/")
+		if bucket == "" || strings.Contains(bucket, "/") {
+			return nil, fmt.Errorf("invalid GCS source %q: expected gs://<bucket>", s)
+		}
+		return &GCSSource{Bucket: bucket, Auth: auth}, nil
+	case strings.HasPrefix(s, "http://"), strings.HasPrefix(s, "https://"):
+		if _, err := url.Parse(s); err != nil {
+			return nil, fmt.Errorf("invalid HTTP source %q: %w", s, err)
+		}
+		return &HTTPSource{BaseURL: s}, nil
+	case strings.HasPrefix(s, "file://"):
+		s = strings.TrimPrefix(s, "file://")
+	}
+	if s == "" {
+		return nil, fmt.Errorf("empty assets source")
+	}
+	return &FileSource{Path: s}, nil
+}
+
+// GCSSource is a Source backed by a GCS bucket.
+type GCSSource struct {
+	Bucket string
+	Auth   AuthOption
+}
+
+func (s *GCSSource) Open(version string) (io.ReadCloser, error) {
+	return NewStorageReader(s.Bucket, version, s.Auth)
+}
+
+func (s *GCSSource) OpenReaderAt(version string) (ReaderAtCloser, int64, error) {
+	ctx := context.Background()
+	o, err := newStorageObjectReadOnly(ctx, s.Bucket, version, s.Auth)
+	if err != nil {
+		return nil, 0, err
+	}
+	attrs, err := o.Attrs(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	r := &chunkedReaderAt{
+		size: attrs.Size,
+		fetch: func(off, n int64) (io.ReadCloser, error) {
+			return o.NewRangeReader(ctx, off, n)
+		},
+	}
+	return r, attrs.Size, nil
+}
+
+func (s *GCSSource) String() string {
+	return "gs://" + s.Bucket
+}
+
+// HTTPSource is a Source backed by a plain HTTP(S) server that serves
+// assets archives under BaseURL by their canonical names
+// (see VersionArchiveName).
+type HTTPSource struct {
+	BaseURL string
+
+	// Client is the HTTP client to use. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+func (s *HTTPSource) client() *http.Client {
+	if s.Client == nil {
+		return http.DefaultClient
+	}
+	return s.Client
+}
+
+func (s *HTTPSource) url(version string) string {
+	return strings.TrimSuffix(s.BaseURL, "/") + "/" + VersionArchiveName(version)
+}
+
+func (s *HTTPSource) Open(version string) (io.ReadCloser, error) {
+	u := s.url(version)
+	resp, err := s.client().Get(u)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("fetching %s: %s", u, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func (s *HTTPSource) OpenReaderAt(version string) (ReaderAtCloser, int64, error) {
+	client := s.client()
+	u := s.url(version)
+	resp, err := client.Head(u)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("fetching %s: %s", u, resp.Status)
+	}
+	if resp.Header.Get("Accept-Ranges") != "bytes" || resp.ContentLength < 0 {
+		return nil, 0, fmt.Errorf("%s does not support range requests", u)
+	}
+	r := &chunkedReaderAt{
+		size: resp.ContentLength,
+		fetch: func(off, n int64) (io.ReadCloser, error) {
+			req, err := http.NewRequest(http.MethodGet, u, nil)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+n-1))
+			resp, err := client.Do(req)
+			if err != nil {
+				return nil, err
+			}
+			if resp.StatusCode != http.StatusPartialContent {
+				resp.Body.Close()
+				return nil, fmt.Errorf("fetching range of %s: %s", u, resp.Status)
+			}
+			return resp.Body, nil
+		},
+	}
+	return r, resp.ContentLength, nil
+}
+
+func (s *HTTPSource) String() string {
+	return s.BaseURL
+}
+
+// FileSource is a Source backed by the local filesystem.
+//
+// If Path is a directory, such as an assets cache (see CachedAssets),
+// assets archives are looked up within it by their canonical names.
+// Otherwise Path is assumed to be an assets archive, and is used for
+// any version.
+type FileSource struct {
+	Path string
+}
+
+func (s *FileSource) Open(version string) (io.ReadCloser, error) {
+	return s.open(version)
+}
+
+func (s *FileSource) OpenReaderAt(version string) (ReaderAtCloser, int64, error) {
+	f, err := s.open(version)
+	if err != nil {
+		return nil, 0, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return f, fi.Size(), nil
+}
+
+func (s *FileSource) open(version string) (*os.File, error) {
+	fi, err := os.Stat(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return os.Open(filepath.Join(s.Path, VersionArchiveName(version)))
+	}
+	return os.Open(s.Path)
+}
+
+func (s *FileSource) String() string {
+	return s.Path
+}
+
+// readAtChunkSize is the minimum amount of data that chunkedReaderAt
+// fetches at once. Reading a zip archive involves many small reads, so
+// this avoids issuing a request for each one.
+const readAtChunkSize = 1 << 20
+
+// chunkedReaderAt is an io.ReaderAt for remote data that fetches the
+// data in large chunks, caching the most recent one.
+type chunkedReaderAt struct {
+	size  int64
+	fetch func(off, n int64) (io.ReadCloser, error)
+
+	mu     sync.Mutex
+	buf    []byte
+	bufOff int64
+}
+
+func (r *chunkedReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset")
+	}
+	var n int
+	for n < len(p) {
+		cur := off + int64(n)
+		if cur >= r.size {
+			return n, io.EOF
+		}
+		if cur < r.bufOff || cur >= r.bufOff+int64(len(r.buf)) {
+			if err := r.fill(cur, int64(len(p)-n)); err != nil {
+				return n, err
+			}
+		}
+		n += copy(p[n:], r.buf[cur-r.bufOff:])
+	}
+	return n, nil
+}
+
+func (r *chunkedReaderAt) fill(off, want int64) error {
+	n := want
+	if n < readAtChunkSize {
+		n = readAtChunkSize
+	}
+	if off+n > r.size {
+		n = r.size - off
+	}
+	rc, err := r.fetch(off, n)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(rc, buf); err != nil {
+		return err
+	}
+	r.buf = buf
+	r.bufOff = off
+	return nil
+}
+
+func (r *chunkedReaderAt) Close() error {
+	return nil
+}
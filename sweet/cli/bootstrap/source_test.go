@@ -0,0 +1,106 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bootstrap_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/benchmarks/sweet/cli/bootstrap"
+)
+
+func TestParseSource(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want string
+		err  bool
+	}{
+		{in: "gs://go-sweet-assets", want: "gs://go-sweet-assets"},
+		{in: "gs://go-sweet-assets/", want: "gs://go-sweet-assets"},
+		{in: "gs://", err: true},
+		{in: "gs://a/b", err: true},
+		{in: "http://10.0.0.1:8080", want: "http://10.0.0.1:8080"},
+		{in: "https://example.com/sweet/", want: "https://example.com/sweet/"},
+		{in: "file:///mnt/assets", want: "/mnt/assets"},
+		{in: "/mnt/assets/assets-v0.3.0.zip", want: "/mnt/assets/assets-v0.3.0.zip"},
+		{in: "", err: true},
+	} {
+		src, err := bootstrap.ParseSource(test.in, bootstrap.AuthNone)
+		if test.err {
+			if err == nil {
+				t.Errorf("ParseSource(%q): expected error, got %s", test.in, src)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseSource(%q): unexpected error: %v", test.in, err)
+			continue
+		}
+		if got := src.String(); got != test.want {
+			t.Errorf("ParseSource(%q) = %s, want %s", test.in, got, test.want)
+		}
+	}
+}
+
+func readAll(t *testing.T, src bootstrap.Source, version string) string {
+	t.Helper()
+	rc, err := src.Open(version)
+	if err != nil {
+		t.Fatalf("opening %s from %s: %v", version, src, err)
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("reading %s from %s: %v", version, src, err)
+	}
+	return string(b)
+}
+
+func TestFileAndHTTPSource(t *testing.T) {
+	cache := t.TempDir()
+	const version = "v1.2.3"
+	const contents = "not really a zip file"
+	archive := filepath.Join(cache, bootstrap.VersionArchiveName(version))
+	if err := os.WriteFile(archive, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cache, "secret"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Directories and archives both work as a FileSource.
+	if got := readAll(t, &bootstrap.FileSource{Path: cache}, version); got != contents {
+		t.Errorf("read %q from directory source, want %q", got, contents)
+	}
+	if got := readAll(t, &bootstrap.FileSource{Path: archive}, version); got != contents {
+		t.Errorf("read %q from archive source, want %q", got, contents)
+	}
+	if _, err := (&bootstrap.FileSource{Path: cache}).Open("v0.0.1"); err == nil {
+		t.Error("expected error opening missing version from directory source")
+	}
+
+	// Serve the cache and read it back.
+	srv := httptest.NewServer(bootstrap.NewCacheHandler(cache))
+	defer srv.Close()
+	src := &bootstrap.HTTPSource{BaseURL: srv.URL + "/", Client: srv.Client()}
+	if got := readAll(t, src, version); got != contents {
+		t.Errorf("read %q from HTTP source, want %q", got, contents)
+	}
+	if _, err := src.Open("v0.0.1"); err == nil {
+		t.Error("expected error opening missing version from HTTP source")
+	}
+	resp, err := srv.Client().Get(srv.URL + "/secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("got status %s for non-archive file, want 404", resp.Status)
+	}
+}
@@ -0,0 +1,617 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/benchmarks/sweet/cli/bootstrap"
+	"golang.org/x/benchmarks/sweet/common"
+	"golang.org/x/benchmarks/sweet/common/log"
+	"golang.org/x/benchmarks/sweet/common/machine"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	agentUsage = `Runs benchmarks on behalf of 'sweet submit'.
+
+The agent accepts jobs over HTTP, each consisting of configs, the
+benchmarks to run, and optionally toolchains to run them with. Jobs are
+queued and run one at a time, as 'sweet run' would, with its checks for
+machine noise (see -wait-idle and -governor). The progress of each job
+may be followed as it runs, and its results are made available as an
+archive (see 'sweet run -archive').
+
+Jobs may run arbitrary code on this machine, so the agent must only be
+reachable by trusted clients. By default, it only listens on localhost;
+to accept jobs from other machines, pass an -addr such as :8088.
+
+Usage: %s agent [flags]
+`
+)
+
+// jobSpec describes a job submitted to an agent.
+type jobSpec struct {
+	// Configs is a config file, in the format accepted by 'sweet run'.
+	// The goroot of any config for which a toolchain is uploaded with the
+	// job is ignored.
+	Configs string `json:"configs"`
+
+	Run   []string `json:"run,omitempty"`
+	Count int      `json:"count,omitempty"`
+	Short bool     `json:"short,omitempty"`
+	PGO   bool     `json:"pgo,omitempty"`
+}
+
+// jobStatus describes the state of a job on an agent.
+type jobStatus struct {
+	ID    string   `json:"id"`
+	State jobState `json:"state"`
+
+	// Position is the number of jobs ahead of this one in the queue,
+	// if it is queued.
+	Position int `json:"position,omitempty"`
+
+	// Error describes why the job failed, if it did.
+	Error string `json:"error,omitempty"`
+}
+
+type jobState string
+
+const (
+	jobQueued  jobState = "queued"
+	jobRunning jobState = "running"
+	jobDone    jobState = "done"
+	jobFailed  jobState = "failed"
+)
+
+// Names of the parts of a job submission.
+const (
+	jobSpecPart      = "job"
+	jobToolchainPart = "toolchain:" // Followed by the config name.
+)
+
+// Names of files and directories in a job's directory.
+const (
+	jobConfigs    = "configs.toml"
+	jobToolchains = "toolchains"
+	jobArchive    = "results.tar.gz"
+	jobLog        = "log.txt"
+)
+
+// job is a job on an agent.
+type job struct {
+	id  string
+	dir string
+	// goroots maps config names to the toolchains uploaded for them.
+	goroots map[string]string
+	spec    jobSpec
+
+	mu      sync.Mutex
+	state   jobState
+	err     error
+	log     *os.File      // The job's log, kept in its directory; nil once it finishes.
+	changed chan struct{} // Closed and replaced when the log or state changes.
+}
+
+// Write appends p to the job's log.
+func (j *job) Write(p []byte) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.log == nil {
+		return 0, os.ErrClosed
+	}
+	n, err := j.log.Write(p)
+	j.notifyLocked()
+	return n, err
+}
+
+func (j *job) notifyLocked() {
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+func (j *job) setState(state jobState, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.state = state
+	j.err = err
+	if (state == jobDone || state == jobFailed) && j.log != nil {
+		if err := j.log.Close(); err != nil {
+			log.Printf("closing log of job %s: %v", j.id, err)
+		}
+		j.log = nil
+	}
+	j.notifyLocked()
+}
+
+// logSince returns the job's log from offset off, a channel that will be
+// closed when there's more, and whether the job has finished.
+func (j *job) logSince(off int64) ([]byte, <-chan struct{}, bool, error) {
+	j.mu.Lock()
+	changed := j.changed
+	finished := j.state == jobDone || j.state == jobFailed
+	j.mu.Unlock()
+
+	// Anything written after this point is signaled by changed.
+	f, err := os.Open(filepath.Join(j.dir, jobLog))
+	if err != nil {
+		return nil, nil, false, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(off, io.SeekStart); err != nil {
+		return nil, nil, false, err
+	}
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return b, changed, finished, nil
+}
+
+type agentCmd struct {
+	addr        string
+	maxUpload   int64
+	dir         string
+	benchDir    string
+	assetsDir   string
+	cache       string
+	waitIdle    bool
+	idleTimeout time.Duration
+	governor    machine.GovernorPolicy
+}
+
+func (*agentCmd) Name() string { return "agent" }
+func (*agentCmd) Synopsis() string {
+	return "Runs benchmarks on behalf of 'sweet submit'."
+}
+func (*agentCmd) PrintUsage(w io.Writer, base string) {
+	fmt.Fprintf(w, agentUsage, base)
+}
+
+func (c *agentCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&c.addr, "addr", "localhost:8088", "address to listen on")
+	f.Int64Var(&c.maxUpload, "max-upload", maxUploadDefault, "maximum size of a job submission, including its toolchains, in bytes")
+	f.StringVar(&c.dir, "dir", "./sweet-agent", "directory to keep jobs and their results in")
+	f.StringVar(&c.benchDir, "bench-dir", "./benchmarks", "the benchmarks directory in the sweet source")
+	f.StringVar(&c.assetsDir, "assets-dir", "", "a directory containing uncompressed assets for sweet benchmarks (overrides -cache)")
+	f.StringVar(&c.cache, "cache", bootstrap.CacheDefault(), "cache location for assets")
+	f.BoolVar(&c.waitIdle, "wait-idle", true, fmt.Sprintf("wait for the 1-minute load average to drop below %.1f before running each job", idleMaxLoad))
+	f.DurationVar(&c.idleTimeout, "wait-idle-timeout", idleTimeoutDefault, "how long -wait-idle waits before giving up on a job (0 means forever)")
+	c.governor = machine.GovernorRefuse
+	f.Var(&c.governor, "governor", "what to do if the CPU frequency governor is not 'performance' (options: ignore, warn, refuse)")
+}
+
+func (c *agentCmd) Run(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("unexpected arguments")
+	}
+	log.SetActivityLog(true)
+
+	a, err := newAgent(c.dir)
+	if err != nil {
+		return err
+	}
+	a.run = c.runJob
+	a.maxUpload = c.maxUpload
+	go a.serve()
+
+	log.Printf("Listening on %s", c.addr)
+	return http.ListenAndServe(c.addr, a)
+}
+
+// runJob runs j with 'sweet run', writing its results archive into its
+// directory. It runs in its own process, so that a job can't disturb the
+// agent's state, or take the agent down with it.
+func (c *agentCmd) runJob(j *job) error {
+	// Toolchains and work directories can be large, and aren't needed
+	// once the results are archived.
+	defer os.RemoveAll(filepath.Join(j.dir, jobToolchains))
+	defer os.RemoveAll(filepath.Join(j.dir, "work"))
+	args, err := c.runArgs(j)
+	if err != nil {
+		return err
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(self, args...)
+	cmd.Stdout = j
+	cmd.Stderr = j
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("sweet run: %w", err)
+	}
+	return nil
+}
+
+// runArgs writes j's configs into its directory, with their goroots
+// pointing at the toolchains uploaded for them, and returns the arguments
+// to 'sweet run' for j.
+func (c *agentCmd) runArgs(j *job) ([]string, error) {
+	// Fill in the uploaded toolchains.
+	var cfgs common.ConfigFile
+	if _, err := toml.Decode(j.spec.Configs, &cfgs); err != nil {
+		return nil, fmt.Errorf("parsing configs: %w", err)
+	}
+	for _, cfg := range cfgs.Configs {
+		if goroot, ok := j.goroots[cfg.Name]; ok {
+			cfg.GoRoot = goroot
+		} else if cfg.GoRoot != "" && !filepath.IsAbs(cfg.GoRoot) {
+			return nil, fmt.Errorf("goroot for config %q must be an absolute path on the agent or uploaded", cfg.Name)
+		}
+	}
+	b, err := common.ConfigFileMarshalTOML(&cfgs)
+	if err != nil {
+		return nil, err
+	}
+	configFile := filepath.Join(j.dir, jobConfigs)
+	if err := os.WriteFile(configFile, b, 0644); err != nil {
+		return nil, err
+	}
+
+	args := []string{
+		"run",
+		"-results", filepath.Join(j.dir, "results"),
+		"-work-dir", filepath.Join(j.dir, "work"),
+		"-archive", filepath.Join(j.dir, jobArchive),
+		"-bench-dir", c.benchDir,
+		"-cache", c.cache,
+		"-governor", c.governor.String(),
+		"-count", strconv.Itoa(j.spec.Count),
+	}
+	if c.assetsDir != "" {
+		args = append(args, "-assets-dir", c.assetsDir)
+	}
+	if len(j.spec.Run) != 0 {
+		args = append(args, "-run", strings.Join(j.spec.Run, ","))
+	}
+	if c.waitIdle {
+		args = append(args, "-wait-idle", "-wait-idle-timeout", c.idleTimeout.String())
+	}
+	if j.spec.Short {
+		args = append(args, "-short")
+	}
+	if j.spec.PGO {
+		args = append(args, "-pgo")
+	}
+	return append(args, configFile), nil
+}
+
+// agent is the state of a 'sweet agent', which also serves its HTTP API:
+//
+//	POST /jobs                 submit a job, returning its status
+//	GET  /jobs/<id>            get a job's status
+//	GET  /jobs/<id>/log        stream a job's log until it finishes
+//	GET  /jobs/<id>/archive    download a job's results archive
+//
+// A job is submitted as a multipart form, with its jobSpec as JSON in a
+// part named "job", and a gzipped tar archive of the GOROOT to use for
+// each config that needs one uploaded in a part named "toolchain:<config>".
+// The GOROOT may be at the root of its archive or in a single top-level
+// directory, as in Go's release archives.
+type agent struct {
+	dir string
+
+	// run runs a job, which is in the running state, writing its
+	// progress to the job.
+	run func(*job) error
+
+	// maxUpload is the maximum size of a job submission, in bytes.
+	maxUpload int64
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	pending []*job
+	wake    chan struct{}
+}
+
+func newAgent(dir string) (*agent, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "jobs"), os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &agent{
+		dir:       dir,
+		maxUpload: maxUploadDefault,
+		jobs:      make(map[string]*job),
+		wake:      make(chan struct{}, 1),
+	}, nil
+}
+
+// maxUploadDefault is the default maximum size of a job submission, which
+// leaves room for a few toolchains.
+const maxUploadDefault = 2 << 30
+
+// serve runs queued jobs one at a time, forever.
+func (a *agent) serve() {
+	for {
+		a.mu.Lock()
+		var j *job
+		if len(a.pending) != 0 {
+			j = a.pending[0]
+			a.pending = a.pending[1:]
+		}
+		a.mu.Unlock()
+		if j == nil {
+			<-a.wake
+			continue
+		}
+
+		log.Printf("Running job %s", j.id)
+		j.setState(jobRunning, nil)
+		if err := a.run(j); err != nil {
+			log.Printf("Job %s failed: %v", j.id, err)
+			fmt.Fprintf(j, "error: %v\n", err)
+			j.setState(jobFailed, err)
+		} else {
+			log.Printf("Job %s done", j.id)
+			j.setState(jobDone, nil)
+		}
+	}
+}
+
+func (a *agent) status(j *job) jobStatus {
+	j.mu.Lock()
+	s := jobStatus{ID: j.id, State: j.state}
+	if j.err != nil {
+		s.Error = j.err.Error()
+	}
+	j.mu.Unlock()
+	if s.State == jobQueued {
+		a.mu.Lock()
+		for i, p := range a.pending {
+			if p == j {
+				s.Position = i
+			}
+		}
+		a.mu.Unlock()
+	}
+	return s
+}
+
+func (a *agent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/jobs")
+	if rest == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+	if rest == "" || rest == "/" {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		a.submit(w, r)
+		return
+	}
+	id, what, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+	a.mu.Lock()
+	j := a.jobs[id]
+	a.mu.Unlock()
+	if j == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	switch what {
+	case "":
+		writeJSON(w, a.status(j))
+	case "log":
+		a.streamLog(w, r, j)
+	case "archive":
+		f, err := os.Open(filepath.Join(j.dir, jobArchive))
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, "job has no results archive", http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/gzip")
+		http.ServeContent(w, r, jobArchive, fi.ModTime(), f)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (a *agent) submit(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, a.maxUpload)
+	dir, err := os.MkdirTemp(filepath.Join(a.dir, "jobs"), "job-")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	logFile, err := os.Create(filepath.Join(dir, jobLog))
+	if err != nil {
+		os.RemoveAll(dir)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	j := &job{
+		id:      filepath.Base(dir),
+		dir:     dir,
+		goroots: make(map[string]string),
+		state:   jobQueued,
+		log:     logFile,
+		changed: make(chan struct{}),
+	}
+	if err := j.receive(r); err != nil {
+		logFile.Close()
+		os.RemoveAll(dir)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	a.mu.Lock()
+	a.jobs[j.id] = j
+	a.pending = append(a.pending, j)
+	a.mu.Unlock()
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+	writeJSON(w, a.status(j))
+}
+
+// receive reads j's spec and toolchains from the submission in r.
+func (j *job) receive(r *http.Request) error {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return err
+	}
+	var haveSpec bool
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		name := p.FormName()
+		switch {
+		case name == jobSpecPart:
+			if err := json.NewDecoder(p).Decode(&j.spec); err != nil {
+				return fmt.Errorf("parsing job: %w", err)
+			}
+			haveSpec = true
+		case strings.HasPrefix(name, jobToolchainPart):
+			cfg := strings.TrimPrefix(name, jobToolchainPart)
+			if err := j.receiveToolchain(cfg, p); err != nil {
+				return fmt.Errorf("receiving toolchain for %s: %w", cfg, err)
+			}
+		default:
+			return fmt.Errorf("unexpected part %q", name)
+		}
+	}
+	if !haveSpec {
+		return fmt.Errorf("missing %q part", jobSpecPart)
+	}
+	if j.spec.Count < 0 {
+		return fmt.Errorf("count must not be negative")
+	}
+	var cfgs common.ConfigFile
+	if _, err := toml.Decode(j.spec.Configs, &cfgs); err != nil {
+		return fmt.Errorf("parsing configs: %w", err)
+	}
+	names := make(map[string]bool)
+	for _, cfg := range cfgs.Configs {
+		names[cfg.Name] = true
+	}
+	for cfg := range j.goroots {
+		if !names[cfg] {
+			return fmt.Errorf("toolchain uploaded for unknown config %q", cfg)
+		}
+	}
+	return nil
+}
+
+// receiveToolchain extracts the toolchain archive in p for the config cfg.
+func (j *job) receiveToolchain(cfg string, p *multipart.Part) error {
+	if cfg == "" || cfg != filepath.Base(cfg) || cfg == "." || cfg == ".." {
+		return fmt.Errorf("invalid config name")
+	}
+	if _, ok := j.goroots[cfg]; ok {
+		return fmt.Errorf("toolchain uploaded more than once")
+	}
+	dir := filepath.Join(j.dir, jobToolchains, cfg)
+	if err := untar(p, dir, true); err != nil {
+		return err
+	}
+	goroot, err := findGoroot(dir)
+	if err != nil {
+		return err
+	}
+	j.goroots[cfg] = goroot
+	return nil
+}
+
+// findGoroot returns the GOROOT extracted into dir, which is either dir
+// itself or its only subdirectory (as in Go's release archives).
+func findGoroot(dir string) (string, error) {
+	isGoroot := func(dir string) bool {
+		_, err := os.Stat(filepath.Join(dir, "bin", "go"))
+		return err == nil
+	}
+	if isGoroot(dir) {
+		return dir, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		if sub := filepath.Join(dir, entries[0].Name()); isGoroot(sub) {
+			return sub, nil
+		}
+	}
+	return "", fmt.Errorf("archive does not contain a GOROOT with bin/go")
+}
+
+// streamLog writes j's log to w as it's written, until j finishes or the
+// client goes away.
+func (a *agent) streamLog(w http.ResponseWriter, r *http.Request, j *job) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	flusher, _ := w.(http.Flusher)
+	var off int64
+	for {
+		b, changed, finished, err := j.logSince(off)
+		if err != nil {
+			if off == 0 {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		if len(b) != 0 {
+			if _, err := w.Write(b); err != nil {
+				return
+			}
+			off += int64(len(b))
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if finished {
+			return
+		}
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
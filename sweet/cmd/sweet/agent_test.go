@@ -0,0 +1,210 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/benchmarks/sweet/common"
+	"golang.org/x/benchmarks/sweet/common/log"
+	"golang.org/x/benchmarks/sweet/common/machine"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestAgent(t *testing.T) {
+	log.SetActivityLog(true)
+	defer log.SetActivityLog(false)
+
+	a, err := newAgent(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.run = func(j *job) error {
+		if len(j.spec.Run) == 0 {
+			return fmt.Errorf("nothing to run")
+		}
+		fmt.Fprintf(j, "Running %s with %s\n", strings.Join(j.spec.Run, ","), j.goroots["exp"])
+		if _, err := os.Stat(filepath.Join(j.goroots["exp"], "bin", "go")); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(j.dir, jobArchive), []byte("results"), 0644)
+	}
+	go a.serve()
+	srv := httptest.NewServer(a)
+	defer srv.Close()
+	client := &agentClient{url: srv.URL}
+
+	// Upload a toolchain from a directory.
+	goroot := filepath.Join(t.TempDir(), "go")
+	if err := os.MkdirAll(filepath.Join(goroot, "bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(goroot, "bin", "go"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	spec := &jobSpec{
+		Configs: "[[config]]\n  name = \"exp\"\n  goroot = \"/nonexistent\"\n",
+		Run:     []string{"markdown"},
+	}
+	st, err := client.submit(spec, map[string]string{"exp": goroot})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var progress bytes.Buffer
+	if err := client.follow(st.ID, &progress); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(progress.String(), "Running markdown with ") {
+		t.Errorf("log does not contain the job's progress:\n%s", progress.String())
+	}
+	// The log is kept in the job's directory, not in memory.
+	a.mu.Lock()
+	j := a.jobs[st.ID]
+	a.mu.Unlock()
+	if b, err := os.ReadFile(filepath.Join(j.dir, jobLog)); err != nil {
+		t.Error(err)
+	} else if string(b) != progress.String() {
+		t.Errorf("log file contains %q, want %q", b, progress.String())
+	}
+	if st, err = client.status(st.ID); err != nil {
+		t.Fatal(err)
+	} else if st.State != jobDone {
+		t.Fatalf("job is %s, want %s (error: %s)", st.State, jobDone, st.Error)
+	}
+	out := filepath.Join(t.TempDir(), "results.tar.gz")
+	if err := client.download(st.ID, out); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := os.ReadFile(out); err != nil {
+		t.Fatal(err)
+	} else if string(b) != "results" {
+		t.Errorf("got archive %q, want %q", b, "results")
+	}
+
+	// A failing job.
+	st, err = client.submit(&jobSpec{Configs: spec.Configs}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.follow(st.ID, &progress); err != nil {
+		t.Fatal(err)
+	}
+	if st, err = client.status(st.ID); err != nil {
+		t.Fatal(err)
+	} else if st.State != jobFailed || st.Error != "nothing to run" {
+		t.Errorf("got status %+v, want a failure", st)
+	}
+	if err := client.download(st.ID, out); err == nil {
+		t.Errorf("downloaded results for a job without any")
+	}
+
+	// A toolchain for a config that doesn't exist is rejected.
+	if _, err := client.submit(spec, map[string]string{"base": goroot}); err == nil {
+		t.Errorf("submitted a toolchain for an unknown config")
+	}
+
+	// Submissions larger than the limit are rejected.
+	small, err := newAgent(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	small.maxUpload = 64
+	smallSrv := httptest.NewServer(small)
+	defer smallSrv.Close()
+	smallClient := &agentClient{url: smallSrv.URL}
+	if _, err := smallClient.submit(spec, map[string]string{"exp": goroot}); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("submitting a job larger than the limit: got %v, want an error", err)
+	}
+}
+
+func TestAgentRunArgs(t *testing.T) {
+	dir := t.TempDir()
+	j := &job{
+		dir:     dir,
+		goroots: map[string]string{"exp": "/agent/jobs/job-1/toolchains/exp/go"},
+		spec: jobSpec{
+			Configs: `[[config]]
+  name = "base"
+  goroot = "/usr/local/go"
+  envbuild = ["GOAMD64=v3"]
+
+[[config]]
+  name = "exp"
+  goroot = "/client/go"
+`,
+			Run:   []string{"markdown", "tile38"},
+			Count: 5,
+			Short: true,
+			PGO:   true,
+		},
+	}
+	c := &agentCmd{
+		benchDir:    "/sweet/benchmarks",
+		cache:       "/cache",
+		waitIdle:    true,
+		idleTimeout: 10 * time.Minute,
+		governor:    machine.GovernorWarn,
+	}
+	args, err := c.runArgs(j)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configFile := filepath.Join(dir, jobConfigs)
+	want := []string{
+		"run",
+		"-results", filepath.Join(dir, "results"),
+		"-work-dir", filepath.Join(dir, "work"),
+		"-archive", filepath.Join(dir, jobArchive),
+		"-bench-dir", "/sweet/benchmarks",
+		"-cache", "/cache",
+		"-governor", "warn",
+		"-count", "5",
+		"-run", "markdown,tile38",
+		"-wait-idle", "-wait-idle-timeout", "10m0s",
+		"-short",
+		"-pgo",
+		configFile,
+	}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("got args\n\t%q\nwant\n\t%q", args, want)
+	}
+
+	// The uploaded toolchain replaces the goroot of its config, and the
+	// rest of the configs are left as they were.
+	b, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cfgs common.ConfigFile
+	if _, err := toml.Decode(string(b), &cfgs); err != nil {
+		t.Fatal(err)
+	}
+	goroots := make(map[string]string)
+	for _, cfg := range cfgs.Configs {
+		goroots[cfg.Name] = cfg.GoRoot
+	}
+	if want := map[string]string{"base": "/usr/local/go", "exp": "/agent/jobs/job-1/toolchains/exp/go"}; !reflect.DeepEqual(goroots, want) {
+		t.Errorf("got goroots %v, want %v", goroots, want)
+	}
+	if v, ok := cfgs.Configs[0].BuildEnv.Lookup("GOAMD64"); !ok || v != "v3" {
+		t.Errorf("GOAMD64 for base = %q, %v; want %q", v, ok, "v3")
+	}
+
+	// A goroot that's neither uploaded nor absolute can't be resolved
+	// on the agent.
+	j.spec.Configs = "[[config]]\n  name = \"base\"\n  goroot = \"go\"\n"
+	if _, err := c.runArgs(j); err == nil {
+		t.Errorf("runArgs accepted a relative goroot")
+	}
+}
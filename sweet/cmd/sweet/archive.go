@@ -0,0 +1,372 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/benchmarks/sweet/cli/bootstrap"
+	"golang.org/x/benchmarks/sweet/common"
+	"golang.org/x/benchmarks/sweet/common/log"
+)
+
+// Names of files and directories in a results archive.
+const (
+	archiveResultsDir   = "results"
+	archiveBuildInfoDir = "buildinfo"
+	archiveConfigs      = "configs.toml"
+	archiveEnvironment  = "environment.txt"
+)
+
+// archiveWriter writes a gzipped tar archive, recording the hash of each
+// file in a manifest (see bootstrap.Manifest) which is written out as the
+// last file in the archive.
+type archiveWriter struct {
+	f        *os.File
+	gz       *gzip.Writer
+	tw       *tar.Writer
+	manifest *bootstrap.Manifest
+	mtime    time.Time
+}
+
+func createArchive(name string, mtime time.Time) (*archiveWriter, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	gz := gzip.NewWriter(f)
+	return &archiveWriter{
+		f:        f,
+		gz:       gz,
+		tw:       tar.NewWriter(gz),
+		manifest: bootstrap.NewManifest(),
+		mtime:    mtime,
+	}, nil
+}
+
+// add adds a file called name to the archive with the contents of r.
+func (a *archiveWriter) add(name string, mode fs.FileMode, size int64, r io.Reader) error {
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     int64(mode.Perm()),
+		Size:     size,
+		ModTime:  a.mtime,
+	}
+	if err := a.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	h := bootstrap.Hash()
+	if _, err := io.Copy(a.tw, io.TeeReader(r, h)); err != nil {
+		return fmt.Errorf("archiving %s: %w", name, err)
+	}
+	a.manifest.Files[name] = bootstrap.CanonicalizeHash(h)
+	return nil
+}
+
+// addBytes adds a file called name to the archive containing b.
+func (a *archiveWriter) addBytes(name string, b []byte) error {
+	return a.add(name, 0644, int64(len(b)), bytes.NewReader(b))
+}
+
+// addDir adds all the regular files in dir to the archive, under the
+// directory called name.
+func (a *archiveWriter) addDir(name, dir string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return a.add(path.Join(name, filepath.ToSlash(rel)), fi.Mode(), fi.Size(), f)
+	})
+}
+
+// Close writes out the manifest and closes the archive.
+func (a *archiveWriter) Close() error {
+	b, err := a.manifest.Marshal()
+	if err == nil {
+		err = a.addBytes(bootstrap.ManifestName, b)
+	}
+	for _, c := range []io.Closer{a.tw, a.gz, a.f} {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// writeArchive writes a self-contained archive of the results of running
+// benchmarks under configs to c.archive, along with everything needed to
+// interpret them: the configs, how each binary was built, and the state of
+// the machine.
+func (c *runCmd) writeArchive(configs []*common.Config, benchmarks []*benchmark) (err error) {
+	log.Printf("Writing results archive to %s", c.archive)
+	a, err := createArchive(c.archive, c.runstamp)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := a.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	// Configs.
+	b, err := common.ConfigFileMarshalTOML(&common.ConfigFile{Configs: configs})
+	if err != nil {
+		return fmt.Errorf("marshaling configs: %w", err)
+	}
+	if err := a.addBytes(archiveConfigs, b); err != nil {
+		return err
+	}
+
+	// Environment.
+	env, err := c.environment(configs)
+	if err != nil {
+		return err
+	}
+	if err := a.addBytes(archiveEnvironment, env); err != nil {
+		return err
+	}
+
+	for _, bench := range benchmarks {
+		// Results and diagnostics.
+		resultsDir := c.benchmarkResultsDir(bench)
+		if _, err := os.Stat(resultsDir); errors.Is(err, fs.ErrNotExist) {
+			// The benchmark failed before producing any results.
+			continue
+		}
+		if err := a.addDir(path.Join(archiveResultsDir, bench.name), resultsDir); err != nil {
+			return err
+		}
+
+		// Build information for each binary built for the benchmark.
+		if err := c.archiveBuildInfo(a, bench, configs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archiveBuildInfo adds the build information for each binary built for
+// bench to a. This includes binaries built for configs derived from configs
+// (e.g. for PGO profiling runs), which share a toolchain with the config
+// they were derived from.
+func (c *runCmd) archiveBuildInfo(a *archiveWriter, bench *benchmark, configs []*common.Config) error {
+	cfgDirs, err := os.ReadDir(filepath.Join(c.workDir, bench.name))
+	if err != nil {
+		return err
+	}
+	for _, cfgDir := range cfgDirs {
+		var cfg *common.Config
+		for _, cc := range configs {
+			name := cfgDir.Name()
+			if name != cc.Name && !strings.HasPrefix(name, cc.Name+".") {
+				continue
+			}
+			if cfg == nil || len(cc.Name) > len(cfg.Name) {
+				cfg = cc
+			}
+		}
+		if cfg == nil {
+			continue
+		}
+		binDir := filepath.Join(c.workDir, bench.name, cfgDir.Name(), "bin")
+		bins, err := os.ReadDir(binDir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		for _, bin := range bins {
+			if !bin.Type().IsRegular() {
+				continue
+			}
+			out, err := cfg.GoTool().VersionM(filepath.Join(binDir, bin.Name()))
+			if err != nil {
+				// Not a Go binary.
+				continue
+			}
+			name := path.Join(archiveBuildInfoDir, bench.name, cfgDir.Name(), bin.Name()+".txt")
+			if err := a.addBytes(name, out); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// environment returns a description of the environment the benchmarks
+// ran in, in the form of Go benchmark format configuration lines.
+func (c *runCmd) environment(configs []*common.Config) ([]byte, error) {
+	var buf bytes.Buffer
+	if c.machine != nil {
+		for _, line := range c.machine.Lines() {
+			fmt.Fprintln(&buf, line)
+		}
+	}
+	fmt.Fprintf(&buf, "sweet-version: %s\n", common.Version)
+	fmt.Fprintf(&buf, "runstamp: %s\n", c.runstamp.In(time.UTC).Format(time.RFC3339Nano))
+	fmt.Fprintf(&buf, "args: %s\n", strings.Join(os.Args[1:], " "))
+	for _, cfg := range configs {
+		ti, err := c.toolchainInfo(cfg)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "toolchain: %s %s %s/%s", cfg.Name, ti.version, ti.goos, ti.goarch)
+		if ti.commit != "" {
+			fmt.Fprintf(&buf, " %s", ti.commit)
+		}
+		fmt.Fprintln(&buf)
+	}
+	return buf.Bytes(), nil
+}
+
+// extractArchive extracts the results archive read from r into dir, and
+// checks its contents against its manifest.
+func extractArchive(r io.Reader, dir string) (*bootstrap.Manifest, error) {
+	// Results archives only ever contain regular files.
+	if err := untar(r, dir, false); err != nil {
+		return nil, err
+	}
+	fsys := os.DirFS(dir)
+	m, err := bootstrap.ReadManifestFS(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.Verify(fsys, ""); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// untar extracts the regular files in the gzipped tar archive read from r
+// into dir, which must not already contain any of them, along with any
+// symbolic links if allowSymlinks is set. Names may have a leading "./",
+// as in archives created with 'tar -C dir .', and symbolic links must
+// point within dir.
+func untar(r io.Reader, dir string, allowSymlinks bool) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	links := make(map[string]bool)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(hdr.Name, "./"), "/")
+		if name == "." || name == "" {
+			continue
+		}
+		if !fs.ValidPath(name) {
+			return fmt.Errorf("archive contains invalid path %q", hdr.Name)
+		}
+		// Links are only checked against where they appear in the
+		// archive, so nothing may be extracted through one.
+		for d := path.Dir(name); d != "."; d = path.Dir(d) {
+			if links[d] {
+				return fmt.Errorf("archive contains %q inside symbolic link %q", hdr.Name, d)
+			}
+		}
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+		case tar.TypeDir:
+			continue
+		case tar.TypeSymlink:
+			if !allowSymlinks {
+				return fmt.Errorf("archive contains symbolic link %q", hdr.Name)
+			}
+			if !linkWithin(name, hdr.Linkname) {
+				return fmt.Errorf("archive contains symbolic link %q pointing outside it", hdr.Name)
+			}
+			if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+				return err
+			}
+			if err := os.Symlink(filepath.FromSlash(hdr.Linkname), p); err != nil {
+				return err
+			}
+			links[name] = true
+			continue
+		default:
+			return fmt.Errorf("archive contains %q, which is not a regular file or directory", hdr.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fs.FileMode(hdr.Mode).Perm())
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(f, tr)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("extracting %s: %w", hdr.Name, err)
+		}
+	}
+}
+
+// linkWithin reports whether the symbolic link called name, with target
+// target, points within the root it's extracted into.
+//
+// Nothing is extracted through a link, so the directories the link is in
+// are real, and any ".." elements at the start of target safely climb
+// them. The rest of target may pass through other links, whose targets
+// are only known to be within the root relative to where they are, so it
+// must only descend.
+func linkWithin(name, target string) bool {
+	if target == "" || path.IsAbs(target) {
+		return false
+	}
+	elems := strings.Split(target, "/")
+	up := 0
+	for up < len(elems) && elems[up] == ".." {
+		up++
+	}
+	for _, e := range elems[up:] {
+		if e == ".." {
+			return false
+		}
+	}
+	depth := 0
+	if dir := path.Dir(name); dir != "." {
+		depth = strings.Count(dir, "/") + 1
+	}
+	return up <= depth
+}
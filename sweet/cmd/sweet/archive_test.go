@@ -0,0 +1,193 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{"a.results": "a", "sub/b.cpu": "b"} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	name := filepath.Join(dir, "out.tar.gz")
+	a, err := createArchive(name, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.addBytes(archiveEnvironment, []byte("cpu: test\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.addDir("results/x", src); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	out := filepath.Join(dir, "out")
+	m, err := extractArchive(f, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{archiveEnvironment, "results/x/a.results", "results/x/sub/b.cpu"} {
+		if _, ok := m.Files[want]; !ok {
+			t.Errorf("manifest missing %s", want)
+		}
+	}
+	b, err := os.ReadFile(filepath.Join(out, "results", "x", "sub", "b.cpu"))
+	if err != nil {
+		t.Fatal(err)
+	} else if string(b) != "b" {
+		t.Errorf("got %q for results/x/sub/b.cpu, want %q", b, "b")
+	}
+}
+
+// writeTarGz writes a gzipped tar archive of hdrs to path, with the
+// contents of each regular file being its name.
+func writeTarGz(t *testing.T, path string, hdrs ...*tar.Header) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, hdr := range hdrs {
+		if hdr.Typeflag == tar.TypeReg {
+			hdr.Size = int64(len(hdr.Name))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(hdr.Name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUntar(t *testing.T) {
+	dir := t.TempDir()
+	// An archive as created by 'tar -C goroot -czf go.tar.gz .'.
+	archive := filepath.Join(dir, "go.tar.gz")
+	writeTarGz(t, archive,
+		&tar.Header{Typeflag: tar.TypeDir, Name: "./", Mode: 0755},
+		&tar.Header{Typeflag: tar.TypeDir, Name: "./bin/", Mode: 0755},
+		&tar.Header{Typeflag: tar.TypeReg, Name: "./bin/go", Mode: 0755},
+		&tar.Header{Typeflag: tar.TypeSymlink, Name: "./bin/gofmt", Linkname: "go"},
+		&tar.Header{Typeflag: tar.TypeSymlink, Name: "./src/link", Linkname: "../bin"},
+	)
+	f, err := os.Open(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	out := filepath.Join(dir, "out")
+	if err := untar(f, out, true); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := os.ReadFile(filepath.Join(out, "bin", "gofmt")); err != nil {
+		t.Error(err)
+	} else if string(b) != "./bin/go" {
+		t.Errorf("got %q through bin/gofmt, want %q", b, "./bin/go")
+	}
+	if goroot, err := findGoroot(out); err != nil {
+		t.Error(err)
+	} else if goroot != out {
+		t.Errorf("findGoroot = %s, want %s", goroot, out)
+	}
+
+	for _, test := range []struct {
+		name string
+		hdrs []*tar.Header
+	}{
+		{"invalid path", []*tar.Header{
+			{Typeflag: tar.TypeReg, Name: "../escape"},
+		}},
+		{"absolute link", []*tar.Header{
+			{Typeflag: tar.TypeSymlink, Name: "link", Linkname: "/etc"},
+		}},
+		{"escaping link", []*tar.Header{
+			{Typeflag: tar.TypeSymlink, Name: "a/link", Linkname: "../.."},
+		}},
+		{"through link", []*tar.Header{
+			{Typeflag: tar.TypeSymlink, Name: "link", Linkname: "."},
+			{Typeflag: tar.TypeSymlink, Name: "link/escape", Linkname: ".."},
+		}},
+		{"chained links", []*tar.Header{
+			{Typeflag: tar.TypeDir, Name: "b/"},
+			// Each of these is within the root where it is, but a
+			// resolves to the root's grandparent through b/c.
+			{Typeflag: tar.TypeSymlink, Name: "b/c", Linkname: ".."},
+			{Typeflag: tar.TypeSymlink, Name: "a", Linkname: "b/c/../.."},
+		}},
+		{"link made later", []*tar.Header{
+			// x/.. is the root until x is a link to somewhere else.
+			{Typeflag: tar.TypeSymlink, Name: "a", Linkname: "x/../y"},
+			{Typeflag: tar.TypeDir, Name: "d/e/"},
+			{Typeflag: tar.TypeSymlink, Name: "x", Linkname: "d/e"},
+		}},
+		{"hard link", []*tar.Header{
+			{Typeflag: tar.TypeLink, Name: "link", Linkname: "file"},
+		}},
+	} {
+		archive := filepath.Join(t.TempDir(), "bad.tar.gz")
+		writeTarGz(t, archive, test.hdrs...)
+		f, err := os.Open(archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := untar(f, filepath.Join(t.TempDir(), "out"), true); err == nil {
+			t.Errorf("%s: untar succeeded, want an error", test.name)
+		}
+		f.Close()
+	}
+}
+
+func TestUntarNoSymlinks(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "results.tar.gz")
+	writeTarGz(t, archive,
+		&tar.Header{Typeflag: tar.TypeReg, Name: "a.results", Mode: 0644},
+		&tar.Header{Typeflag: tar.TypeSymlink, Name: "b.results", Linkname: "a.results"},
+	)
+	f, err := os.Open(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := extractArchive(f, filepath.Join(t.TempDir(), "out")); err == nil || !strings.Contains(err.Error(), "symbolic link") {
+		t.Errorf("extracting a results archive with a symbolic link: got %v, want an error", err)
+	}
+}
@@ -7,13 +7,17 @@ package main
 import (
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
 	"runtime/debug"
+	"strings"
+	"time"
 
 	"golang.org/x/benchmarks/sweet/common"
+	"golang.org/x/benchmarks/sweet/common/diagnostics"
 	"golang.org/x/benchmarks/sweet/common/fileutil"
 	"golang.org/x/benchmarks/sweet/common/log"
 	"golang.org/x/benchmarks/sweet/generators"
@@ -45,12 +49,24 @@ var allBenchmarks = []benchmark{
 		harness:     harnesses.BleveIndex(),
 		generator:   generators.BleveIndex(),
 	},
+	{
+		name:        "build",
+		description: "Builds cmd/go with 'go build -a -p $GOMAXPROCS' (from the legacy suite)",
+		harness:     harnesses.Build{},
+		generator:   generators.None{},
+	},
 	{
 		name:        "etcd",
 		description: "Distributed key-value store",
 		harness:     harnesses.Etcd{},
 		generator:   generators.None{},
 	},
+	{
+		name:        "garbage",
+		description: "Repeatedly parses the net/http package, stressing the GC (from the legacy suite)",
+		harness:     harnesses.Garbage(),
+		generator:   generators.None{},
+	},
 	{
 		name:        "go-build",
 		description: "Go build command",
@@ -69,6 +85,18 @@ var allBenchmarks = []benchmark{
 		harness:     harnesses.GVisor{},
 		generator:   generators.GVisor{},
 	},
+	{
+		name:        "http",
+		description: "Makes requests of a local HTTP server (from the legacy suite)",
+		harness:     harnesses.HTTP(),
+		generator:   generators.None{},
+	},
+	{
+		name:        "json",
+		description: "Marshals and unmarshals a 2MB JSON document (from the legacy suite)",
+		harness:     harnesses.JSON(),
+		generator:   generators.None{},
+	},
 	{
 		name:        "markdown",
 		description: "Renders a corpus of markdown documents to XHTML",
@@ -108,6 +136,14 @@ var benchmarkGroups = func() map[string][]*benchmark {
 		//allBenchmarksMap["tile38"],
 	}
 
+	// The benchmarks ported from the legacy suite in the parent directory.
+	m["legacy"] = []*benchmark{
+		allBenchmarksMap["build"],
+		allBenchmarksMap["garbage"],
+		allBenchmarksMap["http"],
+		allBenchmarksMap["json"],
+	}
+
 	for i := range allBenchmarks {
 		switch allBenchmarks[i].name {
 		case "gvisor":
@@ -128,28 +164,61 @@ func benchmarkNames(b []*benchmark) (s []string) {
 	return
 }
 
+// selectBenchmarks returns the benchmarks named by names, which is either
+// the name of a single benchmark group or a list of benchmark names. If
+// names is empty, the benchmarks in the group called def are returned.
+func selectBenchmarks(names []string, def string) ([]*benchmark, error) {
+	var benchmarks []*benchmark
+	var unknown []string
+	switch len(names) {
+	case 0:
+		benchmarks = benchmarkGroups[def]
+	case 1:
+		if grp, ok := benchmarkGroups[names[0]]; ok {
+			benchmarks = grp
+			break
+		}
+		fallthrough
+	default:
+		for _, name := range names {
+			if benchmark, ok := allBenchmarksMap[name]; ok {
+				benchmarks = append(benchmarks, benchmark)
+			} else {
+				unknown = append(unknown, name)
+			}
+		}
+	}
+	if len(unknown) != 0 {
+		return nil, fmt.Errorf("unknown benchmarks: %s", strings.Join(unknown, ", "))
+	}
+	return benchmarks, nil
+}
+
 func mkdirAll(path string) error {
-	log.CommandPrintf("mkdir -p %s", path)
-	return os.MkdirAll(path, os.ModePerm)
+	return common.Do(fmt.Sprintf("mkdir -p %s", path), func() error {
+		return os.MkdirAll(path, os.ModePerm)
+	})
 }
 
 func copyDirContents(dst, src string) error {
-	log.CommandPrintf("cp -r %s/* %s", src, dst)
-	return fileutil.CopyDir(dst, src, nil)
+	return common.Do(fmt.Sprintf("cp -r %s/* %s", src, dst), func() error {
+		return fileutil.CopyDir(dst, src, nil)
+	})
 }
 
 func rmDirContents(dir string) error {
-	log.CommandPrintf("rm -rf %s/*", dir)
-	fs, err := os.ReadDir(dir)
-	if err != nil {
-		return err
-	}
-	for _, fi := range fs {
-		if err := os.RemoveAll(filepath.Join(dir, fi.Name())); err != nil {
+	return common.Do(fmt.Sprintf("rm -rf %s/*", dir), func() error {
+		fs, err := os.ReadDir(dir)
+		if err != nil {
 			return err
 		}
-	}
-	return nil
+		for _, fi := range fs {
+			if err := os.RemoveAll(filepath.Join(dir, fi.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 type benchmark struct {
@@ -161,6 +230,9 @@ type benchmark struct {
 
 func (b *benchmark) execute(cfgs []*common.Config, r *runCfg) error {
 	log.Printf("Setting up benchmark: %s", b.name)
+	if r.estimate != nil {
+		r.estimate.add(b, r, len(cfgs))
+	}
 
 	// Compute top-level directories for this benchmark to work in.
 	benchDir := filepath.Join(r.benchDir, b.name)
@@ -184,6 +256,9 @@ func (b *benchmark) execute(cfgs []*common.Config, r *runCfg) error {
 		hasAssets = true
 	} else if !errors.Is(err, fs.ErrNotExist) {
 		return err
+	} else if r.manifest != nil && len(r.manifest.Names(assetsFSDir)) != 0 {
+		// The assets were only partially extracted (see 'sweet get -run').
+		return fmt.Errorf("assets for %s are missing; did you leave it out of `sweet get -run`?", b.name)
 	}
 
 	// Retrieve the benchmark's source, if needed. If execute is called
@@ -207,6 +282,8 @@ func (b *benchmark) execute(cfgs []*common.Config, r *runCfg) error {
 
 	// Perform a setup step for each config for the benchmark.
 	setups := make([]common.RunConfig, 0, len(cfgs))
+	buildTimes := make([]time.Duration, 0, len(cfgs))
+	profilesDirs := make([]string, 0, len(cfgs)) // Empty if no diagnostics.
 	for _, pcfg := range cfgs {
 		// Local copy for per-benchmark environment adjustments.
 		cfg := pcfg.Copy()
@@ -231,32 +308,36 @@ func (b *benchmark) execute(cfgs []*common.Config, r *runCfg) error {
 			}
 		}
 
-		// Add PGO if profile specified for this benchmark, otherwise
-		// explicitly disable it to avoid default.pgo files.
-		pgo, ok := cfg.PGOFiles[b.name]
-		if !ok {
-			pgo = "off"
-		}
-		goflags, ok := cfg.BuildEnv.Lookup("GOFLAGS")
-		if ok {
-			goflags += " "
-		}
-		goflags += fmt.Sprintf("-pgo=%s", pgo)
-		cfg.BuildEnv.Env = cfg.BuildEnv.MustSet("GOFLAGS=" + goflags)
-
 		// Build the benchmark (application and any other necessary components).
+		// The harness adds PGO for any binaries with profiles specified for
+		// this benchmark, and explicitly disables it for the rest to avoid
+		// default.pgo files.
 		bcfg := common.BuildConfig{
 			BinDir:   binDir,
 			SrcDir:   srcDir,
 			BenchDir: benchDir,
 			Short:    r.short,
+			PGOFiles: cfg.BenchmarkPGOFiles(b.name),
 		}
-		if err := b.harness.Build(cfg, &bcfg); err != nil {
+		built := false
+		if r.reuseBuilds {
+			bins, err := os.ReadDir(binDir)
+			if err != nil {
+				return fmt.Errorf("reading %s bin for %s: %v", b.name, cfg.Name, err)
+			}
+			built = len(bins) != 0
+		}
+		start := time.Now()
+		if built {
+			log.Printf("Reusing %s binaries for %s", b.name, cfg.Name)
+		} else if err := b.harness.Build(cfg, &bcfg); err != nil {
 			return fmt.Errorf("build %s for %s: %v", b.name, cfg.Name, err)
 		}
+		buildTimes = append(buildTimes, time.Since(start))
 
 		// Generate any args to funnel through to benchmarks.
 		args := []string{}
+		var resultsProfilesDir string
 		if r.dumpCore {
 			// Create a directory for the core files to live in.
 			resultsCoresDir := filepath.Join(resultsDir, "core")
@@ -272,7 +353,7 @@ func (b *benchmark) execute(cfgs []*common.Config, r *runCfg) error {
 		}
 		if !cfg.Diagnostics.Empty() {
 			// Create a directory for any profile files to live in.
-			resultsProfilesDir := r.runProfilesDir(b, cfg)
+			resultsProfilesDir = r.runProfilesDir(b, cfg)
 			mkdirAll(resultsProfilesDir)
 
 			// We need to pass arguments to the benchmark binary to generate
@@ -282,11 +363,19 @@ func (b *benchmark) execute(cfgs []*common.Config, r *runCfg) error {
 			}
 		}
 
-		results, err := os.Create(filepath.Join(resultsDir, fmt.Sprintf("%s.results", cfg.Name)))
+		resultsFile := filepath.Join(resultsDir, fmt.Sprintf("%s.results", cfg.Name))
+		results, err := common.CreateFile(resultsFile)
 		if err != nil {
 			return fmt.Errorf("create %s results file for %s: %v", b.name, cfg.Name, err)
 		}
 		defer results.Close()
+		err = common.Do(fmt.Sprintf("# write the results header to %s", resultsFile), func() error {
+			return r.writeResultsHeader(results, b, cfg)
+		})
+		if err != nil {
+			return fmt.Errorf("write %s results header for %s: %v", b.name, cfg.Name, err)
+		}
+		profilesDirs = append(profilesDirs, resultsProfilesDir)
 		setups = append(setups, common.RunConfig{
 			BinDir:    binDir,
 			TmpDir:    tmpDir,
@@ -297,41 +386,138 @@ func (b *benchmark) execute(cfgs []*common.Config, r *runCfg) error {
 		})
 	}
 
-	for j := 0; j < r.count; j++ {
-		// Execute the benchmark for each configuration.
-		for i, setup := range setups {
-			if hasAssets {
-				// Set up assets directory for test run.
-				r.logCopyDirCommand(b.name, setup.AssetsDir)
-				if err := fileutil.CopyDir(setup.AssetsDir, assetsFSDir, r.assetsFS); err != nil {
-					return err
-				}
-			}
+	// Make sure the assets haven't been corrupted before we copy them
+	// around, so that we can point at the culprit.
+	if hasAssets && r.manifest != nil {
+		err := common.Do(fmt.Sprintf("# verify the assets for %s", b.name), func() error {
+			return r.manifest.Verify(r.assetsFS, assetsFSDir)
+		})
+		if err != nil {
+			return fmt.Errorf("checking assets for %s: %w", b.name, err)
+		}
+	}
 
-			log.Printf("Running benchmark %s for %s: run %d", b.name, cfgs[i].Name, j+1)
-			// Force a GC now because we're about to turn it off.
-			runtime.GC()
-			// Hold your breath: we're turning off GC for the duration of the
-			// run so that the suite's GC doesn't start blasting on all Ps,
-			// introducing undue noise into the experiments.
-			gogc := debug.SetGCPercent(-1)
-			if err := b.harness.Run(cfgs[i], &setup); err != nil {
-				debug.SetGCPercent(gogc)
-				setup.Results.Close()
-				return fmt.Errorf("run benchmark %s for config %s: %v", b.name, cfgs[i].Name, err)
+	// runOnce runs the benchmark for config i, as run j, and returns the
+	// part of the results file it wrote and how long it took.
+	runOnce := func(i, j int) (runSegment, time.Duration, error) {
+		setup := setups[i]
+		if hasAssets {
+			// Set up assets directory for test run.
+			err := common.Do(r.copyDirCommand(b.name, setup.AssetsDir), func() error {
+				return fileutil.CopyDir(setup.AssetsDir, assetsFSDir, r.assetsFS)
+			})
+			if err != nil {
+				return runSegment{}, 0, fmt.Errorf("copying assets for %s: %w", b.name, err)
 			}
+		}
+
+		if profilesDirs[i] != "" {
+			// Pass the run index along so diagnostic data files
+			// can be attributed to this run.
+			setup.Args = append(setup.Args[:len(setup.Args):len(setup.Args)], diagnostics.RunIndexArgs(j)...)
+		}
+
+		start, err := setup.Results.Seek(0, io.SeekEnd)
+		if err != nil {
+			return runSegment{}, 0, err
+		}
+		log.Printf("Running benchmark %s for %s: run %d", b.name, cfgs[i].Name, j+1)
+		// Force a GC now because we're about to turn it off.
+		runtime.GC()
+		// Hold your breath: we're turning off GC for the duration of the
+		// run so that the suite's GC doesn't start blasting on all Ps,
+		// introducing undue noise into the experiments.
+		gogc := debug.SetGCPercent(-1)
+		startTime := time.Now()
+		if err := b.harness.Run(cfgs[i], &setup); err != nil {
 			debug.SetGCPercent(gogc)
+			setup.Results.Close()
+			return runSegment{}, 0, fmt.Errorf("run benchmark %s for config %s: %v", b.name, cfgs[i].Name, err)
+		}
+		elapsed := time.Since(startTime)
+		debug.SetGCPercent(gogc)
+		end, err := setup.Results.Seek(0, io.SeekEnd)
+		if err != nil {
+			return runSegment{}, 0, err
+		}
+
+		// Clean up tmp directory so benchmarks may assume it's empty.
+		if err := rmDirContents(setup.TmpDir); err != nil {
+			return runSegment{}, 0, err
+		}
+		if hasAssets {
+			// Clean up assets directory just in case any of the files were written to.
+			if err := rmDirContents(setup.AssetsDir); err != nil {
+				return runSegment{}, 0, err
+			}
+		}
+		return runSegment{start, end}, elapsed, nil
+	}
 
-			// Clean up tmp directory so benchmarks may assume it's empty.
-			if err := rmDirContents(setup.TmpDir); err != nil {
+	runTimes := make([]time.Duration, len(setups))
+	runCounts := make([]int, len(setups))
+	runs := make([][]runSegment, len(setups))
+	for j := 0; j < r.count; j++ {
+		// Execute the benchmark for each configuration.
+		for i := range setups {
+			seg, elapsed, err := runOnce(i, j)
+			if err != nil {
 				return err
 			}
-			if hasAssets {
-				// Clean up assets directory just in case any of the files were written to.
-				if err := rmDirContents(setup.AssetsDir); err != nil {
-					return err
+			runTimes[i] += elapsed
+			runCounts[i]++
+			runs[i] = append(runs[i], seg)
+		}
+	}
+
+	// Look for unstable results, and rerun any outliers if requested.
+	for i, setup := range setups {
+		i := i
+		rerun := func(j int) (runSegment, error) {
+			// The rerun replaces run j, so drop the diagnostic data
+			// collected during it.
+			if dir := profilesDirs[i]; dir != "" {
+				err := common.Do(fmt.Sprintf("rm %s", filepath.Join(dir, fmt.Sprintf("*.run%d.*", j))), func() error {
+					return diagnostics.RemoveRun(dir, j)
+				})
+				if err != nil {
+					return runSegment{}, fmt.Errorf("removing diagnostic data for run %d: %w", j+1, err)
 				}
 			}
+			seg, elapsed, err := runOnce(i, j)
+			runTimes[i] += elapsed
+			runCounts[i]++
+			return seg, err
+		}
+		if err := r.checkStability(b, cfgs[i], setup.Results, runs[i], rerun); err != nil {
+			return fmt.Errorf("checking stability of %s for %s: %w", b.name, cfgs[i].Name, err)
+		}
+	}
+
+	for i := range setups {
+		if runCounts[i] > 0 {
+			r.record(b, buildTimes[i], runTimes[i]/time.Duration(runCounts[i]))
+		}
+	}
+
+	// Describe the diagnostic data collected for each config.
+	for _, dir := range profilesDirs {
+		if dir == "" {
+			continue
+		}
+		dir := dir
+		err := common.Do(fmt.Sprintf("# describe the diagnostic data in %s", filepath.Join(dir, diagnostics.ManifestName)), func() error {
+			m, err := diagnostics.NewManifest(dir, b.name)
+			if err != nil {
+				return fmt.Errorf("creating diagnostics manifest for %s: %w", b.name, err)
+			}
+			if err := m.Write(dir); err != nil {
+				return fmt.Errorf("writing diagnostics manifest for %s: %w", b.name, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
 	}
 	return nil
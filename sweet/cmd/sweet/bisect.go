@@ -0,0 +1,418 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/benchmarks/stats"
+	"golang.org/x/benchmarks/sweet/cli/bootstrap"
+	"golang.org/x/benchmarks/sweet/common"
+	"golang.org/x/benchmarks/sweet/common/fileutil"
+	"golang.org/x/benchmarks/sweet/common/log"
+	"golang.org/x/benchmarks/sweet/common/machine"
+	"golang.org/x/benchmarks/sweet/common/results"
+)
+
+const (
+	bisectUsage = `Finds the commit in a Go repository that introduced a change in
+the performance of a benchmark.
+
+Each candidate commit is checked out into a git worktree, built with
+make.bash, and used to run the benchmark, interleaved with runs of the
+good and bad commits. The results are compared with those of the good
+and bad commits with a Mann-Whitney U test to decide
+whether the candidate is good or bad. A candidate that differs from both
+is decided by whichever its median is closer to. If the candidate differs
+from neither, it's run again to collect more results, up to -retries times,
+after which it's skipped in favor of a neighboring commit.
+
+The bad commit must be a descendant of the good commit, and the
+performance of the two must differ significantly.
+
+Usage: %s bisect [flags]
+`
+)
+
+type bisectCmd struct {
+	runCfg
+	quiet      bool
+	printCmd   bool
+	gorootRepo string
+	good, bad  string
+	toRun      string
+	sub        string
+	metric     string
+	retries    int
+	alpha      float64
+
+	// measure runs the benchmark with toolchains built at each of revs,
+	// interleaving their runs, and returns the values of the metric for
+	// each. attempt distinguishes multiple measurements of the same
+	// commits.
+	measure   func(b *benchmark, revs []string, attempt int) ([][]float64, error)
+	worktrees []string
+}
+
+func (*bisectCmd) Name() string { return "bisect" }
+func (*bisectCmd) Synopsis() string {
+	return "Finds the Go commit that changed a benchmark's performance."
+}
+func (*bisectCmd) PrintUsage(w io.Writer, base string) {
+	fmt.Fprintf(w, bisectUsage, base)
+}
+
+func (c *bisectCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&c.runCfg.resultsDir, "results", "./bisect-results", "location to write benchmark results to")
+	f.StringVar(&c.runCfg.benchDir, "bench-dir", "./benchmarks", "the benchmarks directory in the sweet source")
+	f.StringVar(&c.runCfg.assetsDir, "assets-dir", "", "a directory containing uncompressed assets for sweet benchmarks, usually for debugging Sweet (overrides -cache)")
+	f.StringVar(&c.runCfg.workDir, "work-dir", "", "work directory for benchmarks and toolchains (default: temporary directory)")
+	f.StringVar(&c.runCfg.assetsCache, "cache", bootstrap.CacheDefault(), "cache location for assets")
+	f.IntVar(&c.runCfg.count, "count", countDefault, "the number of times to run the benchmark for each commit at each attempt")
+	f.BoolVar(&c.short, "short", false, "whether to run a short version of the benchmark for testing")
+
+	f.BoolVar(&c.quiet, "quiet", false, "whether to suppress activity output on stderr (no effect on -shell)")
+	f.BoolVar(&c.printCmd, "shell", false, "whether to print the commands being executed to stdout")
+	f.StringVar(&c.gorootRepo, "goroot-repo", "", "a git checkout of the Go repository to bisect (required)")
+	f.StringVar(&c.good, "good", "", "a revision with good performance (required)")
+	f.StringVar(&c.bad, "bad", "", "a revision with bad performance, descended from -good (required)")
+	f.StringVar(&c.toRun, "run", "", "the benchmark to run (required)")
+	f.StringVar(&c.sub, "sub", "", "the benchmark within -run to compare, as it appears in the results without the Benchmark prefix (required if there's more than one)")
+	f.StringVar(&c.metric, "metric", "ns/op", "the metric to compare")
+	f.IntVar(&c.retries, "retries", 2, "the number of times to rerun a commit whose performance can't be decided, before skipping it")
+	f.Float64Var(&c.alpha, "alpha", 0.05, "the significance level for deciding whether performance differs")
+}
+
+func (c *bisectCmd) Run(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("unexpected arguments")
+	}
+	switch {
+	case c.gorootRepo == "":
+		return fmt.Errorf("-goroot-repo is required")
+	case c.good == "" || c.bad == "":
+		return fmt.Errorf("-good and -bad are required")
+	case c.toRun == "":
+		return fmt.Errorf("-run is required")
+	case c.count < 2:
+		return fmt.Errorf("-count must be at least 2")
+	case c.alpha <= 0 || c.alpha >= 1:
+		return fmt.Errorf("-alpha must be between 0 and 1")
+	}
+	b, ok := allBenchmarksMap[c.toRun]
+	if !ok {
+		return fmt.Errorf("unknown benchmark %q", c.toRun)
+	}
+	checkPlatform()
+
+	log.SetCommandTrace(c.printCmd)
+	log.SetActivityLog(!c.quiet)
+
+	c.runstamp = time.Now()
+	c.toolchains = make(map[string]*toolchainInfo)
+	c.machine = machine.Snapshot()
+	c.reuseBuilds = true
+	c.measure = c.measureRevs
+
+	var err error
+	c.gorootRepo, err = filepath.Abs(c.gorootRepo)
+	if err != nil {
+		return fmt.Errorf("creating absolute path from Go repository path (-goroot-repo): %w", err)
+	}
+	cleanup, err := c.setUp()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	defer c.removeWorktrees()
+	log.Printf("Work directory: %s", c.workDir)
+
+	if err := b.harness.CheckPrerequisites(); err != nil {
+		return fmt.Errorf("failed to meet prerequisites for %s: %v", b.name, err)
+	}
+
+	// Find the candidate commits, from oldest to newest.
+	good, err := c.git("rev-parse", "--verify", c.good+"^{commit}")
+	if err != nil {
+		return err
+	}
+	bad, err := c.git("rev-parse", "--verify", c.bad+"^{commit}")
+	if err != nil {
+		return err
+	}
+	if _, err := c.git("merge-base", "--is-ancestor", good, bad); err != nil {
+		return fmt.Errorf("-good %s is not an ancestor of -bad %s", c.good, c.bad)
+	}
+	revList, err := c.git("rev-list", "--reverse", "--ancestry-path", good+".."+bad)
+	if err != nil {
+		return err
+	}
+	commits := strings.Fields(revList)
+	if len(commits) == 0 {
+		return fmt.Errorf("no commits between -good and -bad")
+	}
+	log.Printf("Bisecting %d commits for %s %s", len(commits), b.name, c.metric)
+
+	// Establish the performance of the good and bad commits, and make
+	// sure they actually differ.
+	var goodS, badS []float64
+	for attempt := 0; ; attempt++ {
+		s, err := c.measure(b, []string{good, bad}, attempt)
+		if err != nil {
+			return fmt.Errorf("measuring good and bad commits: %w", err)
+		}
+		goodS = append(goodS, s[0]...)
+		badS = append(badS, s[1]...)
+		if stats.MannWhitneyU(goodS, badS).P < c.alpha {
+			break
+		}
+		if attempt == c.retries {
+			return fmt.Errorf("no significant difference in %s between -good (median %v) and -bad (median %v)", c.metric, stats.Median(goodS), stats.Median(badS))
+		}
+		log.Printf("No significant difference between good and bad yet; collecting more results")
+	}
+
+	// Narrow down the range of commits (lo, hi] containing the culprit,
+	// where lo is the index of a good commit (-1 for -good) and hi is the
+	// index of a bad commit.
+	lo, hi := -1, len(commits)-1
+	skipped := make(map[int]bool)
+	for hi-lo > 1 {
+		mid := nextCandidate(lo, hi, skipped)
+		if mid < 0 {
+			// Every remaining candidate was skipped.
+			break
+		}
+		log.Printf("Testing %s (%d commits left)", commits[mid], hi-lo-1)
+		switch c.decide(b, good, bad, commits[mid]) {
+		case verdictGood:
+			lo = mid
+		case verdictBad:
+			hi = mid
+		case verdictSkip:
+			log.Printf("Skipping %s", commits[mid])
+			skipped[mid] = true
+		}
+	}
+
+	fmt.Printf("%s %s: good %s median %v, bad %s median %v\n", b.name, c.metric, shortRev(good), stats.Median(goodS), shortRev(bad), stats.Median(badS))
+	if hi-lo == 1 {
+		desc, err := c.git("log", "-1", "--format=%H %s", commits[hi])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("First bad commit: %s\n", desc)
+		return nil
+	}
+	fmt.Println("Could not decide between skipped commits; the first bad commit is one of:")
+	for i := lo + 1; i <= hi; i++ {
+		desc, err := c.git("log", "-1", "--format=%H %s", commits[i])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %s\n", desc)
+	}
+	return nil
+}
+
+// nextCandidate returns the index of the commit to test next in (lo, hi),
+// which is the one closest to the midpoint that hasn't been skipped, or -1
+// if every commit in the range has been skipped.
+func nextCandidate(lo, hi int, skipped map[int]bool) int {
+	mid := lo + (hi-lo)/2
+	for d := 0; mid-d > lo || mid+d < hi; d++ {
+		if i := mid - d; i > lo && !skipped[i] {
+			return i
+		}
+		if i := mid + d; i < hi && !skipped[i] {
+			return i
+		}
+	}
+	return -1
+}
+
+type verdict int
+
+const (
+	verdictGood verdict = iota
+	verdictBad
+	verdictSkip
+)
+
+// decide measures the performance of the commit rev alongside the good
+// and bad commits, and decides whether it's more like the good or the bad
+// commit.
+func (c *bisectCmd) decide(b *benchmark, good, bad, rev string) verdict {
+	var goodS, badS, s []float64
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		more, err := c.measure(b, []string{good, bad, rev}, attempt)
+		if err != nil {
+			// Most likely the toolchain or benchmark doesn't build at
+			// this commit, which more runs won't fix.
+			log.Error(fmt.Errorf("measuring %s: %w", rev, err))
+			return verdictSkip
+		}
+		goodS = append(goodS, more[0]...)
+		badS = append(badS, more[1]...)
+		s = append(s, more[2]...)
+		diffGood := stats.MannWhitneyU(goodS, s).P < c.alpha
+		diffBad := stats.MannWhitneyU(badS, s).P < c.alpha
+		switch {
+		case diffGood && !diffBad:
+			return verdictBad
+		case diffBad && !diffGood:
+			return verdictGood
+		case diffGood && diffBad:
+			// The commit's performance lies somewhere else, for
+			// example in between, if the change happened in more
+			// than one step. Side with whichever it's closest to.
+			m := math.Log(stats.Median(s))
+			if math.Abs(m-math.Log(stats.Median(goodS))) < math.Abs(m-math.Log(stats.Median(badS))) {
+				return verdictGood
+			}
+			return verdictBad
+		}
+		if attempt < c.retries {
+			log.Printf("Inconclusive result for %s (median %v); collecting more results", shortRev(rev), stats.Median(s))
+		}
+	}
+	return verdictSkip
+}
+
+// measureRevs builds a toolchain at each of revs, runs the benchmark with
+// them in a single execution so that their runs are interleaved, and
+// returns the values of the metric for each. The benchmark is built for
+// each commit only once, and reused for later attempts, whose results
+// are kept in a separate directory.
+func (c *bisectCmd) measureRevs(b *benchmark, revs []string, attempt int) ([][]float64, error) {
+	cfgs := make([]*common.Config, 0, len(revs))
+	for _, rev := range revs {
+		goroot, err := c.toolchain(rev)
+		if err != nil {
+			return nil, err
+		}
+		cfgs = append(cfgs, &common.Config{
+			Name:     shortRev(rev),
+			GoRoot:   goroot,
+			BuildEnv: common.ConfigEnv{Env: common.NewEnvFromEnviron()},
+			ExecEnv:  common.ConfigEnv{Env: common.NewEnvFromEnviron()},
+			PGOFiles: make(map[string]string),
+		})
+	}
+	rc := c.runCfg
+	if attempt > 0 {
+		rc.resultsDir = filepath.Join(c.resultsDir, fmt.Sprintf("attempt%d", attempt+1))
+	}
+	if err := b.execute(cfgs, &rc); err != nil {
+		return nil, err
+	}
+	values := make([][]float64, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		v, err := readMetric(filepath.Join(rc.benchmarkResultsDir(b), cfg.Name+".results"), c.sub, c.metric)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// toolchain returns a GOROOT containing a toolchain built at rev, checking
+// it out and building it if necessary.
+func (c *bisectCmd) toolchain(rev string) (string, error) {
+	goroot := filepath.Join(c.workDir, "goroots", shortRev(rev))
+	if ok, err := fileutil.FileExists(filepath.Join(goroot, "bin", "go")); err != nil {
+		return "", err
+	} else if ok {
+		return goroot, nil
+	}
+	if ok, err := fileutil.FileExists(goroot); err != nil {
+		return "", err
+	} else if !ok {
+		if _, err := c.git("worktree", "add", "--detach", goroot, rev); err != nil {
+			return "", err
+		}
+		c.worktrees = append(c.worktrees, goroot)
+	}
+	log.Printf("Building toolchain at %s", shortRev(rev))
+	cmd := exec.Command("./make.bash")
+	cmd.Dir = filepath.Join(goroot, "src")
+	log.TraceCommand(cmd, false)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("building toolchain at %s: %w\n%s", rev, err, out)
+	}
+	return goroot, nil
+}
+
+func (c *bisectCmd) removeWorktrees() {
+	for _, wt := range c.worktrees {
+		if _, err := c.git("worktree", "remove", "--force", wt); err != nil {
+			log.Error(err)
+		}
+	}
+}
+
+// git runs git in the Go repository with args and returns its trimmed
+// output.
+func (c *bisectCmd) git(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", c.gorootRepo}, args...)...)
+	log.TraceCommand(cmd, false)
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) != 0 {
+			return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(ee.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func shortRev(rev string) string {
+	if len(rev) > 12 {
+		return rev[:12]
+	}
+	return rev
+}
+
+// readMetric returns the values of the metric called metric for the
+// benchmark called sub in the results file called path. If sub is
+// empty, the results must contain the metric for just one benchmark.
+func readMetric(path, sub, metric string) ([]float64, error) {
+	rs, err := results.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string][]float64)
+	for _, r := range rs {
+		if v, ok := r.Get(metric); ok {
+			values[r.Name] = append(values[r.Name], v)
+		}
+	}
+	if sub == "" {
+		if len(values) > 1 {
+			var names []string
+			for name := range values {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("%s is reported by multiple benchmarks, use -sub to pick one of: %s", metric, strings.Join(names, ", "))
+		}
+		for name := range values {
+			sub = name
+		}
+	}
+	if len(values[sub]) == 0 {
+		return nil, fmt.Errorf("no %s results found for %s in %s", metric, sub, path)
+	}
+	return values[sub], nil
+}
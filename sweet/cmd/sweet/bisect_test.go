@@ -0,0 +1,150 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNextCandidate(t *testing.T) {
+	for _, test := range []struct {
+		lo, hi  int
+		skipped []int
+		want    int
+	}{
+		{-1, 9, nil, 4},
+		{-1, 1, nil, 0},
+		{4, 9, nil, 6},
+		{4, 9, []int{6}, 5},
+		{4, 9, []int{5, 6}, 7},
+		{4, 7, []int{5, 6}, -1},
+	} {
+		skipped := make(map[int]bool)
+		for _, i := range test.skipped {
+			skipped[i] = true
+		}
+		if got := nextCandidate(test.lo, test.hi, skipped); got != test.want {
+			t.Errorf("nextCandidate(%d, %d, %v) = %d, want %d", test.lo, test.hi, test.skipped, got, test.want)
+		}
+	}
+}
+
+func TestDecide(t *testing.T) {
+	// samples returns n samples spread evenly around m.
+	samples := func(m float64, n int) []float64 {
+		s := make([]float64, n)
+		for i := range s {
+			s[i] = m + float64(i-n/2)
+		}
+		return s
+	}
+	for _, test := range []struct {
+		name string
+		// cand returns the candidate's samples at each attempt.
+		cand     func(attempt int) []float64
+		want     verdict
+		attempts int
+	}{
+		{"good", func(int) []float64 { return samples(100, 10) }, verdictGood, 1},
+		{"bad", func(int) []float64 { return samples(200, 10) }, verdictBad, 1},
+		{"closer to good", func(int) []float64 { return samples(130, 10) }, verdictGood, 1},
+		{"closer to bad", func(int) []float64 { return samples(170, 10) }, verdictBad, 1},
+		{
+			// Too few samples to tell at first, then clearly bad.
+			name: "retry",
+			cand: func(attempt int) []float64 {
+				if attempt == 0 {
+					return []float64{150}
+				}
+				return samples(200, 10)
+			},
+			want:     verdictBad,
+			attempts: 2,
+		},
+		{
+			// Too noisy to distinguish from either.
+			name: "skip",
+			cand: func(int) []float64 {
+				return []float64{95, 205}
+			},
+			want:     verdictSkip,
+			attempts: 3,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c := &bisectCmd{retries: 2, alpha: 0.05}
+			attempts := 0
+			c.measure = func(b *benchmark, revs []string, attempt int) ([][]float64, error) {
+				if want := []string{"good", "bad", "cand"}; !reflect.DeepEqual(revs, want) {
+					t.Fatalf("measured %v, want %v", revs, want)
+				}
+				if attempt != attempts {
+					t.Fatalf("got attempt %d, want %d", attempt, attempts)
+				}
+				attempts++
+				return [][]float64{samples(100, 10), samples(200, 10), test.cand(attempt)}, nil
+			}
+			if got := c.decide(&benchmark{name: "bench"}, "good", "bad", "cand"); got != test.want {
+				t.Errorf("decide = %v, want %v", got, test.want)
+			}
+			if attempts != test.attempts {
+				t.Errorf("measured %d times, want %d", attempts, test.attempts)
+			}
+		})
+	}
+}
+
+func TestDecideError(t *testing.T) {
+	c := &bisectCmd{retries: 2, alpha: 0.05}
+	c.measure = func(b *benchmark, revs []string, attempt int) ([][]float64, error) {
+		return nil, errors.New("build failed")
+	}
+	if got := c.decide(&benchmark{name: "bench"}, "good", "bad", "cand"); got != verdictSkip {
+		t.Errorf("decide = %v, want %v", got, verdictSkip)
+	}
+}
+
+func TestReadMetric(t *testing.T) {
+	const data = `goos: linux
+goarch: amd64
+BenchmarkFoo 1 100 ns/op 10 B/op
+BenchmarkFoo 1 110 ns/op 12 B/op
+BenchmarkBar 1 5 ns/op
+`
+	path := filepath.Join(t.TempDir(), "base.results")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		sub, metric string
+		want        []float64
+		err         string
+	}{
+		{sub: "Foo", metric: "ns/op", want: []float64{100, 110}},
+		{sub: "Bar", metric: "ns/op", want: []float64{5}},
+		{sub: "", metric: "B/op", want: []float64{10, 12}},
+		{sub: "", metric: "ns/op", err: "use -sub to pick one of: Bar, Foo"},
+		{sub: "Baz", metric: "ns/op", err: "no ns/op results found for Baz"},
+		{sub: "Bar", metric: "B/op", err: "no B/op results found for Bar"},
+	} {
+		got, err := readMetric(path, test.sub, test.metric)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("readMetric(%q, %q): got error %v, want %q", test.sub, test.metric, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("readMetric(%q, %q): %v", test.sub, test.metric, err)
+		} else if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("readMetric(%q, %q) = %v, want %v", test.sub, test.metric, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/google/pprof/profile"
+
+	"golang.org/x/benchmarks/sweet/common/diagnostics"
+	"golang.org/x/benchmarks/sweet/common/log"
+	sprofile "golang.org/x/benchmarks/sweet/common/profile"
+	"golang.org/x/benchmarks/sweet/common/results"
+)
+
+const (
+	diffprofUsage = `Compares the profiles collected by 'sweet run' for two configs.
+
+For each benchmark, and each process profiled within it, the profiles
+for each config are merged, normalized, and diffed. The functions and
+inlined call sites with the largest regressions from the base config to
+the experiment config are reported, and the diff is written out as a
+pprof profile, which may be explored further with 'go tool pprof'.
+
+Profiles may be normalized by their total samples, which compares where
+time is spent (or memory is allocated), or by the number of benchmark
+operations they cover according to the results, which compares the cost
+of each operation.
+
+Usage: %s diffprof [flags] <base config> <experiment config>
+`
+)
+
+type diffprofCmd struct {
+	resultsDir string
+	outDir     string
+	typ        string
+	sample     string
+	normalize  string
+	n          int
+	toDiff     csvFlag
+}
+
+func (*diffprofCmd) Name() string { return "diffprof" }
+func (*diffprofCmd) Synopsis() string {
+	return "Compares the profiles collected for two configs."
+}
+func (*diffprofCmd) PrintUsage(w io.Writer, base string) {
+	fmt.Fprintf(w, diffprofUsage, base)
+}
+
+func (c *diffprofCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&c.resultsDir, "results", "./results", "location of benchmark results")
+	f.StringVar(&c.outDir, "o", "", "directory to write diff profiles to (default: each benchmark's results directory)")
+	f.StringVar(&c.typ, "type", string(diagnostics.CPUProfile), "type of profile to compare (options: cpuprofile, memprofile)")
+	f.StringVar(&c.sample, "sample", "", "sample type to compare (default: the last in the profile, as for pprof)")
+	f.StringVar(&c.normalize, "normalize", "samples", "how to normalize profiles (options: samples, ops)")
+	f.IntVar(&c.n, "n", 10, "number of functions and call sites to report")
+	f.Var(&c.toDiff, "run", "benchmark group or comma-separated list of benchmarks to compare")
+}
+
+func (c *diffprofCmd) Run(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected a base config and an experiment config")
+	}
+	log.SetActivityLog(true)
+
+	typ := diagnostics.Type(c.typ)
+	if !typ.IsPprof() {
+		return fmt.Errorf("-type must be a pprof profile type, got %q", c.typ)
+	}
+	if c.normalize != "samples" && c.normalize != "ops" {
+		return fmt.Errorf("unknown normalization %q", c.normalize)
+	}
+	benchmarks, err := selectBenchmarks(c.toDiff, "all")
+	if err != nil {
+		return err
+	}
+	base, exp := args[0], args[1]
+
+	var found bool
+	for _, b := range benchmarks {
+		dir := filepath.Join(c.resultsDir, b.name)
+		baseFiles, err := profilesByProcess(filepath.Join(dir, base+".debug"), b.name, typ)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		expFiles, err := profilesByProcess(filepath.Join(dir, exp+".debug"), b.name, typ)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		procs := make([]string, 0, len(baseFiles))
+		for proc := range baseFiles {
+			if _, ok := expFiles[proc]; ok {
+				procs = append(procs, proc)
+			}
+		}
+		sort.Strings(procs)
+		for _, proc := range procs {
+			if err := c.diff(b, proc, base, exp, baseFiles[proc], expFiles[proc]); err != nil {
+				return fmt.Errorf("comparing %s profiles for %s: %w", typ, b.name, err)
+			}
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("no %s profiles found for both %s and %s in %s", typ, base, exp, c.resultsDir)
+	}
+	return nil
+}
+
+// diff compares the profiles of process proc collected for bench under
+// the configs base and exp.
+func (c *diffprofCmd) diff(b *benchmark, proc, base, exp string, baseFiles, expFiles []profileFile) error {
+	dir := filepath.Join(c.resultsDir, b.name)
+	baseProf, err := mergeProfileFiles(baseFiles)
+	if err != nil {
+		return err
+	}
+	expProf, err := mergeProfileFiles(expFiles)
+	if err != nil {
+		return err
+	}
+	idx, err := sprofile.SampleIndex(expProf, c.sample)
+	if err != nil {
+		return err
+	}
+	var baseNorm, expNorm float64
+	normalize := c.normalize
+	switch normalize {
+	case "samples":
+		baseNorm = float64(sprofile.Total(baseProf, idx))
+		expNorm = float64(sprofile.Total(expProf, idx))
+		if baseNorm == 0 || expNorm == 0 {
+			return fmt.Errorf("no samples to compare; try -normalize=ops")
+		}
+	case "ops":
+		baseNorm, err = countOps(filepath.Join(dir, base+".results"), baseFiles)
+		if err == nil {
+			expNorm, err = countOps(filepath.Join(dir, exp+".results"), expFiles)
+		}
+		if errors.Is(err, errNoOps) {
+			log.Printf("warning: %s: %v; normalizing by samples instead", b.name, err)
+			normalize = "samples"
+			baseNorm = float64(sprofile.Total(baseProf, idx))
+			expNorm = float64(sprofile.Total(expProf, idx))
+			if baseNorm == 0 || expNorm == 0 {
+				return fmt.Errorf("no samples or operations to compare")
+			}
+		} else if err != nil {
+			return err
+		}
+	}
+	diff, err := sprofile.Diff(baseProf, expProf, baseNorm, expNorm)
+	if err != nil {
+		return err
+	}
+
+	outDir := dir
+	if c.outDir != "" {
+		outDir = filepath.Join(c.outDir, b.name)
+		if err := os.MkdirAll(outDir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	name := fmt.Sprintf("%s-vs-%s.%s.%s.pb.gz", base, exp, c.typ, proc)
+	if proc == "" {
+		name = fmt.Sprintf("%s-vs-%s.%s.pb.gz", base, exp, c.typ)
+	}
+	out := filepath.Join(outDir, name)
+	if err := writeProfile(out, diff); err != nil {
+		return err
+	}
+
+	title := b.name
+	if proc != "" {
+		title += " (" + proc + ")"
+	}
+	unit := diff.SampleType[idx].Unit
+	fmt.Printf("%s: %s %s, normalized by %s\n", title, c.typ, diff.SampleType[idx].Type, normalize)
+	printDeltas("Functions", sprofile.FunctionDeltas(diff, idx), c.n, unit)
+	printDeltas("Inlined call sites", sprofile.InlinedCallDeltas(diff, idx), c.n, unit)
+	fmt.Printf("  Diff profile: %s\n\n", out)
+	return nil
+}
+
+// printDeltas prints up to n of the regressions in ds.
+func printDeltas(title string, ds []sprofile.Delta, n int, unit string) {
+	fmt.Printf("  %s with the largest regressions:\n", title)
+	var printed int
+	for _, d := range ds {
+		if printed == n || d.Diff() <= 0 {
+			break
+		}
+		change := "new"
+		if d.Base != 0 {
+			change = fmt.Sprintf("%+.1f%%", 100*float64(d.Diff())/float64(d.Base))
+		}
+		fmt.Printf("    %+d %s (%s) %s\n", d.Diff(), unit, change, d.Name)
+		printed++
+	}
+	if printed == 0 {
+		fmt.Println("    none")
+	}
+}
+
+type profileFile struct {
+	path string
+	diagnostics.DataFile
+}
+
+// profilesByProcess returns the profiles of type typ in the diagnostics
+// directory dir for bench, grouped by the process they were collected from.
+func profilesByProcess(dir, bench string, typ diagnostics.Type) (map[string][]profileFile, error) {
+	m, err := diagnostics.ReadManifest(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Fall back on file names, which the manifest is derived from.
+		m, err = diagnostics.NewManifest(dir, bench)
+	}
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]profileFile)
+	for name, d := range m.Files {
+		if d.Type != typ {
+			continue
+		}
+		files[d.Process] = append(files[d.Process], profileFile{filepath.Join(dir, name), d})
+	}
+	return files, nil
+}
+
+func mergeProfileFiles(files []profileFile) (*profile.Profile, error) {
+	var profiles []*profile.Profile
+	for _, f := range files {
+		if fi, err := os.Stat(f.path); err != nil {
+			return nil, err
+		} else if fi.Size() == 0 {
+			// See sprofile.ReadDirPprof.
+			continue
+		}
+		p, err := sprofile.ReadPprof(f.path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f.path, err)
+		}
+		profiles = append(profiles, p)
+	}
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("all profiles are empty")
+	}
+	return profile.Merge(profiles)
+}
+
+// errNoOps is returned by countOps if no operations were found for the
+// profiled sub-benchmarks.
+var errNoOps = errors.New("no operations found")
+
+// countOps returns the total number of operations reported in the results
+// file called path for the runs of the sub-benchmarks profiled in files.
+//
+// A sub-benchmark without results of its own is attributed the results
+// of the longest benchmark name that's a prefix of its own. For example,
+// the compiler profiles of go-build benchmark GoBuildFoo are recorded for
+// GoBuildFooCompile, which is measured as part of GoBuildFoo.
+func countOps(path string, files []profileFile) (float64, error) {
+	rs, err := results.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	names := make(map[string]bool)
+	for _, r := range rs {
+		names[r.Name] = true
+	}
+	subs := make(map[string]bool)
+	for _, f := range files {
+		for name := f.SubBenchmark; name != ""; name = name[:len(name)-1] {
+			if names[name] {
+				subs[name] = true
+				break
+			}
+		}
+	}
+	var ops float64
+	for _, r := range rs {
+		if subs[r.Name] {
+			ops += float64(r.Iters)
+		}
+	}
+	if ops == 0 {
+		return 0, fmt.Errorf("%w in %s", errNoOps, path)
+	}
+	return ops, nil
+}
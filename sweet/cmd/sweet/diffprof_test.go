@@ -0,0 +1,59 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/benchmarks/sweet/common/diagnostics"
+)
+
+func TestCountOps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "base.results")
+	err := os.WriteFile(path, []byte(`goos: linux
+BenchmarkGoBuildKubelet 1 5000000000 ns/op
+BenchmarkGoBuildKubeletLink 1 800000000 ns/op
+BenchmarkGoBuildKubelet 1 5100000000 ns/op
+BenchmarkGoBuildKubeletLink 1 810000000 ns/op
+BenchmarkTile38QueryLoad 2000 100000 ns/op
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := func(subs ...string) []profileFile {
+		var fs []profileFile
+		for _, sub := range subs {
+			fs = append(fs, profileFile{DataFile: diagnostics.DataFile{SubBenchmark: sub}})
+		}
+		return fs
+	}
+	for _, test := range []struct {
+		name string
+		subs []string
+		want float64
+	}{
+		{"Exact", []string{"Tile38QueryLoad"}, 2000},
+		{"Link", []string{"GoBuildKubeletLink", "GoBuildKubeletLink"}, 2},
+		// Compiler profiles have no results of their own, and are
+		// attributed to the build as a whole.
+		{"Compile", []string{"GoBuildKubeletCompile"}, 2},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := countOps(path, files(test.subs...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("countOps(%q) = %v, want %v", test.subs, got, test.want)
+			}
+		})
+	}
+	if _, err := countOps(path, files("Markdown")); !errors.Is(err, errNoOps) {
+		t.Errorf("expected errNoOps for a benchmark without results, got %v", err)
+	}
+}
@@ -0,0 +1,323 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/benchmarks/sweet/common/log"
+	"golang.org/x/benchmarks/sweet/common/results"
+)
+
+const (
+	exportUsage = `Exports benchmark results for ingestion into time-series monitoring.
+
+Each value in the results becomes a sample of a gauge named after its
+unit (for example, ns/op becomes sweet_ns_per_op), labeled with the
+benchmark, the sub-benchmark name, the config, the index of the run, and
+the configuration lines at the top of the results file, which describe
+the toolchain and the machine. If the results have a runstamp, it's used
+as the timestamp of each sample.
+
+Each argument is a results directory or an archive produced by
+'sweet run -archive'. By default, ./results is exported.
+
+Usage: %s export [flags] [results...]
+`
+)
+
+// Formats for exported results.
+const (
+	formatOpenMetrics = "openmetrics"
+	formatPrometheus  = "prometheus"
+)
+
+// labelsFlag is a set of labels, as key=value.
+type labelsFlag map[string]string
+
+func (l labelsFlag) String() string {
+	var s []string
+	for k, v := range l {
+		s = append(s, k+"="+v)
+	}
+	sort.Strings(s)
+	return strings.Join(s, ",")
+}
+
+func (l labelsFlag) Set(input string) error {
+	k, v, ok := strings.Cut(input, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("expected <key>=<value>")
+	}
+	l[k] = v
+	return nil
+}
+
+type exportCmd struct {
+	format     string
+	out        string
+	timestamps bool
+	labels     labelsFlag
+}
+
+func (*exportCmd) Name() string { return "export" }
+func (*exportCmd) Synopsis() string {
+	return "Exports results as OpenMetrics or Prometheus text."
+}
+func (*exportCmd) PrintUsage(w io.Writer, base string) {
+	fmt.Fprintf(w, exportUsage, base)
+}
+
+func (c *exportCmd) SetFlags(f *flag.FlagSet) {
+	c.labels = make(labelsFlag)
+	f.StringVar(&c.format, "format", formatOpenMetrics, "output format (options: openmetrics, prometheus)")
+	f.StringVar(&c.out, "o", "", "file to write to (default: stdout)")
+	f.BoolVar(&c.timestamps, "timestamps", true, "whether to timestamp samples with the runstamp of their results")
+	f.Var(c.labels, "label", "add a label to every sample as <key>=<value> (may be repeated)")
+}
+
+func (c *exportCmd) Run(args []string) error {
+	log.SetActivityLog(true)
+	if c.format != formatOpenMetrics && c.format != formatPrometheus {
+		return fmt.Errorf("unknown format %q", c.format)
+	}
+	if len(args) == 0 {
+		args = []string{"./results"}
+	}
+	var files []results.File
+	for _, arg := range args {
+		fs, err := readResults(arg)
+		if err != nil {
+			return err
+		}
+		files = append(files, fs...)
+	}
+	e := &exporter{format: c.format, timestamps: c.timestamps, labels: c.labels}
+	if c.out == "" {
+		return e.write(os.Stdout, files)
+	}
+	return e.writeFile(c.out, files)
+}
+
+// readResults reads the results in path, which is either a results
+// directory or an archive produced by 'sweet run -archive'.
+func readResults(path string) ([]results.File, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return results.ReadDir(path)
+	}
+	dir, err := os.MkdirTemp("", "gosweet-results")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if _, err := extractArchive(f, dir); err != nil {
+		return nil, fmt.Errorf("extracting %s: %w", path, err)
+	}
+	return results.ReadDir(filepath.Join(dir, archiveResultsDir))
+}
+
+// exportResults writes the results of benchmarks to the file named by
+// -export, in OpenMetrics format.
+func (c *runCmd) exportResults(benchmarks []*benchmark) error {
+	all, err := results.ReadDir(c.resultsDir)
+	if err != nil {
+		return err
+	}
+	run := make(map[string]bool)
+	for _, b := range benchmarks {
+		run[b.name] = true
+	}
+	var files []results.File
+	for _, f := range all {
+		if run[f.Benchmark] {
+			files = append(files, f)
+		}
+	}
+	log.Printf("Exporting results to %s", c.export)
+	e := &exporter{format: formatOpenMetrics, timestamps: true}
+	return e.writeFile(c.export, files)
+}
+
+// exporter writes results as OpenMetrics or Prometheus text.
+type exporter struct {
+	format     string
+	timestamps bool
+	labels     map[string]string // Extra labels for every sample.
+}
+
+// sample is a single exported value.
+type sample struct {
+	labels    string // Formatted.
+	value     float64
+	timestamp time.Time
+}
+
+// writeFile writes files to the file called name, replacing it atomically
+// so that collectors never see a partial file.
+func (e *exporter) writeFile(name string, files []results.File) error {
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	err = e.write(f, files)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+func (e *exporter) write(w io.Writer, files []results.File) error {
+	// Group samples into metric families, which must be contiguous.
+	families := make(map[string][]sample)
+	for _, file := range files {
+		for _, r := range file.Results {
+			labels := e.formatLabels(file, &r)
+			var ts time.Time
+			if e.timestamps {
+				ts, _ = time.Parse(time.RFC3339Nano, r.Config["runstamp"])
+			}
+			for _, v := range r.Values {
+				name := metricName(v.Unit)
+				families[name] = append(families[name], sample{labels, v.Value, ts})
+			}
+		}
+	}
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		// Each series' samples must be contiguous and in order.
+		samples := families[name]
+		sort.SliceStable(samples, func(i, j int) bool {
+			if samples[i].labels != samples[j].labels {
+				return samples[i].labels < samples[j].labels
+			}
+			return samples[i].timestamp.Before(samples[j].timestamp)
+		})
+		fmt.Fprintf(bw, "# TYPE %s gauge\n", name)
+		for _, s := range samples {
+			fmt.Fprintf(bw, "%s{%s} %s", name, s.labels, formatFloat(s.value))
+			if !s.timestamp.IsZero() {
+				if e.format == formatOpenMetrics {
+					fmt.Fprintf(bw, " %s", formatFloat(float64(s.timestamp.UnixNano())/1e9))
+				} else {
+					fmt.Fprintf(bw, " %d", s.timestamp.UnixNano()/1e6)
+				}
+			}
+			fmt.Fprintln(bw)
+		}
+	}
+	if e.format == formatOpenMetrics {
+		fmt.Fprintln(bw, "# EOF")
+	}
+	return bw.Flush()
+}
+
+// formatLabels returns the labels for the values in r, from file.
+func (e *exporter) formatLabels(file results.File, r *results.Result) string {
+	labels := map[string]string{
+		"benchmark": file.Benchmark,
+		"name":      r.Name,
+		"config":    file.Config,
+		"run":       strconv.Itoa(r.Run),
+	}
+	for k, v := range r.Config {
+		if k == "runstamp" && e.timestamps {
+			// Used as the timestamp instead.
+			continue
+		}
+		k = labelName(k)
+		if _, ok := labels[k]; !ok {
+			labels[k] = v
+		}
+	}
+	for k, v := range e.labels {
+		labels[labelName(k)] = v
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", k, escapeLabelValue(labels[k]))
+	}
+	return b.String()
+}
+
+// metricName returns the name of the metric for values in unit, such as
+// sweet_ns_per_op for ns/op.
+func metricName(unit string) string {
+	return "sweet_" + sanitizeName(strings.ReplaceAll(unit, "/", "_per_"))
+}
+
+// labelName returns key as a valid label name.
+func labelName(key string) string {
+	return sanitizeName(key)
+}
+
+// sanitizeName returns s in lower case with any characters that aren't
+// valid in metric or label names replaced by underscores.
+func sanitizeName(s string) string {
+	b := []byte(strings.ToLower(s))
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c == '_' || c >= '0' && c <= '9' && i > 0) {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
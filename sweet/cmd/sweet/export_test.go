@@ -0,0 +1,57 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/benchmarks/sweet/common/results"
+)
+
+func TestExport(t *testing.T) {
+	const text = `goos: linux
+go-version: go1.22 "x"
+runstamp: 2024-01-02T03:04:05Z
+BenchmarkFoo 1 100 ns/op 2048 peak-RSS-bytes
+BenchmarkFoo 1 200 ns/op 4096 peak-RSS-bytes
+`
+	rs, err := results.Parse(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []results.File{{Benchmark: "foo", Config: "base", Results: rs}}
+
+	const labels = `benchmark="foo",config="base",go_version="go1.22 \"x\"",goos="linux",host="lab1",name="Foo"`
+	for _, test := range []struct {
+		format string
+		want   string
+	}{
+		{formatOpenMetrics, `# TYPE sweet_ns_per_op gauge
+sweet_ns_per_op{` + labels + `,run="0"} 100 1.704164645e+09
+sweet_ns_per_op{` + labels + `,run="1"} 200 1.704164645e+09
+# TYPE sweet_peak_rss_bytes gauge
+sweet_peak_rss_bytes{` + labels + `,run="0"} 2048 1.704164645e+09
+sweet_peak_rss_bytes{` + labels + `,run="1"} 4096 1.704164645e+09
+# EOF
+`},
+		{formatPrometheus, `# TYPE sweet_ns_per_op gauge
+sweet_ns_per_op{` + labels + `,run="0"} 100 1704164645000
+sweet_ns_per_op{` + labels + `,run="1"} 200 1704164645000
+# TYPE sweet_peak_rss_bytes gauge
+sweet_peak_rss_bytes{` + labels + `,run="0"} 2048 1704164645000
+sweet_peak_rss_bytes{` + labels + `,run="1"} 4096 1704164645000
+`},
+	} {
+		e := &exporter{format: test.format, timestamps: true, labels: map[string]string{"host": "lab1"}}
+		var b strings.Builder
+		if err := e.write(&b, files); err != nil {
+			t.Fatal(err)
+		}
+		if got := b.String(); got != test.want {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", test.format, got, test.want)
+		}
+	}
+}
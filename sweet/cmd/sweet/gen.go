@@ -5,17 +5,21 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 	"unicode/utf8"
 
+	"golang.org/x/benchmarks/sweet/cli/bootstrap"
 	"golang.org/x/benchmarks/sweet/common"
 	"golang.org/x/benchmarks/sweet/common/log"
+	"golang.org/x/benchmarks/sweet/generators"
 )
 
 const (
@@ -32,8 +36,9 @@ type genCfg struct {
 
 type genCmd struct {
 	genCfg
-	quiet bool
-	toGen csvFlag
+	quiet  bool
+	verify bool
+	toGen  csvFlag
 }
 
 func (*genCmd) Name() string     { return "gen" }
@@ -87,6 +92,7 @@ func (c *genCmd) SetFlags(f *flag.FlagSet) {
 	f.StringVar(&c.genCfg.outputDir, "output-dir", "./assets", "the directory into which new assets should be generated")
 
 	f.BoolVar(&c.quiet, "quiet", false, "whether to suppress activity output on stderr (no effect on -shell)")
+	f.BoolVar(&c.verify, "verify", false, "regenerate assets into a scratch directory and check them against the existing assets instead of writing to -output-dir")
 	f.Var(&c.toGen, "gen", "benchmark group or comma-separated list of benchmarks to gen (default: all)")
 }
 
@@ -123,28 +129,9 @@ func (c *genCmd) Run(args []string) error {
 	}
 
 	// Decide which benchmarks to gen assets for, based on the -gen flag.
-	var benchmarks []*benchmark
-	var unknown []string
-	switch len(c.toGen) {
-	case 0:
-		benchmarks = benchmarkGroups["all"]
-	case 1:
-		if grp, ok := benchmarkGroups[c.toGen[0]]; ok {
-			benchmarks = grp
-			break
-		}
-		fallthrough
-	default:
-		for _, name := range c.toGen {
-			if benchmark, ok := allBenchmarksMap[name]; ok {
-				benchmarks = append(benchmarks, benchmark)
-			} else {
-				unknown = append(unknown, name)
-			}
-		}
-	}
-	if len(unknown) != 0 {
-		return fmt.Errorf("unknown benchmarks: %s", strings.Join(unknown, ", "))
+	benchmarks, err := selectBenchmarks(c.toGen, "all")
+	if err != nil {
+		return err
 	}
 
 	// Find the go tool.
@@ -154,10 +141,25 @@ func (c *genCmd) Run(args []string) error {
 	}
 	log.Printf("Using Go: %s", goTool.Tool)
 
+	// When verifying, generate into a scratch directory instead.
+	outputRoot := c.outputDir
+	if c.verify {
+		outputRoot, err = os.MkdirTemp("", "sweet-gen-verify")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(outputRoot)
+	}
+
 	// Execute each generator.
+	var mismatched []string
 	for _, b := range benchmarks {
+		if _, ok := b.generator.(generators.None); ok && c.verify {
+			// There's nothing to regenerate, so there's nothing to verify.
+			continue
+		}
 		log.Printf("Generating assets: %s", b.name)
-		outputDir := filepath.Join(c.outputDir, b.name)
+		outputDir := filepath.Join(outputRoot, b.name)
 		if err := mkdirAll(outputDir); err != nil {
 			return err
 		}
@@ -174,6 +176,80 @@ func (c *genCmd) Run(args []string) error {
 		if err := b.generator.Generate(&cfg); err != nil {
 			return err
 		}
+		if c.verify {
+			ok, err := verifyAssets(b, cfg.AssetsDir, outputDir)
+			if err != nil {
+				return fmt.Errorf("verifying assets for %s: %w", b.name, err)
+			}
+			if !ok {
+				mismatched = append(mismatched, b.name)
+			}
+		}
+	}
+	if len(mismatched) != 0 {
+		return fmt.Errorf("regenerated assets do not match for: %s", strings.Join(mismatched, ", "))
 	}
 	return nil
 }
+
+// verifyAssets compares the assets generated by b in newDir against
+// those in oldDir, logging each difference. Files that differ are
+// compared semantically if b's generator is a common.AssetComparer.
+// A missing directory is treated as containing no assets.
+// Returns whether the assets match.
+func verifyAssets(b *benchmark, oldDir, newDir string) (bool, error) {
+	oldFiles, err := dirManifest(oldDir)
+	if err != nil {
+		return false, err
+	}
+	newFiles, err := dirManifest(newDir)
+	if err != nil {
+		return false, err
+	}
+	comparer, _ := b.generator.(common.AssetComparer)
+
+	ok := true
+	for _, name := range oldFiles.Names("") {
+		if _, found := newFiles.Files[name]; !found {
+			log.Printf("%s: %s: missing from regenerated assets", b.name, name)
+			ok = false
+		}
+	}
+	for _, name := range newFiles.Names("") {
+		oldHash, found := oldFiles.Files[name]
+		switch {
+		case !found:
+			log.Printf("%s: %s: not in existing assets", b.name, name)
+			ok = false
+		case oldHash == newFiles.Files[name]:
+			// Identical.
+		case comparer == nil:
+			log.Printf("%s: %s: differs", b.name, name)
+			ok = false
+		default:
+			err := comparer.CompareAsset(name, filepath.Join(oldDir, filepath.FromSlash(name)), filepath.Join(newDir, filepath.FromSlash(name)))
+			if errors.Is(err, common.ErrNotComparable) {
+				log.Printf("%s: %s: differs", b.name, name)
+				ok = false
+			} else if err != nil {
+				log.Printf("%s: %s: differs: %v", b.name, name, err)
+				ok = false
+			} else {
+				log.Printf("%s: %s: differs, but is equivalent", b.name, name)
+			}
+		}
+	}
+	if ok {
+		log.Printf("%s: regenerated assets match", b.name)
+	}
+	return ok, nil
+}
+
+// dirManifest returns a manifest of the files in dir, or an empty
+// manifest if dir does not exist.
+func dirManifest(dir string) (*bootstrap.Manifest, error) {
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		return bootstrap.NewManifest(), nil
+	}
+	return bootstrap.NewManifestFS(os.DirFS(dir))
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/benchmarks/sweet/generators"
+)
+
+func TestVerifyAssets(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		old, new map[string]string // nil means the directory doesn't exist
+		ok       bool
+	}{
+		{
+			name: "identical",
+			old:  map[string]string{"a.txt": "a", "sub/b.txt": "b"},
+			new:  map[string]string{"a.txt": "a", "sub/b.txt": "b"},
+			ok:   true,
+		},
+		{
+			name: "differing",
+			old:  map[string]string{"a.txt": "a"},
+			new:  map[string]string{"a.txt": "A"},
+			ok:   false,
+		},
+		{
+			name: "missing",
+			old:  map[string]string{"a.txt": "a", "b.txt": "b"},
+			new:  map[string]string{"a.txt": "a"},
+			ok:   false,
+		},
+		{
+			name: "extra",
+			old:  map[string]string{"a.txt": "a"},
+			new:  map[string]string{"a.txt": "a", "b.txt": "b"},
+			ok:   false,
+		},
+		{
+			name: "no assets",
+			ok:   true,
+		},
+		{
+			name: "no existing assets",
+			new:  map[string]string{"a.txt": "a"},
+			ok:   false,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			oldDir := writeAssets(t, filepath.Join(dir, "old"), test.old)
+			newDir := writeAssets(t, filepath.Join(dir, "new"), test.new)
+			b := &benchmark{name: "bench", generator: generators.None{}}
+			ok, err := verifyAssets(b, oldDir, newDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != test.ok {
+				t.Errorf("verifyAssets = %v, want %v", ok, test.ok)
+			}
+		})
+	}
+}
+
+// writeAssets writes files into dir, which is created only if files
+// is non-nil, and returns dir.
+func writeAssets(t *testing.T, dir string, files map[string]string) string {
+	t.Helper()
+	if files == nil {
+		return dir
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
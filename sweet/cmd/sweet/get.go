@@ -6,9 +6,12 @@ package main
 
 import (
 	"archive/zip"
+	"bytes"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -19,7 +22,8 @@ import (
 )
 
 const (
-	getUsage = `Retrieves assets for benchmarks from GCS.
+	getUsage = `Retrieves assets for benchmarks from GCS, an HTTP(S) mirror, or a local
+directory or archive (see -source).
 
 Usage: %s get [flags]
 `
@@ -42,7 +46,9 @@ type getCmd struct {
 	force          bool
 	cache          string
 	bucket         string
+	source         string
 	copyDir        string
+	toGet          csvFlag
 	assetsHashFile string
 	version        string
 }
@@ -58,8 +64,10 @@ func (c *getCmd) SetFlags(f *flag.FlagSet) {
 	f.BoolVar(&c.force, "force", false, "force download even if assets for this version exist in the cache")
 	f.StringVar(&c.cache, "cache", bootstrap.CacheDefault(), "cache location for assets")
 	f.StringVar(&c.version, "version", common.Version, "the version to download assets for")
-	f.StringVar(&c.bucket, "bucket", "go-sweet-assets", "GCS bucket to download assets from")
+	f.StringVar(&c.bucket, "bucket", "go-sweet-assets", "GCS bucket to download assets from (ignored if -source is set)")
+	f.StringVar(&c.source, "source", "", "where to download assets from: gs://<bucket>, http(s)://<mirror>, or a local directory or archive (default: gs://<-bucket>)")
 	f.StringVar(&c.copyDir, "copy", "", "location to extract assets into, useful for development")
+	f.Var(&c.toGet, "run", "comma-separated list of benchmarks (or a benchmark group) to extract assets for (requires -copy; default: all)")
 	f.StringVar(&c.assetsHashFile, "assets-hash-file", "./assets.hash", "file to check SHA256 hash of the downloaded artifact against")
 }
 
@@ -68,10 +76,66 @@ func (c *getCmd) Run(_ []string) error {
 	if err := bootstrap.ValidateVersion(c.version); err != nil {
 		return err
 	}
+	if len(c.toGet) != 0 && c.copyDir == "" {
+		return fmt.Errorf("-run requires -copy")
+	}
 	if c.copyDir == "" && c.cache == "" {
 		log.Printf("No cache to populate and assets are not copied. Nothing to do.")
 		return nil
 	}
+	var src bootstrap.Source = &bootstrap.GCSSource{Bucket: c.bucket, Auth: c.auth}
+	if c.source != "" {
+		var err error
+		src, err = bootstrap.ParseSource(c.source, c.auth)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Figure out which asset directories to extract, if not all of them.
+	var dirs []string
+	if len(c.toGet) != 0 {
+		benchmarks, err := selectBenchmarks(c.toGet, "")
+		if err != nil {
+			return err
+		}
+		dirs = benchmarkNames(benchmarks)
+	}
+
+	// Check to make sure out destination is clear.
+	if c.copyDir != "" {
+		if _, err := os.Stat(c.copyDir); err == nil {
+			return fmt.Errorf("installing assets: %s exists; to copy assets here, remove it and re-run this command", c.copyDir)
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("stat %s: %v", c.copyDir, err)
+		}
+	}
+
+	// If we only need some of the assets, they aren't already in the cache,
+	// and the source supports it, fetch just those files out of the archive.
+	// The cache is left alone in this case, since we don't have the
+	// whole archive.
+	if rs, ok := src.(bootstrap.RandomAccessSource); ok && dirs != nil {
+		inCache := false
+		if c.cache != "" && !c.force {
+			_, err := bootstrap.CachedAssets(c.cache, c.version)
+			if err != nil && err != bootstrap.ErrNotInCache {
+				return err
+			}
+			inCache = err == nil
+		}
+		vals, err := bootstrap.ReadHashesFile(c.assetsHashFile)
+		if err != nil {
+			return err
+		}
+		manifestHash, ok := vals.GetManifest(c.version)
+		if ok && !inCache {
+			return c.getSelective(rs, dirs, manifestHash)
+		}
+		if !ok {
+			log.Printf("No manifest hash for version %s in %s, so its assets can't be retrieved selectively; downloading the whole archive", c.version, c.assetsHashFile)
+		}
+	}
 
 	// Create a file that we'll download assets into.
 	var (
@@ -87,6 +151,7 @@ func (c *getCmd) Run(_ []string) error {
 		if err != nil {
 			return err
 		}
+		defer os.Remove(f.Name())
 		defer f.Close()
 		fName = f.Name()
 	} else {
@@ -109,7 +174,7 @@ func (c *getCmd) Run(_ []string) error {
 	// Otherwise they're in a cache.
 	if f != nil {
 		// Download the compressed assets into f.
-		if err := downloadAssets(f, c.bucket, c.assetsHashFile, c.version, c.auth); err != nil {
+		if err := downloadAssets(f, src, c.assetsHashFile, c.version); err != nil {
 			return err
 		}
 	}
@@ -119,30 +184,61 @@ func (c *getCmd) Run(_ []string) error {
 	}
 	if f == nil {
 		// Since f is nil, and we'll be extracting, we need to open the file.
-		f, err := os.Open(fName)
+		f, err = os.Open(fName)
 		if err != nil {
 			return err
 		}
 		defer f.Close()
 	}
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(f, fi.Size())
+	if err != nil {
+		return err
+	}
 
-	// Check to make sure out destination is clear.
-	if _, err := os.Stat(c.copyDir); err == nil {
-		return fmt.Errorf("installing assets: %s exists; to copy assets here, remove it and re-run this command", c.copyDir)
-	} else if !os.IsNotExist(err) {
-		return fmt.Errorf("stat %s: %v", c.copyDir, err)
+	// The archive as a whole was checked against the hashes file when
+	// it was downloaded, so its manifest may be trusted as-is.
+	m, err := readArchiveManifest(zr, "")
+	if err != nil {
+		return err
 	}
 
 	// Extract assets into assetsDir.
 	log.Printf("Copying assets to %s", c.copyDir)
-	return extractAssets(f, c.copyDir)
+	return extractAssets(zr, c.copyDir, dirs, m)
 }
 
-func downloadAssets(toFile *os.File, bucket, hashfile, version string, auth bootstrap.AuthOption) error {
-	log.Printf("Downloading assets archive for version %s to %s", version, toFile.Name())
+// getSelective extracts only the assets in dirs from the archive in src
+// into c.copyDir, without downloading the rest of the archive. Each file
+// is checked against the archive's manifest, which must match manifestHash.
+func (c *getCmd) getSelective(src bootstrap.RandomAccessSource, dirs []string, manifestHash string) error {
+	log.Printf("Reading assets archive for version %s from %s", c.version, src)
+	ra, size, err := src.OpenReaderAt(c.version)
+	if err != nil {
+		return err
+	}
+	defer ra.Close()
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return err
+	}
+	m, err := readArchiveManifest(zr, manifestHash)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Copying assets for %s to %s", strings.Join(dirs, ", "), c.copyDir)
+	return extractAssets(zr, c.copyDir, dirs, m)
+}
 
-	// Create storage reader for streaming.
-	rc, err := bootstrap.NewStorageReader(bucket, version, auth)
+func downloadAssets(toFile *os.File, src bootstrap.Source, hashfile, version string) error {
+	log.Printf("Downloading assets archive for version %s from %s to %s", version, src, toFile.Name())
+
+	// Create reader for streaming.
+	rc, err := src.Open(version)
 	if err != nil {
 		return err
 	}
@@ -171,26 +267,74 @@ func checkAssetsHash(hash, hashfile, version string) error {
 		return fmt.Errorf("hash for version %s not found", version)
 	}
 	if hash != check {
-		return fmt.Errorf("downloaded artifact has unexpected hash: expected %s, got %s", hash, check)
+		return fmt.Errorf("downloaded artifact has unexpected hash: expected %s, got %s", check, hash)
 	}
 	return nil
 }
 
-func extractAssets(archive *os.File, outdir string) error {
-	if err := os.MkdirAll(outdir, os.ModePerm); err != nil {
-		return fmt.Errorf("create assets directory: %w", err)
+// readArchiveManifest reads the manifest from the assets archive zr.
+// If hash is not empty, the manifest must match it.
+//
+// Returns a nil Manifest and no error if the archive has no manifest
+// and hash is empty, as is the case for older archives.
+func readArchiveManifest(zr *zip.Reader, hash string) (*bootstrap.Manifest, error) {
+	zf, err := zr.Open(bootstrap.ManifestName)
+	if errors.Is(err, fs.ErrNotExist) && hash == "" {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading assets manifest: %w", err)
 	}
-	archiveInfo, err := archive.Stat()
+	defer zf.Close()
+	b, err := io.ReadAll(zf)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("reading assets manifest: %w", err)
 	}
-	zr, err := zip.NewReader(archive, archiveInfo.Size())
-	if err != nil {
-		return err
+	if hash != "" {
+		h := bootstrap.Hash()
+		h.Write(b)
+		if got := bootstrap.CanonicalizeHash(h); got != hash {
+			return nil, fmt.Errorf("assets manifest has unexpected hash: expected %s, got %s", hash, got)
+		}
+	}
+	return bootstrap.ReadManifest(bytes.NewReader(b))
+}
+
+// extractAssets extracts the assets in zr into outdir.
+//
+// If dirs is non-nil, only the assets under those top-level directories
+// are extracted, along with the manifest. If m is non-nil, each extracted
+// file is checked against it.
+func extractAssets(zr *zip.Reader, outdir string, dirs []string, m *bootstrap.Manifest) error {
+	if err := os.MkdirAll(outdir, os.ModePerm); err != nil {
+		return fmt.Errorf("create assets directory: %w", err)
+	}
+	want := func(name string) bool {
+		if dirs == nil || name == bootstrap.ManifestName {
+			return true
+		}
+		for _, dir := range dirs {
+			if strings.HasPrefix(name, dir+"/") {
+				return true
+			}
+		}
+		return false
 	}
 	for _, zf := range zr.File {
+		if !fs.ValidPath(strings.TrimSuffix(zf.Name, "/")) {
+			return fmt.Errorf("archive contains invalid path %q", zf.Name)
+		}
+		if !want(zf.Name) {
+			continue
+		}
+		if strings.HasSuffix(zf.Name, "/") {
+			// Directory entry.
+			if err := os.MkdirAll(filepath.Join(outdir, filepath.FromSlash(zf.Name)), os.ModePerm); err != nil {
+				return err
+			}
+			continue
+		}
 		err := func(zf *zip.File) error {
-			fullpath := filepath.Join(outdir, zf.Name)
+			fullpath := filepath.Join(outdir, filepath.FromSlash(zf.Name))
 			if err := os.MkdirAll(filepath.Dir(fullpath), os.ModePerm); err != nil {
 				return err
 			}
@@ -199,18 +343,30 @@ func extractAssets(archive *os.File, outdir string) error {
 				return err
 			}
 			defer inFile.Close()
-			outFile, err := os.Create(fullpath)
+			// Extract into a temporary file, and only move it into
+			// place once it's known to be intact, so that a corrupt
+			// file is never left behind.
+			tmpFile, err := os.CreateTemp(filepath.Dir(fullpath), "."+filepath.Base(fullpath)+".*")
+			if err != nil {
+				return err
+			}
+			defer os.Remove(tmpFile.Name())
+			defer tmpFile.Close()
+			if m != nil && zf.Name != bootstrap.ManifestName {
+				err = m.Check(zf.Name, io.TeeReader(inFile, tmpFile))
+			} else if _, err = io.Copy(tmpFile, inFile); err != nil {
+				err = fmt.Errorf("extracting %s: %w", zf.Name, err)
+			}
 			if err != nil {
 				return err
 			}
-			defer outFile.Close()
-			if _, err := io.Copy(outFile, inFile); err != nil {
+			if err := tmpFile.Chmod(zf.Mode()); err != nil {
 				return err
 			}
-			if err := outFile.Chmod(zf.Mode()); err != nil {
+			if err := tmpFile.Close(); err != nil {
 				return err
 			}
-			return nil
+			return os.Rename(tmpFile.Name(), fullpath)
 		}(zf)
 		if err != nil {
 			return err
@@ -0,0 +1,163 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/benchmarks/sweet/cli/bootstrap"
+)
+
+func TestGetSelective(t *testing.T) {
+	const version = "v1.2.3"
+	dir := t.TempDir()
+
+	// Build an assets archive with two benchmarks' worth of assets.
+	assets := filepath.Join(dir, "assets")
+	for name, data := range map[string]string{
+		"markdown/input.md": "# hello",
+		"tile38/data.json":  "{}",
+	} {
+		path := filepath.Join(assets, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	archives := filepath.Join(dir, "archives")
+	if err := os.Mkdir(archives, 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(filepath.Join(archives, bootstrap.VersionArchiveName(version)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestHash, err := createAssetsArchive(f, assets, version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(t *testing.T, manifestHash string) (string, error) {
+		// Record a bogus hash for the archive as a whole, so that
+		// downloading the whole archive fails: only a selective
+		// retrieval checked against the manifest can succeed.
+		hashFile := filepath.Join(t.TempDir(), "assets.hash")
+		vals := make(bootstrap.Hashes)
+		vals.Put(version, "bogus", false)
+		vals.PutManifest(version, manifestHash, false)
+		if err := vals.WriteToFile(hashFile); err != nil {
+			t.Fatal(err)
+		}
+		copyDir := filepath.Join(t.TempDir(), "assets")
+		c := &getCmd{
+			source:         archives,
+			copyDir:        copyDir,
+			toGet:          csvFlag{"markdown"},
+			assetsHashFile: hashFile,
+			version:        version,
+		}
+		return copyDir, c.Run(nil)
+	}
+
+	t.Run("Match", func(t *testing.T) {
+		copyDir, err := get(t, manifestHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := os.ReadFile(filepath.Join(copyDir, "markdown", "input.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "# hello" {
+			t.Errorf("got markdown/input.md %q, want %q", b, "# hello")
+		}
+		if _, err := os.Stat(filepath.Join(copyDir, "tile38")); !os.IsNotExist(err) {
+			t.Errorf("expected tile38 assets not to be extracted, got %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(copyDir, bootstrap.ManifestName)); err != nil {
+			t.Errorf("expected manifest to be extracted: %v", err)
+		}
+	})
+	t.Run("Mismatch", func(t *testing.T) {
+		_, err := get(t, strings.Repeat("0", len(manifestHash)))
+		if err == nil || !strings.Contains(err.Error(), "manifest has unexpected hash") {
+			t.Errorf("expected manifest hash mismatch, got %v", err)
+		}
+	})
+}
+
+func TestExtractAssetsCorrupt(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("markdown/input.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("# hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := bootstrap.NewManifest()
+	m.Files["markdown/input.md"] = "bogus"
+
+	outdir := t.TempDir()
+	err = extractAssets(zr, outdir, nil, m)
+	var cerr *bootstrap.CorruptFileError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("expected a corrupt file error, got %v", err)
+	}
+	// Neither the corrupt file nor the temporary file it was extracted
+	// into should be left behind.
+	entries, err := os.ReadDir(filepath.Join(outdir, "markdown"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		t.Errorf("unexpected file %s left in the assets directory", e.Name())
+	}
+}
+
+func TestExtractAssetsInvalidPath(t *testing.T) {
+	for _, name := range []string{"../escape.txt", "/abs.txt", "markdown/../../escape.txt", "../escape/"} {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		if _, err := zw.Create(name); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir := t.TempDir()
+		outdir := filepath.Join(dir, "assets")
+		err = extractAssets(zr, outdir, nil, nil)
+		if err == nil || !strings.Contains(err.Error(), "invalid path") {
+			t.Errorf("extracting %q: expected an invalid path error, got %v", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "escape.txt")); err == nil {
+			t.Errorf("extracting %q wrote outside the assets directory", name)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/benchmarks/sweet/common"
+	"golang.org/x/benchmarks/sweet/common/fileutil"
+	"golang.org/x/benchmarks/sweet/common/log"
+)
+
+// toolchainInfo identifies the toolchain in a GOROOT.
+type toolchainInfo struct {
+	goos, goarch string
+	version      string
+	commit       string // Empty if GOROOT is not a git checkout.
+}
+
+// toolchainInfo returns identifying information for the toolchain used
+// by cfg. Results are cached by GOROOT and build environment, since
+// derived configs (e.g. for PGO) share a toolchain with the config they
+// were derived from, but configs that share a GOROOT may target different
+// platforms.
+func (r *runCfg) toolchainInfo(cfg *common.Config) (*toolchainInfo, error) {
+	env := cfg.BuildEnv.Collapse()
+	sort.Strings(env)
+	key := strings.Join(append([]string{cfg.GoRoot}, env...), "\x00")
+	if ti, ok := r.toolchains[key]; ok {
+		return ti, nil
+	}
+	vars, err := cfg.GoTool().EnvVars("GOOS", "GOARCH", "GOVERSION")
+	if err != nil {
+		return nil, fmt.Errorf("reading go env for %s: %w", cfg.GoRoot, err)
+	}
+	ti := &toolchainInfo{
+		goos:    vars["GOOS"],
+		goarch:  vars["GOARCH"],
+		version: vars["GOVERSION"],
+	}
+	ti.commit, err = gitHead(cfg.GoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("reading git commit for %s: %w", cfg.GoRoot, err)
+	}
+	r.toolchains[key] = ti
+	return ti, nil
+}
+
+// gitHead returns the commit hash of HEAD if dir is the root of a git
+// checkout, or an empty string otherwise.
+func gitHead(dir string) (string, error) {
+	if ok, err := fileutil.FileExists(filepath.Join(dir, ".git")); err != nil {
+		return "", err
+	} else if !ok {
+		return "", nil
+	}
+	cmd := exec.Command("git", "-C", dir, "rev-parse", "HEAD")
+	log.TraceCommand(cmd, false)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// writeResultsHeader writes a block of configuration lines in the Go
+// benchmark format to w, describing the context that the results for
+// benchmark b under cfg are produced in.
+func (r *runCfg) writeResultsHeader(w io.Writer, b *benchmark, cfg *common.Config) error {
+	ti, err := r.toolchainInfo(cfg)
+	if err != nil {
+		return err
+	}
+	lines := []string{
+		"goos: " + ti.goos,
+		"goarch: " + ti.goarch,
+		"pkg: golang.org/x/benchmarks/sweet/benchmarks/" + b.name,
+	}
+	if r.machine != nil {
+		lines = append(lines, r.machine.Lines()...)
+	}
+	lines = append(lines,
+		"toolchain: "+cfg.Name,
+		"go-version: "+ti.version,
+	)
+	if ti.commit != "" {
+		lines = append(lines, "go-commit: "+ti.commit)
+	}
+	lines = append(lines,
+		"sweet-version: "+common.Version,
+		"runstamp: "+r.runstamp.In(time.UTC).Format(time.RFC3339Nano),
+	)
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
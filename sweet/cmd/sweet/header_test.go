@@ -0,0 +1,67 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/benchmarks/sweet/common"
+)
+
+func TestWriteResultsHeader(t *testing.T) {
+	out, err := exec.Command("go", "env", "GOROOT", "GOVERSION").Output()
+	if err != nil {
+		t.Skipf("go env: %v", err)
+	}
+	goenv := strings.Fields(string(out))
+	goroot, version := goenv[0], goenv[1]
+
+	r := &runCfg{
+		runstamp:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		toolchains: make(map[string]*toolchainInfo),
+	}
+	b := &benchmark{name: "markdown"}
+	// Two configs sharing a GOROOT, but targeting different platforms.
+	newConfig := func(name, goos, goarch string) *common.Config {
+		return &common.Config{
+			Name:     name,
+			GoRoot:   goroot,
+			BuildEnv: common.ConfigEnv{Env: common.NewEnvFromEnviron().MustSet("GOOS="+goos, "GOARCH="+goarch)},
+		}
+	}
+	for _, test := range []struct {
+		cfg  *common.Config
+		want string
+	}{
+		{newConfig("linux", "linux", "amd64"), "goos: linux\ngoarch: amd64\n"},
+		{newConfig("darwin", "darwin", "arm64"), "goos: darwin\ngoarch: arm64\n"},
+	} {
+		var sb strings.Builder
+		if err := r.writeResultsHeader(&sb, b, test.cfg); err != nil {
+			t.Fatal(err)
+		}
+		got := sb.String()
+		// The commit depends on how the toolchain was installed.
+		var lines []string
+		for _, line := range strings.SplitAfter(got, "\n") {
+			if !strings.HasPrefix(line, "go-commit: ") {
+				lines = append(lines, line)
+			}
+		}
+		got = strings.Join(lines, "")
+		want := test.want +
+			"pkg: golang.org/x/benchmarks/sweet/benchmarks/markdown\n" +
+			"toolchain: " + test.cfg.Name + "\n" +
+			"go-version: " + version + "\n" +
+			"sweet-version: " + common.Version + "\n" +
+			"runstamp: 2024-01-02T03:04:05Z\n"
+		if got != want {
+			t.Errorf("header for %s:\n%s\nwant:\n%s", test.cfg.Name, got, want)
+		}
+	}
+}
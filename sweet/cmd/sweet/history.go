@@ -0,0 +1,235 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/benchmarks/stats"
+	"golang.org/x/benchmarks/sweet/common/log"
+)
+
+const (
+	historyUsage = `Finds changepoints in a series of benchmark results.
+
+Each argument is a results directory or an archive produced by
+'sweet run -archive', and together they must be in chronological order,
+for example the results of nightly runs. For each benchmark, config and
+metric, the median value in each set of results forms a time series, in
+which changepoints are found by E-divisive segmentation (see package
+golang.org/x/benchmarks/stats). Each changepoint is tested with a
+permutation test, and is reported if it's significant at -alpha and the
+medians of the series on either side differ by at least -threshold, along
+with its p-value, its effect size, and the results it first appears in.
+
+With -algorithm=edm or -algorithm=edmx, changepoints are instead found by
+binary segmentation with E-divisive with medians, which is faster but
+doesn't test their significance: any change in the medians of at least
+-threshold is reported.
+
+Usage: %s history [flags] <results> <results> [results...]
+`
+)
+
+type historyCmd struct {
+	algorithm    string
+	delta        int
+	alpha        float64
+	permutations int
+	threshold    float64
+	metric       string
+	config       string
+	toCheck      csvFlag
+}
+
+func (*historyCmd) Name() string { return "history" }
+func (*historyCmd) Synopsis() string {
+	return "Finds changepoints in a series of benchmark results."
+}
+func (*historyCmd) PrintUsage(w io.Writer, base string) {
+	fmt.Fprintf(w, historyUsage, base)
+}
+
+func (c *historyCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&c.algorithm, "algorithm", "edivisive", "changepoint detection algorithm (options: edivisive, edm, edmx)")
+	f.IntVar(&c.delta, "delta", 3, "minimum number of results on either side of a changepoint")
+	f.Float64Var(&c.alpha, "alpha", 0.05, "significance level of changepoints")
+	f.IntVar(&c.permutations, "permutations", 199, "number of permutations with which to test the significance of changepoints")
+	f.Float64Var(&c.threshold, "threshold", 0.02, "minimum relative change in the median to report")
+	f.StringVar(&c.metric, "metric", "", "only check the metric with this unit, such as ns/op (default: all)")
+	f.StringVar(&c.config, "config", "", "only check the config with this name (default: all)")
+	f.Var(&c.toCheck, "run", "benchmark group or comma-separated list of benchmarks to check")
+}
+
+// historyPoint is a point in a series, from one set of results.
+type historyPoint struct {
+	value float64 // The median of the results.
+	at    int     // Index of the results.
+}
+
+// seriesKey identifies a series.
+type seriesKey struct {
+	benchmark, name, config, metric string
+}
+
+// historyInput describes one set of results in the history.
+type historyInput struct {
+	path   string
+	commit string // The go-commit of the results, if any.
+}
+
+func (in historyInput) String() string {
+	s := strings.TrimSuffix(filepath.Base(in.path), ".tar.gz")
+	if in.commit != "" {
+		s += ", go-commit " + shortRev(in.commit)
+	}
+	return s
+}
+
+func (c *historyCmd) Run(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("expected at least two sets of results")
+	}
+	log.SetActivityLog(true)
+
+	switch c.algorithm {
+	case "edivisive":
+		if c.delta < 2 {
+			return fmt.Errorf("-delta must be at least 2")
+		}
+		if c.alpha <= 0 || c.alpha >= 1 {
+			return fmt.Errorf("-alpha must be between 0 and 1")
+		}
+		if c.permutations < 1 {
+			return fmt.Errorf("-permutations must be at least 1")
+		}
+	case "edm", "edmx":
+		if c.delta < 1 {
+			return fmt.Errorf("-delta must be at least 1")
+		}
+	default:
+		return fmt.Errorf("unknown algorithm %q", c.algorithm)
+	}
+	benchmarks, err := selectBenchmarks(c.toCheck, "all")
+	if err != nil {
+		return err
+	}
+	selected := make(map[string]bool)
+	for _, b := range benchmarks {
+		selected[b.name] = true
+	}
+
+	// Build a series for each benchmark, config and metric.
+	inputs := make([]historyInput, len(args))
+	series := make(map[seriesKey][]historyPoint)
+	for i, arg := range args {
+		inputs[i].path = arg
+		files, err := readResults(arg)
+		if err != nil {
+			return err
+		}
+		values := make(map[seriesKey][]float64)
+		for _, f := range files {
+			if !selected[f.Benchmark] || (c.config != "" && f.Config != c.config) {
+				continue
+			}
+			for _, r := range f.Results {
+				if commit := r.Config["go-commit"]; commit != "" {
+					inputs[i].commit = commit
+				}
+				for _, v := range r.Values {
+					if c.metric != "" && v.Unit != c.metric {
+						continue
+					}
+					k := seriesKey{f.Benchmark, r.Name, f.Config, v.Unit}
+					values[k] = append(values[k], v.Value)
+				}
+			}
+		}
+		for k, vs := range values {
+			series[k] = append(series[k], historyPoint{stats.Median(vs), i})
+		}
+	}
+
+	keys := make([]seriesKey, 0, len(series))
+	for k := range series {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.benchmark != b.benchmark {
+			return a.benchmark < b.benchmark
+		}
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		if a.config != b.config {
+			return a.config < b.config
+		}
+		return a.metric < b.metric
+	})
+
+	var found int
+	for _, k := range keys {
+		points := series[k]
+		values := make([]float64, len(points))
+		for i, p := range points {
+			values[i] = p.value
+		}
+		cps := c.changepoints(values)
+		if len(cps) == 0 {
+			continue
+		}
+		fmt.Printf("%s %s %s [%s]:\n", k.benchmark, k.name, k.metric, k.config)
+		for _, cp := range cps {
+			at := points[cp.Index].at
+			change := fmt.Sprintf("%+.1f%%", 100*(cp.After-cp.Before)/cp.Before)
+			if c.algorithm == "edivisive" {
+				change += fmt.Sprintf(", p=%.3f, d=%.2f", cp.PValue, cp.EffectSize)
+			}
+			fmt.Printf("  at %d (%s): %.4g -> %.4g (%s)\n", at, inputs[at], cp.Before, cp.After, change)
+		}
+		found += len(cps)
+	}
+	if found == 0 {
+		log.Printf("No changepoints found in %d series", len(keys))
+	}
+	return nil
+}
+
+// changepoints returns the changepoints in values found by c.algorithm
+// whose medians on either side differ by at least c.threshold.
+func (c *historyCmd) changepoints(values []float64) []stats.Changepoint {
+	switch c.algorithm {
+	case "edm":
+		return stats.Segment(values, c.delta, stats.EDM, c.significant)
+	case "edmx":
+		return stats.Segment(values, c.delta, stats.EDMX, c.significant)
+	}
+	cps := stats.EDivisive(values, stats.EDivisiveOptions{
+		MinSize:      c.delta,
+		Alpha:        c.alpha,
+		Permutations: c.permutations,
+	})
+	var filtered []stats.Changepoint
+	for _, cp := range cps {
+		if c.significant(cp) {
+			filtered = append(filtered, cp)
+		}
+	}
+	return filtered
+}
+
+// significant reports whether the medians on either side of cp differ
+// by at least c.threshold.
+func (c *historyCmd) significant(cp stats.Changepoint) bool {
+	return cp.Before != 0 && math.Abs(cp.After-cp.Before)/math.Abs(cp.Before) >= c.threshold
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHistoryChangepoints(t *testing.T) {
+	var values []float64
+	for _, level := range []float64{100, 120, 110} {
+		for j := 0; j < 10; j++ {
+			// A little noise.
+			values = append(values, level+float64(j%2))
+		}
+	}
+	for _, algorithm := range []string{"edivisive", "edmx"} {
+		c := &historyCmd{algorithm: algorithm, delta: 3, alpha: 0.05, permutations: 199, threshold: 0.02}
+		var got []int
+		for _, cp := range c.changepoints(values) {
+			got = append(got, cp.Index)
+		}
+		if want := []int{10, 20}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got changepoints at %v, want %v", algorithm, got, want)
+		}
+
+		// Changes below the threshold aren't reported.
+		c.threshold = 0.5
+		if cps := c.changepoints(values); len(cps) != 0 {
+			t.Errorf("%s: got changepoints %+v, want none", algorithm, cps)
+		}
+	}
+
+	// EDM approximates the medians, so it may be off by one.
+	c := &historyCmd{algorithm: "edm", delta: 3, threshold: 0.02}
+	cps := c.changepoints(values)
+	if len(cps) != 2 || cps[0].Index < 9 || cps[0].Index > 11 || cps[1].Index < 19 || cps[1].Index > 21 {
+		t.Errorf("edm: got changepoints %+v, want about 10 and 20", cps)
+	}
+}
@@ -200,6 +200,9 @@ func TestSweetEndToEnd(t *testing.T) {
 		{"bleve-index", 1},
 		{"gopher-lua", 1},
 		{"markdown", 1},
+		// Run the legacy benchmarks as a group, to cover
+		// running a group with -pgo.
+		{"legacy", 2},
 		// TODO(go.dev/issue/51445): Enable once gVisor builds with Go 1.19.
 		// {"gvisor", 1},
 	} {
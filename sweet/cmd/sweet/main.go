@@ -15,5 +15,13 @@ func main() {
 	subcommands.Register(&putCmd{})
 	subcommands.Register(&runCmd{})
 	subcommands.Register(&genCmd{})
+	subcommands.Register(&serveAssetsCmd{})
+	subcommands.Register(&unpackCmd{})
+	subcommands.Register(&exportCmd{})
+	subcommands.Register(&historyCmd{})
+	subcommands.Register(&diffprofCmd{})
+	subcommands.Register(&bisectCmd{})
+	subcommands.Register(&agentCmd{})
+	subcommands.Register(&submitCmd{})
 	os.Exit(subcommands.Run())
 }
@@ -0,0 +1,125 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/pprof/profile"
+	"golang.org/x/benchmarks/sweet/common"
+)
+
+// writeCPUProfile writes a CPU profile to path with one sample in each
+// of the named functions, hottest first.
+func writeCPUProfile(t *testing.T, path string, funcs ...string) {
+	t.Helper()
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}, {Type: "cpu", Unit: "nanoseconds"}},
+		PeriodType: &profile.ValueType{Type: "cpu", Unit: "nanoseconds"},
+		Period:     1,
+	}
+	for i, name := range funcs {
+		id := uint64(i + 1)
+		fn := &profile.Function{ID: id, Name: name}
+		loc := &profile.Location{ID: id, Address: 0x10 * id, Line: []profile.Line{{Function: fn}}}
+		p.Function = append(p.Function, fn)
+		p.Location = append(p.Location, loc)
+		p.Sample = append(p.Sample, &profile.Sample{
+			Location: []*profile.Location{loc},
+			Value:    []int64{1, int64(len(funcs)-i) * 1000},
+		})
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Write(f); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPGOConfigName(t *testing.T) {
+	for _, test := range []struct {
+		rounds, round int
+		want          string
+	}{
+		{1, 1, "base.pgo"},
+		{3, 1, "base.pgo1"},
+		{3, 3, "base.pgo3"},
+	} {
+		c := &runCmd{runCfg: runCfg{pgoRounds: test.rounds}}
+		if got := c.pgoConfigName("base", test.round); got != test.want {
+			t.Errorf("pgoConfigName(%q, %d) with %d rounds = %q, want %q", "base", test.round, test.rounds, got, test.want)
+		}
+	}
+}
+
+func TestTopFunctionsOverlap(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.pprof")
+	b := filepath.Join(dir, "b.pprof")
+	writeCPUProfile(t, a, "f", "g", "h", "i")
+	writeCPUProfile(t, b, "g", "f", "j", "k")
+
+	o, err := topFunctionsOverlap(a, b, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (functionOverlap{common: 2, n: 3}); o != want {
+		t.Errorf("topFunctionsOverlap(a, b, 3) = %+v, want %+v", o, want)
+	}
+	if o, err = topFunctionsOverlap(a, a, 10); err != nil {
+		t.Fatal(err)
+	}
+	if want := (functionOverlap{common: 4, n: 4}); o != want {
+		t.Errorf("topFunctionsOverlap(a, a, 10) = %+v, want %+v", o, want)
+	}
+	if _, err := topFunctionsOverlap(a, filepath.Join(dir, "missing"), 3); err == nil {
+		t.Error("expected error for missing profile")
+	}
+}
+
+func TestReportPGORound(t *testing.T) {
+	dir := t.TempDir()
+	for name, funcs := range map[string][]string{
+		"pgo1-main.pprof": {"f", "g"},
+		"pgo2-main.pprof": {"f", "h"},
+		"pgo1-tool.pprof": {"x"},
+		"pgo2-tool.pprof": {"x"},
+	} {
+		writeCPUProfile(t, filepath.Join(dir, name), funcs...)
+	}
+	b := &benchmark{name: "bench"}
+	prev := &common.Config{Name: "base.pgo1", PGOFiles: map[string]string{
+		"bench/main": filepath.Join(dir, "pgo1-main.pprof"),
+		"bench/tool": filepath.Join(dir, "pgo1-tool.pprof"),
+	}}
+	cur := &common.Config{Name: "base.pgo2", PGOFiles: map[string]string{
+		"bench/main": filepath.Join(dir, "pgo2-main.pprof"),
+		"bench/tool": filepath.Join(dir, "pgo2-tool.pprof"),
+	}}
+	c := &runCmd{runCfg: runCfg{resultsDir: filepath.Join(dir, "results"), pgoRounds: 2}}
+	if err := c.reportPGORound([]*common.Config{prev}, []*common.Config{cur}, []*benchmark{b}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "results", "bench", "pgo-rounds.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"bench/main: base.pgo1 -> base.pgo2: top-20 function overlap 1/2 (50%)",
+		"bench/tool: base.pgo1 -> base.pgo2: top-20 function overlap 1/1 (100%)",
+		"",
+	}, "\n")
+	if string(got) != want {
+		t.Errorf("pgo-rounds.txt:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// durationsFile is the name of the file in the cache directory that
+// records how long each benchmark took in previous runs on this machine,
+// for the estimates printed by -dry-run. The cache directory is used so
+// that the file persists across invocations; 'sweet serve-assets' only
+// serves assets archives from it, so the file isn't shared with other
+// machines.
+const durationsFile = "durations.json"
+
+// benchDuration is how long the steps for one benchmark and config took.
+type benchDuration struct {
+	Build time.Duration `json:"build"`
+	Run   time.Duration `json:"run"` // Per run.
+}
+
+// durations maps benchmark keys (see durationKey) to the most recently
+// measured durations for that benchmark.
+type durations map[string]benchDuration
+
+// durationKey returns the key in durations for b, which is distinct for
+// the short version of each benchmark.
+func durationKey(b *benchmark, short bool) string {
+	if short {
+		return b.name + "/short"
+	}
+	return b.name
+}
+
+// readDurations reads the durations recorded in the cache directory.
+// A missing file is not an error.
+func readDurations(cache string) (durations, error) {
+	d := make(durations)
+	b, err := os.ReadFile(filepath.Join(cache, durationsFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return d, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &d); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", durationsFile, err)
+	}
+	return d, nil
+}
+
+// recordDurations merges d into the durations recorded in the cache
+// directory.
+func recordDurations(cache string, d durations) error {
+	all, err := readDurations(cache)
+	if err != nil {
+		return err
+	}
+	for k, v := range d {
+		all[k] = v
+	}
+	b, err := json.MarshalIndent(all, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cache, os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(cache, durationsFile), b, 0644)
+}
+
+// durationSum is the total time taken by the steps for one benchmark
+// over a number of configs.
+type durationSum struct {
+	build, run time.Duration
+	n          int
+}
+
+// durationSums maps benchmark keys (see durationKey) to the durations
+// measured for each config in this invocation.
+type durationSums map[string]*durationSum
+
+// record notes that b took build to build and run per run for a single
+// config.
+func (r *runCfg) record(b *benchmark, build, run time.Duration) {
+	if r.durations == nil {
+		return
+	}
+	k := durationKey(b, r.short)
+	s := r.durations[k]
+	if s == nil {
+		s = new(durationSum)
+		r.durations[k] = s
+	}
+	s.build += build
+	s.run += run
+	s.n++
+}
+
+// means returns the mean durations across configs in m.
+func (m durationSums) means() durations {
+	d := make(durations, len(m))
+	for k, s := range m {
+		n := time.Duration(s.n)
+		d[k] = benchDuration{Build: s.build / n, Run: s.run / n}
+	}
+	return d
+}
+
+// estimate accumulates an estimate of how long the benchmarks executed
+// in a dry run would take, based on how long they took in previous runs.
+type estimate struct {
+	prev    durations
+	total   time.Duration
+	unknown map[string]bool // Benchmarks with no previous runs.
+}
+
+func newEstimate(prev durations) *estimate {
+	return &estimate{prev: prev, unknown: make(map[string]bool)}
+}
+
+// add adds the time it would take to execute b with n configs under r.
+func (e *estimate) add(b *benchmark, r *runCfg, n int) {
+	d, ok := e.prev[durationKey(b, r.short)]
+	if !ok {
+		e.unknown[b.name] = true
+		return
+	}
+	e.total += time.Duration(n) * (d.Build + time.Duration(r.count)*d.Run)
+}
+
+func (e *estimate) String() string {
+	s := e.total.Round(time.Second).String()
+	if len(e.unknown) == 0 {
+		return s
+	}
+	unknown := make([]string, 0, len(e.unknown))
+	for name := range e.unknown {
+		unknown = append(unknown, name)
+	}
+	sort.Strings(unknown)
+	return fmt.Sprintf("at least %s (no previous runs of %s)", s, strings.Join(unknown, ", "))
+}
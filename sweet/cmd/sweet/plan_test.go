@@ -0,0 +1,116 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"golang.org/x/benchmarks/sweet/common"
+	"golang.org/x/benchmarks/sweet/common/diagnostics"
+	"golang.org/x/benchmarks/sweet/common/log"
+)
+
+func TestRecordMean(t *testing.T) {
+	b := &benchmark{name: "bench"}
+	r := &runCfg{durations: make(durationSums)}
+	for _, d := range []time.Duration{1, 2, 6} {
+		r.record(b, d*time.Second, d*time.Millisecond)
+	}
+	got := r.durations.means()["bench"]
+	want := benchDuration{Build: 3 * time.Second, Run: 3 * time.Millisecond}
+	if got != want {
+		t.Errorf("mean durations = %+v, want %+v", got, want)
+	}
+}
+
+// falseHarness is a harness whose commands all fail if they're run.
+type falseHarness struct{}
+
+func (falseHarness) CheckPrerequisites() error { return nil }
+
+func (falseHarness) Get(gcfg *common.GetConfig) error {
+	return common.RunCommand(exec.Command("false", "get", gcfg.SrcDir))
+}
+
+func (falseHarness) Build(cfg *common.Config, bcfg *common.BuildConfig) error {
+	return common.RunCommand(exec.Command("false", "build", bcfg.BinDir))
+}
+
+func (falseHarness) Run(cfg *common.Config, rcfg *common.RunConfig) error {
+	cmd := exec.Command("false", append([]string{"run", rcfg.BinDir}, rcfg.Args...)...)
+	cmd.Stdout = rcfg.Results
+	return common.RunCommand(cmd)
+}
+
+func TestDryRun(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	common.SetDryRun(true)
+	defer func() {
+		common.SetDryRun(false)
+		log.SetCommandTrace(false)
+		log.SetOutput(nil)
+	}()
+
+	dir := t.TempDir()
+	r := &runCfg{
+		count:      3,
+		benchDir:   filepath.Join(dir, "benchmarks"),
+		workDir:    filepath.Join(dir, "work"),
+		resultsDir: filepath.Join(dir, "results"),
+		assetsFS:   fstest.MapFS{},
+		estimate: newEstimate(durations{
+			"bench": {Build: time.Minute, Run: time.Second},
+		}),
+	}
+	var cfgs []*common.Config
+	for _, name := range []string{"base", "exp"} {
+		cfg := (&common.Config{Name: name}).Copy()
+		cfg.Diagnostics.Set(diagnostics.Config{Type: diagnostics.CPUProfile})
+		cfgs = append(cfgs, cfg)
+	}
+	for _, b := range []*benchmark{{name: "bench", harness: falseHarness{}}, {name: "new", harness: falseHarness{}}} {
+		if err := b.execute(cfgs, r); err != nil {
+			t.Fatalf("executing %s: %v", b.name, err)
+		}
+	}
+
+	// Nothing should have been done.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("dry run created %s", entries[0].Name())
+	}
+
+	// Every step should have been printed.
+	trace := buf.String()
+	for _, want := range []string{
+		"false get " + filepath.Join(r.workDir, "bench", "src"),
+		"mkdir -p " + filepath.Join(r.resultsDir, "bench", "base.debug"),
+		"false build " + filepath.Join(r.workDir, "bench", "exp", "bin"),
+		"false run " + filepath.Join(r.workDir, "bench", "base", "bin") + " -cpuprofile " + filepath.Join(r.resultsDir, "bench", "base.debug") + " -run-index 2",
+		"rm -rf " + filepath.Join(r.workDir, "bench", "exp", "tmp") + "/*",
+	} {
+		if !strings.Contains(trace, "[shell] "+want+"\n") {
+			t.Errorf("trace is missing %q:\n%s", want, trace)
+		}
+	}
+	if n := strings.Count(trace, "[shell] false run "); n != 2*2*3 {
+		t.Errorf("traced %d runs, want %d", n, 2*2*3)
+	}
+
+	if got, want := r.estimate.String(), "at least 2m6s (no previous runs of new)"; got != want {
+		t.Errorf("estimate is %q, want %q", got, want)
+	}
+}
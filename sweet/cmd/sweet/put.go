@@ -72,23 +72,28 @@ func (c *putCmd) Run(_ []string) error {
 	w := io.MultiWriter(wc, hash)
 
 	// Write the archive.
-	if err := createAssetsArchive(w, c.assetsDir, c.version); err != nil {
+	manifestHash, err := createAssetsArchive(w, c.assetsDir, c.version)
+	if err != nil {
 		return err
 	}
 
 	// Update hash file.
 	log.Printf("Updating hash file...")
-	return updateAssetsHash(bootstrap.CanonicalizeHash(hash), c.assetsHashFile, c.version, c.force)
+	return updateAssetsHash(bootstrap.CanonicalizeHash(hash), manifestHash, c.assetsHashFile, c.version, c.force)
 }
 
-func createAssetsArchive(w io.Writer, assetsDir, version string) (err error) {
+// createAssetsArchive writes a zip archive of assetsDir to w, including
+// a manifest of the hashes of all files in the archive. It returns the
+// hash of the manifest.
+func createAssetsArchive(w io.Writer, assetsDir, version string) (manifestHash string, err error) {
 	zw := zip.NewWriter(w)
 	defer func() {
 		if cerr := zw.Close(); cerr != nil && err == nil {
 			err = fmt.Errorf("closing zip archive: %w", cerr)
 		}
 	}()
-	return filepath.Walk(assetsDir, func(fpath string, info os.FileInfo, err error) error {
+	manifest := bootstrap.NewManifest()
+	err = filepath.Walk(assetsDir, func(fpath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -97,11 +102,17 @@ func createAssetsArchive(w io.Writer, assetsDir, version string) (err error) {
 			// By the guarantees of filepath.Walk, this shouldn't happen.
 			panic(err)
 		}
+		outPath = filepath.ToSlash(outPath)
 		if info.IsDir() {
 			// Add a trailing slash to indicate we're creating a directory.
 			_, err := zw.Create(outPath + "/")
 			return err
 		}
+		if outPath == bootstrap.ManifestName {
+			// Skip any manifest from a previously extracted archive.
+			// We'll generate a fresh one.
+			return nil
+		}
 		if info.Mode()&os.ModeSymlink != 0 {
 			return fmt.Errorf("encountered symlink %s: symbolic links are not supported in assets", fpath)
 		}
@@ -121,13 +132,36 @@ func createAssetsArchive(w io.Writer, assetsDir, version string) (err error) {
 		}
 		defer f.Close()
 
-		// Copy data into the archive.
-		_, err = io.Copy(zf, f)
-		return err
+		// Copy data into the archive, hashing it along the way.
+		h := bootstrap.Hash()
+		if _, err := io.Copy(zf, io.TeeReader(f, h)); err != nil {
+			return err
+		}
+		manifest.Files[outPath] = bootstrap.CanonicalizeHash(h)
+		return nil
 	})
+	if err != nil {
+		return "", err
+	}
+
+	// Write out the manifest.
+	b, err := manifest.Marshal()
+	if err != nil {
+		return "", err
+	}
+	zf, err := zw.Create(bootstrap.ManifestName)
+	if err != nil {
+		return "", err
+	}
+	if _, err := zf.Write(b); err != nil {
+		return "", err
+	}
+	h := bootstrap.Hash()
+	h.Write(b)
+	return bootstrap.CanonicalizeHash(h), nil
 }
 
-func updateAssetsHash(hash, hashfile, version string, force bool) error {
+func updateAssetsHash(hash, manifestHash, hashfile, version string, force bool) error {
 	vals, err := bootstrap.ReadHashesFile(hashfile)
 	if err != nil {
 		return err
@@ -135,5 +169,8 @@ func updateAssetsHash(hash, hashfile, version string, force bool) error {
 	if ok := vals.Put(version, hash, force); !ok {
 		return fmt.Errorf("hash for this version already exists")
 	}
+	if ok := vals.PutManifest(version, manifestHash, force); !ok {
+		return fmt.Errorf("manifest hash for this version already exists")
+	}
 	return vals.WriteToFile(hashfile)
 }
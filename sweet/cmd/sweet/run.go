@@ -13,15 +13,16 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"golang.org/x/benchmarks/sweet/cli/bootstrap"
 	"golang.org/x/benchmarks/sweet/common"
 	"golang.org/x/benchmarks/sweet/common/diagnostics"
 	"golang.org/x/benchmarks/sweet/common/log"
+	"golang.org/x/benchmarks/sweet/common/machine"
 	sprofile "golang.org/x/benchmarks/sweet/common/profile"
 
 	"github.com/BurntSushi/toml"
@@ -49,6 +50,8 @@ files. Note: by default, this command expects to run from /path/to/x/benchmarks/
 const (
 	countDefault       = 10
 	pgoCountDefaultMax = 5
+	idleMaxLoad        = 0.2
+	idleTimeoutDefault = 30 * time.Minute
 )
 
 type runCfg struct {
@@ -61,18 +64,34 @@ type runCfg struct {
 	dumpCore    bool
 	pgo         bool
 	pgoCount    int
+	pgoRounds   int
 	short       bool
 
-	assetsFS fs.FS
+	// rerunOutliers is the maximum number of runs of each benchmark and
+	// config with outliers to rerun.
+	rerunOutliers int
+
+	// reuseBuilds is whether to skip building a config whose work
+	// directory already holds binaries from an earlier execution.
+	reuseBuilds bool
+
+	assetsFS   fs.FS
+	manifest   *bootstrap.Manifest // nil if the assets have no manifest.
+	machine    *machine.Info
+	runstamp   time.Time
+	toolchains map[string]*toolchainInfo
+	durations  durationSums // Durations measured in this invocation, if recorded.
+	estimate   *estimate    // Non-nil in a dry run.
 }
 
-func (r *runCfg) logCopyDirCommand(fromRelDir, toDir string) {
+// copyDirCommand returns the shell command that copies the assets in
+// fromRelDir into toDir.
+func (r *runCfg) copyDirCommand(fromRelDir, toDir string) string {
 	if r.assetsDir == "" {
 		assetsFile, _ := bootstrap.CachedAssets(r.assetsCache, common.Version)
-		log.CommandPrintf("unzip %s '%s/*' -d %s", assetsFile, fromRelDir, toDir)
-	} else {
-		log.CommandPrintf("cp -r %s/* %s", filepath.Join(r.assetsDir, fromRelDir), toDir)
+		return fmt.Sprintf("unzip %s '%s/*' -d %s", assetsFile, fromRelDir, toDir)
 	}
+	return fmt.Sprintf("cp -r %s/* %s", filepath.Join(r.assetsDir, fromRelDir), toDir)
 }
 
 func (r *runCfg) benchmarkResultsDir(b *benchmark) string {
@@ -89,6 +108,12 @@ type runCmd struct {
 	printCmd    bool
 	stopOnError bool
 	toRun       csvFlag
+	waitIdle    bool
+	idleTimeout time.Duration
+	governor    machine.GovernorPolicy
+	archive     string
+	export      string
+	dryRun      bool
 }
 
 func (*runCmd) Name() string     { return "run" }
@@ -149,13 +174,22 @@ func (c *runCmd) SetFlags(f *flag.FlagSet) {
 	f.BoolVar(&c.runCfg.dumpCore, "dump-core", false, "whether to dump core files for each benchmark process when it completes a benchmark")
 	f.BoolVar(&c.pgo, "pgo", false, "perform PGO testing; for each config, collect profiles from a baseline run which are used to feed into a generated PGO config")
 	f.IntVar(&c.runCfg.pgoCount, "pgo-count", 0, "the number of times to run profiling runs for -pgo; defaults to the value of -count if <=5, or 5 if higher")
+	f.IntVar(&c.runCfg.pgoRounds, "pgo-rounds", 1, "the number of rounds of PGO for -pgo; each round profiles the previous round's PGO config to produce a new one")
 	f.IntVar(&c.runCfg.count, "count", 0, fmt.Sprintf("the number of times to run each benchmark (default %d)", countDefault))
 
 	f.BoolVar(&c.quiet, "quiet", false, "whether to suppress activity output on stderr (no effect on -shell)")
 	f.BoolVar(&c.printCmd, "shell", false, "whether to print the commands being executed to stdout")
 	f.BoolVar(&c.stopOnError, "stop-on-error", false, "whether to stop running benchmarks if an error occurs or a benchmark fails")
+	f.IntVar(&c.runCfg.rerunOutliers, "rerun-outliers", 0, "rerun up to this many runs of each benchmark and config whose results include outliers, replacing their results")
 	f.BoolVar(&c.short, "short", false, "whether to run a short version of the benchmarks for testing (changes -count to 1)")
 	f.Var(&c.toRun, "run", "benchmark group or comma-separated list of benchmarks to run")
+	f.BoolVar(&c.waitIdle, "wait-idle", false, fmt.Sprintf("wait for the 1-minute load average to drop below %.1f before running benchmarks", idleMaxLoad))
+	f.DurationVar(&c.idleTimeout, "wait-idle-timeout", idleTimeoutDefault, "how long -wait-idle waits before giving up (0 means forever)")
+	c.governor = machine.GovernorWarn
+	f.Var(&c.governor, "governor", "what to do if the CPU frequency governor is not 'performance' (options: ignore, warn, refuse)")
+	f.BoolVar(&c.dryRun, "dry-run", false, "print the steps that would be performed and an estimate of how long they would take, without performing them")
+	f.StringVar(&c.export, "export", "", "write the results as OpenMetrics text to this file (see 'sweet export')")
+	f.StringVar(&c.archive, "archive", "", "write a gzipped tar archive of the results, configs, build information and environment to this file (see 'sweet unpack')")
 }
 
 func (c *runCmd) Run(args []string) error {
@@ -167,6 +201,9 @@ func (c *runCmd) Run(args []string) error {
 	log.SetCommandTrace(c.printCmd)
 	log.SetActivityLog(!c.quiet)
 
+	c.runstamp = time.Now()
+	c.toolchains = make(map[string]*toolchainInfo)
+
 	if c.runCfg.count == 0 {
 		if c.short {
 			c.runCfg.count = 1
@@ -181,96 +218,15 @@ func (c *runCmd) Run(args []string) error {
 		}
 	}
 
-	var err error
-	if c.workDir == "" {
-		// Create a temporary work tree for running the benchmarks.
-		c.workDir, err = os.MkdirTemp("", "gosweet")
-		if err != nil {
-			return fmt.Errorf("creating work root: %w", err)
-		}
+	if c.runCfg.pgoRounds < 1 {
+		return fmt.Errorf("-pgo-rounds must be at least 1")
 	}
-	// Ensure all provided directories are absolute paths. This avoids problems with
-	// benchmarks potentially changing their current working directory.
-	c.workDir, err = filepath.Abs(c.workDir)
-	if err != nil {
-		return fmt.Errorf("creating absolute path from provided work root (-work-dir): %w", err)
-	}
-	c.benchDir, err = filepath.Abs(c.benchDir)
-	if err != nil {
-		return fmt.Errorf("creating absolute path from benchmarks path (-bench-dir): %w", err)
-	}
-	c.resultsDir, err = filepath.Abs(c.resultsDir)
+
+	cleanup, err := c.setUp()
 	if err != nil {
-		return fmt.Errorf("creating absolute path from results path (-results): %w", err)
-	}
-	if c.assetsDir != "" {
-		c.assetsDir, err = filepath.Abs(c.assetsDir)
-		if err != nil {
-			return fmt.Errorf("creating absolute path from assets path (-assets-dir): %w", err)
-		}
-		if info, err := os.Stat(c.assetsDir); os.IsNotExist(err) {
-			return fmt.Errorf("assets not found at %q: did you forget to run `sweet get`?", c.assetsDir)
-		} else if err != nil {
-			return fmt.Errorf("stat assets %q: %v", c.assetsDir, err)
-		} else if info.Mode()&os.ModeDir == 0 {
-			return fmt.Errorf("%q is not a directory", c.assetsDir)
-		}
-		c.assetsFS = os.DirFS(c.assetsDir)
-	} else {
-		if c.assetsCache == "" {
-			return fmt.Errorf("missing assets cache (-cache) and assets directory (-assets-dir): cannot proceed without assets")
-		}
-		c.assetsCache, err = filepath.Abs(c.assetsCache)
-		if err != nil {
-			return fmt.Errorf("creating absolute path from assets cache path (-cache): %w", err)
-		}
-		if info, err := os.Stat(c.assetsCache); os.IsNotExist(err) {
-			return fmt.Errorf("assets not found at %q (-assets-dir): did you forget to run `sweet get`?", c.assetsDir)
-		} else if err != nil {
-			return fmt.Errorf("stat assets %q: %v", c.assetsDir, err)
-		} else if info.Mode()&os.ModeDir == 0 {
-			return fmt.Errorf("%q (-assets-dir) is not a directory", c.assetsDir)
-		}
-		assetsFile, err := bootstrap.CachedAssets(c.assetsCache, common.Version)
-		if err == bootstrap.ErrNotInCache {
-			return fmt.Errorf("assets for version %q not found in %q", common.Version, c.assetsCache)
-		} else if err != nil {
-			return err
-		}
-		f, err := os.Open(assetsFile)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-		fi, err := f.Stat()
-		if err != nil {
-			return err
-		}
-		c.assetsFS, err = zip.NewReader(f, fi.Size())
-		if err != nil {
-			return err
-		}
-	}
-	// Validate c.benchDir and provide helpful error messages..
-	if fi, err := os.Stat(c.benchDir); errors.Is(err, fs.ErrNotExist) {
-		return fmt.Errorf("benchmarks directory (-bench-dir) does not exist; did you mean to run this command from x/benchmarks/sweet?")
-	} else if err != nil {
-		return fmt.Errorf("checking benchmarks directory (-bench-dir): %w", err)
-	} else {
-		if !fi.IsDir() {
-			return fmt.Errorf("-bench-dir is not a directory; did you mean to run this command from x/benchmarks/sweet?")
-		}
-		var missing []string
-		for _, b := range allBenchmarks {
-			fi, err := os.Stat(filepath.Join(c.benchDir, b.name))
-			if err != nil || !fi.IsDir() {
-				missing = append(missing, b.name)
-			}
-		}
-		if len(missing) != 0 {
-			return fmt.Errorf("benchmarks directory (-bench-dir) is missing benchmarks (%s); did you mean to run this command from x/benchmarks/sweet?", strings.Join(missing, ", "))
-		}
+		return err
 	}
+	defer cleanup()
 	log.Printf("Work directory: %s", c.workDir)
 
 	// Parse and validate all input TOML configs.
@@ -323,8 +279,10 @@ func (c *runCmd) Run(args []string) error {
 				config.PGOFiles = make(map[string]string)
 			}
 			for k := range config.PGOFiles {
-				if _, ok := allBenchmarksMap[k]; !ok {
-					return fmt.Errorf("config %q in %q pgofiles references unknown benchmark %q", config.Name, configFile, k)
+				// Keys may name a binary within the benchmark as well.
+				name, _, _ := strings.Cut(k, "/")
+				if _, ok := allBenchmarksMap[name]; !ok {
+					return fmt.Errorf("config %q in %q pgofiles references unknown benchmark %q", config.Name, configFile, name)
 				}
 			}
 			configs = append(configs, config)
@@ -332,34 +290,15 @@ func (c *runCmd) Run(args []string) error {
 	}
 
 	// Decide which benchmarks to run, based on the -run flag.
-	var benchmarks []*benchmark
-	var unknown []string
-	switch len(c.toRun) {
-	case 0:
-		benchmarks = benchmarkGroups["default"]
-	case 1:
-		if grp, ok := benchmarkGroups[c.toRun[0]]; ok {
-			benchmarks = grp
-			break
-		}
-		fallthrough
-	default:
-		for _, name := range c.toRun {
-			if benchmark, ok := allBenchmarksMap[name]; ok {
-				benchmarks = append(benchmarks, benchmark)
-			} else {
-				unknown = append(unknown, name)
-			}
-		}
-	}
-	if len(unknown) != 0 {
-		return fmt.Errorf("unknown benchmarks: %s", strings.Join(unknown, ", "))
+	benchmarks, err := selectBenchmarks(c.toRun, "default")
+	if err != nil {
+		return err
 	}
 
 	// Print an indication of how many runs will be done.
 	countString := fmt.Sprintf("%d runs", c.runCfg.count*len(configs))
 	if c.pgo {
-		countString += fmt.Sprintf(", %d pgo runs", c.runCfg.pgoCount*len(configs))
+		countString += fmt.Sprintf(", %d pgo runs", c.runCfg.pgoCount*c.runCfg.pgoRounds*len(configs))
 	}
 	log.Printf("Benchmarks: %s (%s)", strings.Join(benchmarkNames(benchmarks), " "), countString)
 
@@ -370,6 +309,26 @@ func (c *runCmd) Run(args []string) error {
 		}
 	}
 
+	if c.dryRun {
+		// Go through all the steps, but only print them, and estimate
+		// how long they'd take instead.
+		common.SetDryRun(true)
+		defer common.SetDryRun(false)
+		prev, err := readDurations(c.assetsCache)
+		if err != nil {
+			return err
+		}
+		c.runCfg.estimate = newEstimate(prev)
+	} else {
+		// Check the machine and record its state for the results.
+		if err := c.checkMachine(); err != nil {
+			return err
+		}
+
+		// Measure how long each benchmark takes, for future estimates.
+		c.runCfg.durations = make(durationSums)
+	}
+
 	// Collect profiles from baseline runs and create new PGO'd configs.
 	if c.pgo {
 		configs, err = c.preparePGO(configs, benchmarks)
@@ -389,15 +348,103 @@ func (c *runCmd) Run(args []string) error {
 			log.Error(err)
 		}
 	}
+
+	if c.dryRun {
+		log.Printf("Estimated time: %s", c.runCfg.estimate)
+	} else if err := recordDurations(c.assetsCache, c.runCfg.durations.means()); err != nil {
+		log.Printf("warning: recording benchmark durations: %v", err)
+	}
+
+	// Export the results for monitoring.
+	if c.export != "" {
+		err := common.Do(fmt.Sprintf("# export the results to %s", c.export), func() error {
+			return c.exportResults(benchmarks)
+		})
+		if err != nil {
+			return fmt.Errorf("exporting results: %w", err)
+		}
+	}
+
+	// Bundle up everything needed to interpret the results elsewhere.
+	if c.archive != "" {
+		err := common.Do(fmt.Sprintf("# write archive %s", c.archive), func() error {
+			return c.writeArchive(configs, benchmarks)
+		})
+		if err != nil {
+			return fmt.Errorf("writing results archive: %w", err)
+		}
+	}
 	if errEncountered {
 		return fmt.Errorf("failed to execute benchmarks, see log for details")
 	}
 	return nil
 }
 
+func (c *runCmd) checkMachine() error {
+	if c.waitIdle {
+		err := machine.WaitForIdle(idleMaxLoad, 30*time.Second, c.idleTimeout, func(avg float64) {
+			log.Printf("Load average is %.2f, waiting for it to drop below %.2f...", avg, idleMaxLoad)
+		})
+		if err != nil {
+			return fmt.Errorf("waiting for idle: %w", err)
+		}
+	}
+	c.runCfg.machine = machine.Snapshot()
+	for _, line := range c.runCfg.machine.Lines() {
+		log.Printf("Machine %s", line)
+	}
+	if err := c.runCfg.machine.CheckGovernor(); err != nil {
+		switch c.governor {
+		case machine.GovernorRefuse:
+			return fmt.Errorf("%w (-governor=refuse)", err)
+		case machine.GovernorWarn:
+			log.Printf("warning: %v; results may be noisy", err)
+		}
+	}
+	return nil
+}
+
+// pgoReportTopN is the number of hottest functions compared between
+// the profiles of successive PGO rounds.
+const pgoReportTopN = 20
+
 func (c *runCmd) preparePGO(configs []*common.Config, benchmarks []*benchmark) ([]*common.Config, error) {
-	profileConfigs := make([]*common.Config, 0, len(configs))
-	for _, c := range configs {
+	newConfigs := configs
+	prevConfigs := configs
+	for round := 1; round <= c.pgoRounds; round++ {
+		pgoConfigs, err := c.pgoRound(round, configs, prevConfigs, benchmarks)
+		if err != nil {
+			return nil, err
+		}
+		if round > 1 {
+			err := common.Do(fmt.Sprintf("# report the changes in the profiles of PGO round %d", round), func() error {
+				return c.reportPGORound(prevConfigs, pgoConfigs, benchmarks)
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		newConfigs = append(newConfigs, pgoConfigs...)
+		prevConfigs = pgoConfigs
+	}
+	return newConfigs, nil
+}
+
+// pgoConfigName returns the name of the config for PGO round round
+// derived from the config called name.
+func (c *runCmd) pgoConfigName(name string, round int) string {
+	if c.pgoRounds == 1 {
+		return name + ".pgo"
+	}
+	return fmt.Sprintf("%s.pgo%d", name, round)
+}
+
+// pgoRound collects profiles from runs of profileSrcs, and returns a new
+// PGO config for each of configs using those profiles. profileSrcs must
+// be configs or derived from them, index for index.
+func (c *runCmd) pgoRound(round int, configs, profileSrcs []*common.Config, benchmarks []*benchmark) ([]*common.Config, error) {
+	profileConfigs := make([]*common.Config, 0, len(profileSrcs))
+	for _, c := range profileSrcs {
 		cc := c.Copy()
 		cc.Name += ".profile"
 		cc.Diagnostics.Set(diagnostics.Config{Type: diagnostics.CPUProfile})
@@ -406,8 +453,15 @@ func (c *runCmd) preparePGO(configs []*common.Config, benchmarks []*benchmark) (
 
 	profileRunCfg := c.runCfg
 	profileRunCfg.count = profileRunCfg.pgoCount
+	// Profiled runs aren't representative of normal runs, so keep them
+	// out of the durations used for estimates.
+	profileRunCfg.durations = nil
 
-	log.Printf("Running profile collection runs")
+	if c.pgoRounds == 1 {
+		log.Printf("Running profile collection runs")
+	} else {
+		log.Printf("Running profile collection runs (PGO round %d of %d)", round, c.pgoRounds)
+	}
 
 	// Execute benchmarks to collect profiles.
 	var errEncountered bool
@@ -425,58 +479,307 @@ func (c *runCmd) preparePGO(configs []*common.Config, benchmarks []*benchmark) (
 	}
 
 	// Merge all the profiles and add new PGO configs.
-	newConfigs := configs
+	pgoConfigs := make([]*common.Config, 0, len(configs))
 	for i := range configs {
 		origConfig := configs[i]
 		profileConfig := profileConfigs[i]
 		pgoConfig := origConfig.Copy()
-		pgoConfig.Name += ".pgo"
+		pgoConfig.Name = c.pgoConfigName(origConfig.Name, round)
 		pgoConfig.PGOFiles = make(map[string]string)
 
 		for _, b := range benchmarks {
-			p, err := mergeCPUProfiles(profileRunCfg.runProfilesDir(b, profileConfig))
+			dir := profileRunCfg.runProfilesDir(b, profileConfig)
+			// In a dry run, there are no profiles to merge, so the
+			// profile merged for the benchmark as a whole stands in
+			// for them.
+			merged := map[string]string{"": filepath.Join(dir, "merged.cpu")}
+			err := common.Do(fmt.Sprintf("# merge the CPU profiles in %s", dir), func() (err error) {
+				merged, err = mergeCPUProfiles(dir)
+				return err
+			})
 			if err != nil {
 				return nil, fmt.Errorf("error merging profiles for %s/%s: %w", b.name, profileConfig.Name, err)
 			}
-			pgoConfig.PGOFiles[b.name] = p
+			for bin, p := range merged {
+				pgoConfig.PGOFiles[pgoFilesKey(b, bin)] = p
+			}
 		}
 
-		newConfigs = append(newConfigs, pgoConfig)
+		pgoConfigs = append(pgoConfigs, pgoConfig)
 	}
+	return pgoConfigs, nil
+}
 
-	return newConfigs, nil
+// pgoFilesKey returns the key in Config.PGOFiles for the profile for the
+// binary called bin built for b, or for all of b's binaries if bin is empty.
+func pgoFilesKey(b *benchmark, bin string) string {
+	if bin == "" {
+		return b.name
+	}
+	return b.name + "/" + bin
+}
+
+// reportPGORound reports how much the profiles used by each of pgoConfigs
+// changed from those used by the corresponding prevConfigs, in terms of
+// the overlap between their hottest functions. The report is logged and
+// appended to pgo-rounds.txt in each benchmark's results directory.
+func (c *runCmd) reportPGORound(prevConfigs, pgoConfigs []*common.Config, benchmarks []*benchmark) error {
+	for _, b := range benchmarks {
+		resultsDir := c.runCfg.benchmarkResultsDir(b)
+		if err := mkdirAll(resultsDir); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(filepath.Join(resultsDir, "pgo-rounds.txt"), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		for i, cfg := range pgoConfigs {
+			prev := prevConfigs[i]
+			prevFiles := prev.BenchmarkPGOFiles(b.name)
+			files := cfg.BenchmarkPGOFiles(b.name)
+			bins := make([]string, 0, len(files))
+			for bin := range files {
+				bins = append(bins, bin)
+			}
+			sort.Strings(bins)
+			for _, bin := range bins {
+				prevFile, ok := prevFiles[bin]
+				if !ok {
+					continue
+				}
+				overlap, err := topFunctionsOverlap(prevFile, files[bin], pgoReportTopN)
+				if err != nil {
+					f.Close()
+					return fmt.Errorf("comparing profiles for %s/%s: %w", b.name, cfg.Name, err)
+				}
+				line := fmt.Sprintf("%s: %s -> %s: ", pgoFilesKey(b, bin), prev.Name, cfg.Name)
+				if overlap.n == 0 {
+					line += "no samples"
+				} else {
+					line += fmt.Sprintf("top-%d function overlap %d/%d (%.0f%%)",
+						pgoReportTopN, overlap.common, overlap.n, overlap.percent())
+				}
+				log.Printf("%s", line)
+				fmt.Fprintln(f, line)
+			}
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// functionOverlap is the number of functions common to two lists of
+// hot functions, out of n.
+type functionOverlap struct {
+	common, n int
 }
 
-var cpuProfileRe = regexp.MustCompile(`^.*\.cpuprofile[0-9]+$`)
+func (o functionOverlap) percent() float64 {
+	return 100 * float64(o.common) / float64(o.n)
+}
 
-func mergeCPUProfiles(dir string) (string, error) {
-	profiles, err := sprofile.ReadDirPprof(dir, func(name string) bool {
-		return cpuProfileRe.FindString(name) != ""
-	})
+// topFunctionsOverlap computes the overlap between the top n functions
+// in the profiles at paths a and b.
+func topFunctionsOverlap(a, b string, n int) (functionOverlap, error) {
+	pa, err := sprofile.ReadPprof(a)
 	if err != nil {
-		return "", fmt.Errorf("error reading dir %q: %w", dir, err)
+		return functionOverlap{}, err
+	}
+	pb, err := sprofile.ReadPprof(b)
+	if err != nil {
+		return functionOverlap{}, err
+	}
+	topA := sprofile.TopFunctions(pa, n)
+	topB := sprofile.TopFunctions(pb, n)
+	inA := make(map[string]bool, len(topA))
+	for _, fn := range topA {
+		inA[fn] = true
 	}
-	if len(profiles) == 0 {
-		return "", fmt.Errorf("no profiles found in %q", dir)
+	o := functionOverlap{n: len(topA)}
+	if len(topB) > o.n {
+		o.n = len(topB)
 	}
+	for _, fn := range topB {
+		if inA[fn] {
+			o.common++
+		}
+	}
+	return o, nil
+}
 
-	p, err := profile.Merge(profiles)
+// mergeCPUProfiles merges the CPU profiles in dir for each binary they
+// were collected from (see diagnostics.ProcessPattern), and returns the
+// paths of the merged profiles keyed by binary name. Profiles that don't
+// identify a binary are merged under the empty name.
+func mergeCPUProfiles(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
 	if err != nil {
-		return "", fmt.Errorf("error merging profiles: %w", err)
+		return nil, fmt.Errorf("error reading dir %q: %w", dir, err)
 	}
+	bins := make(map[string]bool)
+	for _, entry := range entries {
+		if d, ok := diagnostics.ParseDataFile(entry.Name()); ok && d.Type == diagnostics.CPUProfile {
+			bins[d.Process] = true
+		}
+	}
+	merged := make(map[string]string)
+	for bin := range bins {
+		bin := bin
+		profiles, err := sprofile.ReadDirPprof(dir, func(name string) bool {
+			d, ok := diagnostics.ParseDataFile(name)
+			return ok && d.Type == diagnostics.CPUProfile && d.Process == bin
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error reading dir %q: %w", dir, err)
+		}
+		if len(profiles) == 0 {
+			// All the profiles for bin were empty.
+			continue
+		}
+		p, err := profile.Merge(profiles)
+		if err != nil {
+			return nil, fmt.Errorf("error merging profiles: %w", err)
+		}
+		name := "merged.cpu"
+		if bin != "" {
+			name = fmt.Sprintf("merged.%s.cpu", bin)
+		}
+		out := filepath.Join(dir, name)
+		if err := writeProfile(out, p); err != nil {
+			return nil, err
+		}
+		merged[bin] = out
+	}
+	if len(merged) == 0 {
+		return nil, fmt.Errorf("no profiles found in %q", dir)
+	}
+	return merged, nil
+}
 
-	out := filepath.Join(dir, "merged.cpu")
-	f, err := os.Create(out)
+func writeProfile(path string, p *profile.Profile) error {
+	f, err := os.Create(path)
 	if err != nil {
-		return "", fmt.Errorf("error creating file: %w", err)
+		return fmt.Errorf("error creating file: %w", err)
 	}
 	defer f.Close()
 
 	if err := p.Write(f); err != nil {
-		return "", fmt.Errorf("error writing merged profile: %w", err)
+		return fmt.Errorf("error writing merged profile: %w", err)
 	}
+	return nil
+}
 
-	return out, nil
+// setUp prepares the directories and assets for running benchmarks,
+// validating the user-provided paths. The returned cleanup function must be
+// called once benchmarks are no longer being run.
+func (r *runCfg) setUp() (cleanup func(), err error) {
+	var assets *os.File
+	defer func() {
+		if err != nil && assets != nil {
+			assets.Close()
+		}
+	}()
+	if r.workDir == "" {
+		// Create a temporary work tree for running the benchmarks.
+		r.workDir, err = os.MkdirTemp("", "gosweet")
+		if err != nil {
+			return nil, fmt.Errorf("creating work root: %w", err)
+		}
+	}
+	// Ensure all provided directories are absolute paths. This avoids problems with
+	// benchmarks potentially changing their current working directory.
+	r.workDir, err = filepath.Abs(r.workDir)
+	if err != nil {
+		return nil, fmt.Errorf("creating absolute path from provided work root (-work-dir): %w", err)
+	}
+	r.benchDir, err = filepath.Abs(r.benchDir)
+	if err != nil {
+		return nil, fmt.Errorf("creating absolute path from benchmarks path (-bench-dir): %w", err)
+	}
+	r.resultsDir, err = filepath.Abs(r.resultsDir)
+	if err != nil {
+		return nil, fmt.Errorf("creating absolute path from results path (-results): %w", err)
+	}
+	if r.assetsDir != "" {
+		r.assetsDir, err = filepath.Abs(r.assetsDir)
+		if err != nil {
+			return nil, fmt.Errorf("creating absolute path from assets path (-assets-dir): %w", err)
+		}
+		if info, err := os.Stat(r.assetsDir); os.IsNotExist(err) {
+			return nil, fmt.Errorf("assets not found at %q: did you forget to run `sweet get`?", r.assetsDir)
+		} else if err != nil {
+			return nil, fmt.Errorf("stat assets %q: %v", r.assetsDir, err)
+		} else if info.Mode()&os.ModeDir == 0 {
+			return nil, fmt.Errorf("%q is not a directory", r.assetsDir)
+		}
+		r.assetsFS = os.DirFS(r.assetsDir)
+	} else {
+		if r.assetsCache == "" {
+			return nil, fmt.Errorf("missing assets cache (-cache) and assets directory (-assets-dir): cannot proceed without assets")
+		}
+		r.assetsCache, err = filepath.Abs(r.assetsCache)
+		if err != nil {
+			return nil, fmt.Errorf("creating absolute path from assets cache path (-cache): %w", err)
+		}
+		if info, err := os.Stat(r.assetsCache); os.IsNotExist(err) {
+			return nil, fmt.Errorf("assets not found at %q (-assets-dir): did you forget to run `sweet get`?", r.assetsDir)
+		} else if err != nil {
+			return nil, fmt.Errorf("stat assets %q: %v", r.assetsDir, err)
+		} else if info.Mode()&os.ModeDir == 0 {
+			return nil, fmt.Errorf("%q (-assets-dir) is not a directory", r.assetsDir)
+		}
+		assetsFile, err := bootstrap.CachedAssets(r.assetsCache, common.Version)
+		if err == bootstrap.ErrNotInCache {
+			return nil, fmt.Errorf("assets for version %q not found in %q", common.Version, r.assetsCache)
+		} else if err != nil {
+			return nil, err
+		}
+		assets, err = os.Open(assetsFile)
+		if err != nil {
+			return nil, err
+		}
+		fi, err := assets.Stat()
+		if err != nil {
+			return nil, err
+		}
+		r.assetsFS, err = zip.NewReader(assets, fi.Size())
+		if err != nil {
+			return nil, err
+		}
+	}
+	r.manifest, err = bootstrap.ReadManifestFS(r.assetsFS)
+	if errors.Is(err, fs.ErrNotExist) {
+		r.manifest = nil
+	} else if err != nil {
+		return nil, err
+	}
+	// Validate r.benchDir and provide helpful error messages.
+	if fi, err := os.Stat(r.benchDir); errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("benchmarks directory (-bench-dir) does not exist; did you mean to run this command from x/benchmarks/sweet?")
+	} else if err != nil {
+		return nil, fmt.Errorf("checking benchmarks directory (-bench-dir): %w", err)
+	} else {
+		if !fi.IsDir() {
+			return nil, fmt.Errorf("-bench-dir is not a directory; did you mean to run this command from x/benchmarks/sweet?")
+		}
+		var missing []string
+		for _, b := range allBenchmarks {
+			fi, err := os.Stat(filepath.Join(r.benchDir, b.name))
+			if err != nil || !fi.IsDir() {
+				missing = append(missing, b.name)
+			}
+		}
+		if len(missing) != 0 {
+			return nil, fmt.Errorf("benchmarks directory (-bench-dir) is missing benchmarks (%s); did you mean to run this command from x/benchmarks/sweet?", strings.Join(missing, ", "))
+		}
+	}
+	return func() {
+		if assets != nil {
+			assets.Close()
+		}
+	}, nil
 }
 
 func canonicalizePath(path, base string) string {
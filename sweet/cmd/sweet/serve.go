@@ -0,0 +1,68 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"golang.org/x/benchmarks/sweet/cli/bootstrap"
+	"golang.org/x/benchmarks/sweet/common/log"
+)
+
+const (
+	serveAssetsUsage = `Serves the assets archives in an assets cache over HTTP, so that other
+machines may retrieve them with 'sweet get -source=http://<addr>'.
+
+Populate the cache first with 'sweet get'.
+
+Usage: %s serve-assets [flags]
+`
+)
+
+type serveAssetsCmd struct {
+	cache string
+	addr  string
+}
+
+func (*serveAssetsCmd) Name() string { return "serve-assets" }
+func (*serveAssetsCmd) Synopsis() string {
+	return "Serves an assets cache to other machines."
+}
+func (*serveAssetsCmd) PrintUsage(w io.Writer, base string) {
+	fmt.Fprintf(w, serveAssetsUsage, base)
+}
+
+func (c *serveAssetsCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&c.cache, "cache", bootstrap.CacheDefault(), "cache location for assets")
+	f.StringVar(&c.addr, "addr", ":8080", "address to listen on")
+}
+
+func (c *serveAssetsCmd) Run(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("unexpected arguments")
+	}
+	log.SetActivityLog(true)
+
+	entries, err := os.ReadDir(c.cache)
+	if err != nil {
+		return fmt.Errorf("reading assets cache: %w", err)
+	}
+	var found bool
+	for _, e := range entries {
+		if bootstrap.IsArchiveName(e.Name()) {
+			log.Printf("Serving %s", e.Name())
+			found = true
+		}
+	}
+	if !found {
+		log.Printf("warning: no assets archives found in %s; did you forget to run `sweet get`?", c.cache)
+	}
+	log.Printf("Listening on %s", c.addr)
+	return http.ListenAndServe(c.addr, bootstrap.NewCacheHandler(c.cache))
+}
@@ -0,0 +1,243 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/benchmarks/stats"
+	"golang.org/x/benchmarks/sweet/common"
+	"golang.org/x/benchmarks/sweet/common/log"
+	"golang.org/x/benchmarks/sweet/common/results"
+)
+
+// minOutlierRuns is the minimum number of runs among which outliers are
+// looked for.
+const minOutlierRuns = 5
+
+// minOutlierDeviation is the minimum deviation from the median, relative
+// to the median, of an outlier worth noting. Some metrics, such as memory
+// use, are so consistent that tiny deviations are outliers.
+const minOutlierDeviation = 0.01
+
+// outliers returns the indices of the outliers among vals. If vals are
+// bimodal, there are none: the points in the smaller mode aren't outliers,
+// and rerunning them wouldn't help.
+func outliers(vals []float64) []int {
+	if len(vals) < minOutlierRuns || stats.FitBimodal(vals).Bimodal {
+		return nil
+	}
+	m := stats.Median(vals)
+	var out []int
+	for _, i := range stats.MADOutliers(vals, 0) {
+		if math.Abs(vals[i]-m) >= minOutlierDeviation*math.Abs(m) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// runSegment is the part of a results file written by one run of a
+// benchmark, as offsets into the file.
+type runSegment struct {
+	start, end int64
+}
+
+// stabilityKey identifies the values of one metric of one benchmark result
+// across runs.
+type stabilityKey struct {
+	name, unit string
+}
+
+// runValues maps each metric to its value in each run, as the median of
+// the run's values if it has several.
+type runValues map[stabilityKey][]float64
+
+// parseRuns parses the results of each run from the results file contents
+// in data.
+func parseRuns(data []byte, runs []runSegment) (runValues, error) {
+	values := make(runValues)
+	for j, seg := range runs {
+		rs, err := results.Parse(bytes.NewReader(data[seg.start:seg.end]))
+		if err != nil {
+			return nil, fmt.Errorf("run %d: %w", j+1, err)
+		}
+		run := make(map[stabilityKey][]float64)
+		for _, r := range rs {
+			for _, v := range r.Values {
+				k := stabilityKey{r.Name, v.Unit}
+				run[k] = append(run[k], v.Value)
+			}
+		}
+		for k, vs := range run {
+			if values[k] == nil {
+				values[k] = make([]float64, len(runs))
+				for i := range values[k] {
+					values[k][i] = math.NaN()
+				}
+			}
+			values[k][j] = stats.Median(vs)
+		}
+	}
+	return values, nil
+}
+
+// present returns the values of the runs that produced the metric, and the
+// indices of those runs.
+func present(vs []float64) (values []float64, runs []int) {
+	for j, v := range vs {
+		if !math.IsNaN(v) {
+			values = append(values, v)
+			runs = append(runs, j)
+		}
+	}
+	return values, runs
+}
+
+// outlierRuns returns the runs with an outlying value of any metric.
+func (v runValues) outlierRuns() map[int][]stabilityKey {
+	out := make(map[int][]stabilityKey)
+	for k, vs := range v {
+		values, runs := present(vs)
+		for _, i := range outliers(values) {
+			out[runs[i]] = append(out[runs[i]], k)
+		}
+	}
+	return out
+}
+
+// checkStability looks for outliers and bimodality among the results of
+// the runs of b for cfg, which were written to f. If -rerun-outliers is
+// set, runs with outliers are rerun with rerun, up to that many times, and
+// their results are replaced in f. Any remaining problems are logged and
+// noted at the end of f, in lines starting with "# unstable:", which are
+// ignored by tools that read results.
+func (r *runCfg) checkStability(b *benchmark, cfg *common.Config, f *os.File, runs []runSegment, rerun func(j int) (runSegment, error)) error {
+	if len(runs) == 0 {
+		return nil
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return err
+	}
+	values, err := parseRuns(data, runs)
+	if err != nil {
+		return err
+	}
+
+	var notes []string
+	if r.rerunOutliers > 0 {
+		outliers := values.outlierRuns()
+		redo := make([]int, 0, len(outliers))
+		for j := range outliers {
+			redo = append(redo, j)
+		}
+		sort.Ints(redo)
+		if len(redo) > r.rerunOutliers {
+			redo = redo[:r.rerunOutliers]
+		}
+		if len(redo) > 0 {
+			replaced := append([]runSegment(nil), runs...)
+			for _, j := range redo {
+				log.Printf("Rerunning run %d of %s for %s, which had outliers", j+1, b.name, cfg.Name)
+				seg, err := rerun(j)
+				if err != nil {
+					return err
+				}
+				replaced[j] = seg
+				notes = append(notes, fmt.Sprintf("# rerun: run %d, which had outliers in %s", j+1, describeKeys(outliers[j])))
+			}
+			if runs, err = rewriteRuns(f, runs, replaced); err != nil {
+				return err
+			}
+			if data, err = os.ReadFile(f.Name()); err != nil {
+				return err
+			}
+			if values, err = parseRuns(data, runs); err != nil {
+				return err
+			}
+		}
+	}
+
+	keys := make([]stabilityKey, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].name != keys[j].name {
+			return keys[i].name < keys[j].name
+		}
+		return keys[i].unit < keys[j].unit
+	})
+	for _, k := range keys {
+		vals, runs := present(values[k])
+		var problems []string
+		if out := outliers(vals); len(out) > 0 {
+			var desc []string
+			for _, i := range out {
+				desc = append(desc, fmt.Sprintf("run %d (%.4g)", runs[i]+1, vals[i]))
+			}
+			problems = append(problems, fmt.Sprintf("outliers in %s, median %.4g", strings.Join(desc, ", "), stats.Median(vals)))
+		}
+		if fit := stats.FitBimodal(vals); fit.Bimodal {
+			problems = append(problems, fmt.Sprintf("bimodal, with modes at %.4g (%.0f%%) and %.4g (%.0f%%)",
+				fit.Means[0], 100*fit.Weights[0], fit.Means[1], 100*fit.Weights[1]))
+		}
+		for _, p := range problems {
+			log.Printf("warning: %s %s for %s is unstable: %s", k.name, k.unit, cfg.Name, p)
+			notes = append(notes, fmt.Sprintf("# unstable: Benchmark%s %s: %s", k.name, k.unit, p))
+		}
+	}
+	if len(notes) == 0 {
+		return nil
+	}
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "%s\n", strings.Join(notes, "\n"))
+	return err
+}
+
+// rewriteRuns rewrites f so that the results of each run are those in the
+// replaced segments of it, in order, after the header before the first of
+// the original runs, and returns the new segments.
+func rewriteRuns(f *os.File, runs, replaced []runSegment) ([]runSegment, error) {
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return nil, err
+	}
+	out := append([]byte(nil), data[:runs[0].start]...)
+	segs := make([]runSegment, len(replaced))
+	for j, seg := range replaced {
+		start := int64(len(out))
+		out = append(out, data[seg.start:seg.end]...)
+		segs[j] = runSegment{start, int64(len(out))}
+	}
+	if err := f.Truncate(0); err != nil {
+		return nil, err
+	}
+	if _, err := f.WriteAt(out, 0); err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		return nil, err
+	}
+	return segs, nil
+}
+
+func describeKeys(keys []stabilityKey) string {
+	s := make([]string, len(keys))
+	for i, k := range keys {
+		s[i] = k.name + " " + k.unit
+	}
+	sort.Strings(s)
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/benchmarks/sweet/common"
+)
+
+// writeRuns writes a results file with a run for each value, and returns
+// it and the segments of the runs.
+func writeRuns(t *testing.T, values []float64) (*os.File, []runSegment) {
+	t.Helper()
+	f, err := os.Create(filepath.Join(t.TempDir(), "base.results"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	fmt.Fprintf(f, "goos: linux\ntoolchain: base\n")
+	var runs []runSegment
+	for _, v := range values {
+		runs = append(runs, appendRun(t, f, v))
+	}
+	return f, runs
+}
+
+func appendRun(t *testing.T, f *os.File, v float64) runSegment {
+	t.Helper()
+	start, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintf(f, "some output\nBenchmarkFoo 1 %v ns/op 1000 peak-RSS-bytes\n", v)
+	end, _ := f.Seek(0, io.SeekEnd)
+	return runSegment{start, end}
+}
+
+func TestCheckStabilityRerun(t *testing.T) {
+	f, runs := writeRuns(t, []float64{100, 101, 99, 250, 100, 102})
+	r := &runCfg{rerunOutliers: 1}
+	var reran []int
+	rerun := func(j int) (runSegment, error) {
+		reran = append(reran, j)
+		return appendRun(t, f, 98), nil
+	}
+	if err := r.checkStability(&benchmark{name: "foo"}, &common.Config{Name: "base"}, f, runs, rerun); err != nil {
+		t.Fatal(err)
+	}
+	if len(reran) != 1 || reran[0] != 3 {
+		t.Errorf("reran runs %v, expected [3]", reran)
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `goos: linux
+toolchain: base
+some output
+BenchmarkFoo 1 100 ns/op 1000 peak-RSS-bytes
+some output
+BenchmarkFoo 1 101 ns/op 1000 peak-RSS-bytes
+some output
+BenchmarkFoo 1 99 ns/op 1000 peak-RSS-bytes
+some output
+BenchmarkFoo 1 98 ns/op 1000 peak-RSS-bytes
+some output
+BenchmarkFoo 1 100 ns/op 1000 peak-RSS-bytes
+some output
+BenchmarkFoo 1 102 ns/op 1000 peak-RSS-bytes
+# rerun: run 4, which had outliers in Foo ns/op
+`
+	if string(data) != want {
+		t.Errorf("results after rerun:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestCheckStabilityAnnotate(t *testing.T) {
+	var bimodal []float64
+	for i := 0; i < 8; i++ {
+		bimodal = append(bimodal, 100+float64(i%3), 130+float64(i%3))
+	}
+	for _, test := range []struct {
+		name   string
+		values []float64
+		note   string
+	}{
+		{"outlier", []float64{100, 101, 99, 250, 100, 102}, "# unstable: BenchmarkFoo ns/op: outliers in run 4 (250), median 100.5"},
+		{"bimodal", bimodal, "# unstable: BenchmarkFoo ns/op: bimodal, with modes at 100.9 (50%) and 130.9 (50%)"},
+		{"stable", []float64{100, 101, 99, 100.5, 100, 102}, ""},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			f, runs := writeRuns(t, test.values)
+			rerun := func(j int) (runSegment, error) {
+				t.Fatalf("unexpected rerun of run %d", j)
+				return runSegment{}, nil
+			}
+			if err := new(runCfg).checkStability(&benchmark{name: "foo"}, &common.Config{Name: "base"}, f, runs, rerun); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(f.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var notes []string
+			for _, line := range strings.Split(string(data), "\n") {
+				if strings.HasPrefix(line, "#") {
+					notes = append(notes, line)
+				}
+			}
+			if got := strings.Join(notes, "\n"); got != test.note {
+				t.Errorf("got notes:\n%s\nexpected:\n%s", got, test.note)
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/benchmarks/sweet/common/log"
+)
+
+const (
+	submitUsage = `Submits a job to run benchmarks to a 'sweet agent'.
+
+The job consists of the given configs and the benchmarks selected with
+-run. Each config's goroot must either be a path on the agent's machine,
+or be uploaded with -toolchain, in which case the goroot in the config is
+ignored.
+
+Unless -detach is set, the job's progress is printed as it runs, and its
+results archive is downloaded once it finishes (see 'sweet unpack').
+
+Usage: %s submit [flags] <config> [configs...]
+`
+)
+
+// toolchainFlag maps config names to toolchains, as name=path.
+type toolchainFlag map[string]string
+
+func (t toolchainFlag) String() string {
+	var s []string
+	for name, path := range t {
+		s = append(s, name+"="+path)
+	}
+	return strings.Join(s, ",")
+}
+
+func (t toolchainFlag) Set(input string) error {
+	name, path, ok := strings.Cut(input, "=")
+	if !ok || name == "" || path == "" {
+		return fmt.Errorf("expected <config>=<path>")
+	}
+	t[name] = path
+	return nil
+}
+
+type submitCmd struct {
+	agent      string
+	out        string
+	detach     bool
+	toRun      csvFlag
+	count      int
+	short      bool
+	pgo        bool
+	toolchains toolchainFlag
+}
+
+func (*submitCmd) Name() string { return "submit" }
+func (*submitCmd) Synopsis() string {
+	return "Submits a job to run benchmarks to a 'sweet agent'."
+}
+func (*submitCmd) PrintUsage(w io.Writer, base string) {
+	fmt.Fprintf(w, submitUsage, base)
+}
+
+func (c *submitCmd) SetFlags(f *flag.FlagSet) {
+	c.toolchains = make(toolchainFlag)
+	f.StringVar(&c.agent, "agent", "http://localhost:8088", "URL of the agent")
+	f.StringVar(&c.out, "o", "results.tar.gz", "file to write the results archive to")
+	f.BoolVar(&c.detach, "detach", false, "print the job's ID and exit once it's submitted")
+	f.Var(&c.toRun, "run", "benchmark group or comma-separated list of benchmarks to run")
+	f.IntVar(&c.count, "count", 0, fmt.Sprintf("the number of times to run each benchmark (default %d)", countDefault))
+	f.BoolVar(&c.short, "short", false, "whether to run a short version of the benchmarks for testing")
+	f.BoolVar(&c.pgo, "pgo", false, "perform PGO testing (see 'sweet run -pgo')")
+	f.Var(c.toolchains, "toolchain", "upload a toolchain for a config as <config>=<path>, where path is a GOROOT directory or a gzipped tar archive of one, either at its root or in a single top-level directory (may be repeated)")
+}
+
+func (c *submitCmd) Run(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("at least one configuration is required")
+	}
+	log.SetActivityLog(true)
+
+	// Concatenate the configs into a single file, as the TOML tables
+	// simply accumulate.
+	var configs strings.Builder
+	for _, configFile := range args {
+		b, err := os.ReadFile(configFile)
+		if err != nil {
+			return err
+		}
+		configs.Write(b)
+		configs.WriteString("\n")
+	}
+	spec := jobSpec{
+		Configs: configs.String(),
+		Run:     c.toRun,
+		Count:   c.count,
+		Short:   c.short,
+		PGO:     c.pgo,
+	}
+
+	client := &agentClient{url: strings.TrimSuffix(c.agent, "/")}
+	st, err := client.submit(&spec, c.toolchains)
+	if err != nil {
+		return err
+	}
+	if c.detach {
+		fmt.Println(st.ID)
+		return nil
+	}
+	log.Printf("Submitted job %s (%d jobs ahead of it)", st.ID, st.Position)
+	if err := client.follow(st.ID, os.Stderr); err != nil {
+		return err
+	}
+	st, err = client.status(st.ID)
+	if err != nil {
+		return err
+	}
+	if st.State == jobFailed {
+		// There may still be partial results.
+		log.Printf("Job %s failed: %s", st.ID, st.Error)
+	}
+	if err := client.download(st.ID, c.out); err != nil {
+		if st.State == jobFailed {
+			return fmt.Errorf("job failed: %s", st.Error)
+		}
+		return err
+	}
+	log.Printf("Wrote results to %s", c.out)
+	if st.State == jobFailed {
+		return fmt.Errorf("job failed: %s", st.Error)
+	}
+	return nil
+}
+
+// agentClient talks to a 'sweet agent'.
+type agentClient struct {
+	url    string
+	client http.Client
+}
+
+// submit submits a job described by spec, along with the toolchains for
+// configs, which map config names to paths to either GOROOTs or gzipped
+// tar archives of them.
+func (c *agentClient) submit(spec *jobSpec, toolchains map[string]string) (*jobStatus, error) {
+	for _, path := range toolchains {
+		if _, err := os.Stat(path); err != nil {
+			return nil, err
+		}
+	}
+
+	// Stream the upload, since toolchains may be large.
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(writeSubmission(mw, spec, toolchains))
+	}()
+	resp, err := c.client.Post(c.url+"/jobs", mw.FormDataContentType(), pr)
+	pr.Close()
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var st jobStatus
+	if err := decodeResponse(resp, &st); err != nil {
+		return nil, fmt.Errorf("submitting job: %w", err)
+	}
+	return &st, nil
+}
+
+func writeSubmission(mw *multipart.Writer, spec *jobSpec, toolchains map[string]string) error {
+	w, err := mw.CreateFormField(jobSpecPart)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(w).Encode(spec); err != nil {
+		return err
+	}
+	for name, path := range toolchains {
+		w, err := mw.CreateFormFile(jobToolchainPart+name, filepath.Base(path))
+		if err != nil {
+			return err
+		}
+		if err := copyToolchain(w, path); err != nil {
+			return fmt.Errorf("uploading toolchain for %s: %w", name, err)
+		}
+	}
+	return mw.Close()
+}
+
+// copyToolchain writes the toolchain at path to w as a gzipped tar archive.
+// If path is already an archive, it's copied as-is.
+func copyToolchain(w io.Writer, path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(w, f)
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() && d.Type() != fs.ModeSymlink {
+			return nil
+		}
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+		if d.Type() == fs.ModeSymlink {
+			target, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			return tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeSymlink,
+				Name:     filepath.ToSlash(rel),
+				Linkname: filepath.ToSlash(target),
+				Mode:     0777,
+			})
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     filepath.ToSlash(rel),
+			Mode:     int64(fi.Mode().Perm()),
+			Size:     fi.Size(),
+			ModTime:  fi.ModTime(),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// status returns the status of the job called id.
+func (c *agentClient) status(id string) (*jobStatus, error) {
+	resp, err := c.client.Get(c.url + "/jobs/" + url.PathEscape(id))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var st jobStatus
+	if err := decodeResponse(resp, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// follow copies the log of the job called id to w until the job finishes.
+func (c *agentClient) follow(id string, w io.Writer) error {
+	resp, err := c.client.Get(c.url + "/jobs/" + url.PathEscape(id) + "/log")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := decodeResponse(resp, nil); err != nil {
+		return err
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// download writes the results archive of the job called id to out.
+func (c *agentClient) download(id, out string) error {
+	resp, err := c.client.Get(c.url + "/jobs/" + url.PathEscape(id) + "/archive")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := decodeResponse(resp, nil); err != nil {
+		return fmt.Errorf("downloading results: %w", err)
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, resp.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// decodeResponse checks that resp succeeded, and decodes its body as JSON
+// into v, if v is not nil.
+func decodeResponse(resp *http.Response, v interface{}) error {
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("agent returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
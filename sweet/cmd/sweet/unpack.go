@@ -0,0 +1,121 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"golang.org/x/benchmarks/sweet/common"
+	"golang.org/x/benchmarks/sweet/common/log"
+)
+
+const (
+	unpackUsage = `Unpacks a results archive produced by 'sweet run -archive', checks
+its contents, and describes how to compare the results within.
+
+The archive is extracted into the directory given by -o, which must
+not already exist.
+
+Usage: %s unpack [flags] <archive>
+`
+)
+
+type unpackCmd struct {
+	outDir string
+}
+
+func (*unpackCmd) Name() string { return "unpack" }
+func (*unpackCmd) Synopsis() string {
+	return "Unpacks a results archive produced by 'sweet run -archive'."
+}
+func (*unpackCmd) PrintUsage(w io.Writer, base string) {
+	fmt.Fprintf(w, unpackUsage, base)
+}
+
+func (c *unpackCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&c.outDir, "o", "", "directory to unpack into (default: the archive name without its extension)")
+}
+
+func (c *unpackCmd) Run(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly one archive")
+	}
+	log.SetActivityLog(true)
+
+	archive := args[0]
+	if c.outDir == "" {
+		c.outDir = strings.TrimSuffix(strings.TrimSuffix(archive, ".gz"), ".tar")
+		c.outDir = strings.TrimSuffix(c.outDir, ".tgz")
+		if c.outDir == archive {
+			return fmt.Errorf("cannot derive output directory from %q; use -o", archive)
+		}
+	}
+	if _, err := os.Stat(c.outDir); err == nil {
+		return fmt.Errorf("%s already exists", c.outDir)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	log.Printf("Unpacking %s into %s", archive, c.outDir)
+	m, err := extractArchive(f, c.outDir)
+	if err != nil {
+		return fmt.Errorf("unpacking %s: %w", archive, err)
+	}
+	log.Printf("Verified %d files", len(m.Files))
+
+	// Describe the environment the results were produced in.
+	env, err := os.ReadFile(filepath.Join(c.outDir, archiveEnvironment))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Environment:\n%s\n", env)
+
+	// Explain how to compare the results, ordering the results for each
+	// benchmark as the configs were originally ordered.
+	b, err := os.ReadFile(filepath.Join(c.outDir, archiveConfigs))
+	if err != nil {
+		return err
+	}
+	var fconfigs common.ConfigFile
+	if _, err := toml.Decode(string(b), &fconfigs); err != nil {
+		return fmt.Errorf("parsing %s: %w", archiveConfigs, err)
+	}
+	benches, err := os.ReadDir(filepath.Join(c.outDir, archiveResultsDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		fmt.Println("No results found.")
+		return nil
+	} else if err != nil {
+		return err
+	}
+	fmt.Println("To compare results:")
+	for _, bench := range benches {
+		var files []string
+		for _, cfg := range fconfigs.Configs {
+			p := filepath.Join(c.outDir, archiveResultsDir, bench.Name(), cfg.Name+".results")
+			if _, err := os.Stat(p); err == nil {
+				files = append(files, p)
+			}
+		}
+		if len(files) == 0 {
+			continue
+		}
+		fmt.Printf("  benchstat %s\n", strings.Join(files, " "))
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package common
+
+import (
+	"os"
+	"os/exec"
+
+	"golang.org/x/benchmarks/sweet/common/log"
+)
+
+// An Executor performs the steps of an invocation that have side effects:
+// running commands, and operating on files. Each step is traced with the
+// log package as it's performed. All such steps go through the current
+// Executor, so that a dry run can swap in one that only traces them (see
+// SetDryRun).
+type Executor interface {
+	// Run runs cmd, as cmd.Run does.
+	Run(cmd *exec.Cmd) error
+
+	// Output runs cmd and returns its standard output, as cmd.Output
+	// does.
+	Output(cmd *exec.Cmd) ([]byte, error)
+
+	// CombinedOutput runs cmd and returns its standard output and
+	// standard error, as cmd.CombinedOutput does.
+	CombinedOutput(cmd *exec.Cmd) ([]byte, error)
+
+	// Do performs op, which is described by desc, either as a shell
+	// command or, for steps without one, as a shell comment.
+	Do(desc string, op func() error) error
+
+	// Create creates or truncates the file at path for writing.
+	Create(path string) (*os.File, error)
+}
+
+var executor Executor = realExecutor{}
+
+// SetDryRun sets whether steps are only traced, and not performed, by
+// swapping the current Executor. Turning it on also turns on the command
+// trace, which is all there is to a dry run.
+func SetDryRun(on bool) {
+	if on {
+		log.SetCommandTrace(true)
+		executor = dryExecutor{}
+	} else {
+		executor = realExecutor{}
+	}
+}
+
+// RunCommand runs cmd with the current Executor.
+func RunCommand(cmd *exec.Cmd) error {
+	return executor.Run(cmd)
+}
+
+// CommandOutput runs cmd with the current Executor, and returns its
+// standard output. In a dry run, the output is empty.
+func CommandOutput(cmd *exec.Cmd) ([]byte, error) {
+	return executor.Output(cmd)
+}
+
+// CommandCombinedOutput is like CommandOutput, but returns the standard
+// output and standard error of cmd.
+func CommandCombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	return executor.CombinedOutput(cmd)
+}
+
+// Do performs op, described by desc, with the current Executor (see
+// Executor.Do).
+func Do(desc string, op func() error) error {
+	return executor.Do(desc, op)
+}
+
+// CreateFile creates the file at path for writing with the current
+// Executor. In a dry run, writes to the file are discarded.
+func CreateFile(path string) (*os.File, error) {
+	return executor.Create(path)
+}
+
+type realExecutor struct{}
+
+func (realExecutor) Run(cmd *exec.Cmd) error {
+	log.TraceCommand(cmd, false)
+	return cmd.Run()
+}
+
+func (realExecutor) Output(cmd *exec.Cmd) ([]byte, error) {
+	log.TraceCommand(cmd, false)
+	return cmd.Output()
+}
+
+func (realExecutor) CombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	log.TraceCommand(cmd, false)
+	return cmd.CombinedOutput()
+}
+
+func (realExecutor) Do(desc string, op func() error) error {
+	log.CommandPrintf("%s", desc)
+	return op()
+}
+
+func (realExecutor) Create(path string) (*os.File, error) {
+	log.CommandPrintf("# write %s", path)
+	return os.Create(path)
+}
+
+type dryExecutor struct{}
+
+func (dryExecutor) Run(cmd *exec.Cmd) error {
+	log.TraceCommand(cmd, false)
+	return nil
+}
+
+func (dryExecutor) Output(cmd *exec.Cmd) ([]byte, error) {
+	log.TraceCommand(cmd, false)
+	return nil, nil
+}
+
+func (dryExecutor) CombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	log.TraceCommand(cmd, false)
+	return nil, nil
+}
+
+func (dryExecutor) Do(desc string, op func() error) error {
+	log.CommandPrintf("%s", desc)
+	return nil
+}
+
+func (dryExecutor) Create(path string) (*os.File, error) {
+	log.CommandPrintf("# write %s", path)
+	return os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+}
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"golang.org/x/benchmarks/sweet/common/diagnostics"
@@ -23,7 +24,9 @@ called 'config'. Each element of the array consists of the following fields:
       envexec: additional environment variables that should be used for execution
                each variable should take the form "X=Y" (optional)
      pgofiles: a map of benchmark names (see 'sweet help run') to profile files
-               to be passed to the Go compiler for optimization (optional)
+               to be passed to the Go compiler for optimization; a key of the
+               form "<benchmark>/<binary>" applies the profile to just one of
+               the benchmark's binaries (e.g. "etcd/etcd") (optional)
   diagnostics: profile types to collect for each benchmark run of this
                configuration, which may be one of: cpuprofile, memprofile,
                perf[=flags], trace (optional)
@@ -72,6 +75,23 @@ func (c *Config) GoTool() *Go {
 	}
 }
 
+// BenchmarkPGOFiles returns the profiles that the binaries for the
+// benchmark called bench should be built with, keyed by binary name
+// (see BuildConfig.PGOFiles).
+//
+// A PGOFiles entry keyed by the benchmark name applies to all of the
+// benchmark's binaries, while an entry keyed by "<benchmark>/<binary>"
+// applies to just that binary.
+func (c *Config) BenchmarkPGOFiles(bench string) map[string]string {
+	files := make(map[string]string)
+	for key, file := range c.PGOFiles {
+		if b, bin, _ := strings.Cut(key, "/"); b == bench {
+			files[bin] = file
+		}
+	}
+	return files
+}
+
 // Copy returns a deep copy of Config.
 func (c *Config) Copy() *Config {
 	cc := *c
@@ -81,3 +81,29 @@ func makeEnvIndex(a *common.Env) map[string]string {
 	}
 	return index
 }
+
+func TestBenchmarkPGOFiles(t *testing.T) {
+	cfg := &common.Config{PGOFiles: map[string]string{
+		"etcd":                 "default.pgo",
+		"etcd/etcd":            "etcd.pgo",
+		"tile38/tile38-server": "tile38.pgo",
+	}}
+	bcfg := &common.BuildConfig{PGOFiles: cfg.BenchmarkPGOFiles("etcd")}
+	for bin, want := range map[string]string{
+		"etcd":       "etcd.pgo",
+		"etcd-bench": "default.pgo",
+	} {
+		if got := bcfg.PGOFile(bin); got != want {
+			t.Errorf("PGOFile(%q) = %q, want %q", bin, got, want)
+		}
+	}
+	bcfg = &common.BuildConfig{PGOFiles: cfg.BenchmarkPGOFiles("tile38")}
+	for bin, want := range map[string]string{
+		"tile38-server": "tile38.pgo",
+		"tile38-bench":  "off",
+	} {
+		if got := bcfg.PGOFile(bin); got != want {
+			t.Errorf("PGOFile(%q) = %q, want %q", bin, got, want)
+		}
+	}
+}
@@ -7,8 +7,20 @@ package diagnostics
 import (
 	"flag"
 	"fmt"
+	"strconv"
 )
 
+// RunIndexFlag is the name of the flag through which Sweet passes the index
+// of the current run to a benchmark binary, for naming diagnostic data files
+// (see DataFileName).
+const RunIndexFlag = "run-index"
+
+// RunIndexArgs returns the arguments that should be passed to a Sweet
+// benchmark binary to indicate that it's executing the run with index run.
+func RunIndexArgs(run int) []string {
+	return []string{"-" + RunIndexFlag, strconv.Itoa(run)}
+}
+
 // DriverArgs returns the arguments that should be passed to a Sweet benchmark
 // binary to collect data for the Config.
 func (d Config) DriverArgs(resultsDir string) []string {
@@ -0,0 +1,84 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diagnostics
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// ManifestName is the name of the manifest file in a directory of
+// diagnostic data.
+const ManifestName = "manifest.json"
+
+// Manifest describes each diagnostic data file in a directory.
+type Manifest struct {
+	// Files maps the name of each file to a description of its contents.
+	Files map[string]DataFile `json:"files"`
+}
+
+// NewManifest creates a Manifest for the diagnostic data files in dir,
+// which were collected from the Sweet benchmark called benchmark. Files
+// whose names were not produced by DataFileName are ignored.
+func NewManifest(dir, benchmark string) (*Manifest, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{Files: make(map[string]DataFile)}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		d, ok := ParseDataFile(entry.Name())
+		if !ok {
+			continue
+		}
+		d.Benchmark = benchmark
+		m.Files[entry.Name()] = d
+	}
+	return m, nil
+}
+
+// RemoveRun removes the diagnostic data files in dir collected during run
+// index run, for example so that the run can be repeated without its
+// data being attributed to both runs.
+func RemoveRun(dir string, run int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if d, ok := ParseDataFile(entry.Name()); ok && d.Run == run {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ReadManifest reads the manifest in dir.
+func ReadManifest(dir string) (*Manifest, error) {
+	b, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if err != nil {
+		return nil, err
+	}
+	m := new(Manifest)
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Write writes the manifest into dir.
+func (m *Manifest) Write(dir string) error {
+	b, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ManifestName), append(b, '\n'), 0644)
+}
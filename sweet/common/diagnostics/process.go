@@ -0,0 +1,118 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diagnostics
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ProcessPattern returns a pattern for naming diagnostic data files
+// for the benchmark run called name, tagging them as having been collected
+// from a process running the binary called bin. bin should be the base
+// name of the binary, as built by the benchmark's harness.
+//
+// Tagging data in this way allows Sweet to attribute diagnostic data to
+// binaries, for example to build each binary with its own PGO profile.
+func ProcessPattern(name, bin string) string {
+	return name + "@" + bin
+}
+
+// DataFileName returns the name of the file containing the seq'th piece
+// of diagnostic data of type typ collected during run index run, for the
+// given pattern (see ProcessPattern). The pattern is escaped, since
+// benchmark names may contain slashes.
+func DataFileName(pattern string, run, seq int, typ Type) string {
+	return fmt.Sprintf("%s.run%d.%d.%s", url.PathEscape(pattern), run, seq, typ)
+}
+
+// DataFile describes a file containing diagnostic data.
+type DataFile struct {
+	// Benchmark is the name of the Sweet benchmark the data was
+	// collected from. It is not encoded in the file name, since data
+	// for each benchmark is kept in its own directory.
+	Benchmark string `json:"benchmark,omitempty"`
+
+	// SubBenchmark is the name of the benchmark run within Benchmark,
+	// as it appears in the results.
+	SubBenchmark string `json:"subBenchmark"`
+
+	// Run is the index of the run the data was collected in.
+	Run int `json:"run"`
+
+	// Seq orders multiple pieces of data collected from the same
+	// process in the same run, for example when diagnostic data is
+	// collected from a server periodically.
+	Seq int `json:"seq"`
+
+	// Process is the base name of the binary the data was collected
+	// from, or empty if unknown.
+	Process string `json:"process,omitempty"`
+
+	// Type is the type of diagnostic data.
+	Type Type `json:"type"`
+}
+
+// ParseDataFile parses a file name produced by DataFileName. The returned
+// DataFile's Benchmark is always empty. ok is false if file is not the
+// name of a diagnostic data file.
+func ParseDataFile(file string) (d DataFile, ok bool) {
+	rest, typ, ok := cutLast(file, ".")
+	if !ok {
+		return DataFile{}, false
+	}
+	d.Type = Type(typ)
+	if !isType(d.Type) {
+		return DataFile{}, false
+	}
+	rest, seq, ok := cutLast(rest, ".")
+	if !ok {
+		return DataFile{}, false
+	}
+	rest, run, ok := cutLast(rest, ".run")
+	if !ok {
+		return DataFile{}, false
+	}
+	var err error
+	if d.Seq, err = strconv.Atoi(seq); err != nil || d.Seq < 0 {
+		return DataFile{}, false
+	}
+	if d.Run, err = strconv.Atoi(run); err != nil || d.Run < 0 {
+		return DataFile{}, false
+	}
+	if d.SubBenchmark, err = url.PathUnescape(rest); err != nil {
+		return DataFile{}, false
+	}
+	if sub, proc, ok := cutLast(d.SubBenchmark, "@"); ok {
+		d.SubBenchmark, d.Process = sub, proc
+	}
+	return d, true
+}
+
+// DataProcess returns the name of the binary that the diagnostic data in
+// the file called file was collected from, as tagged by ProcessPattern,
+// or an empty string if the file isn't tagged.
+func DataProcess(file string) string {
+	d, _ := ParseDataFile(file)
+	return d.Process
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+func isType(t Type) bool {
+	for _, typ := range Types() {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diagnostics
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDataProcess(t *testing.T) {
+	for _, test := range []struct {
+		file, want string
+	}{
+		{"Tile38QueryLoad@tile38-server.run0.3.cpuprofile", "tile38-server"},
+		{DataFileName(ProcessPattern("EtcdPut", "etcd"), 2, 0, MemProfile), "etcd"},
+		{"GoBuildKubelet@compile.run1.0.trace", "compile"},
+		{"MarkdownRenderXHTML.run0.0.cpuprofile", ""},
+		{"merged.cpu", ""},
+		{"Foo@bar", ""},
+	} {
+		if got := DataProcess(test.file); got != test.want {
+			t.Errorf("DataProcess(%q) = %q, want %q", test.file, got, test.want)
+		}
+	}
+}
+
+func TestParseDataFile(t *testing.T) {
+	for _, test := range []struct {
+		file string
+		want DataFile
+		ok   bool
+	}{
+		{
+			file: DataFileName(ProcessPattern("GoBuildKubeletLink", "link"), 4, 1, CPUProfile),
+			want: DataFile{SubBenchmark: "GoBuildKubeletLink", Run: 4, Seq: 1, Process: "link", Type: CPUProfile},
+			ok:   true,
+		},
+		{
+			file: DataFileName(ProcessPattern("Garbage/benchmem-MB=64", "garbage-bench"), 0, 0, CPUProfile),
+			want: DataFile{SubBenchmark: "Garbage/benchmem-MB=64", Process: "garbage-bench", Type: CPUProfile},
+			ok:   true,
+		},
+		{
+			file: DataFileName("BiogoIgor", 0, 0, Perf),
+			want: DataFile{SubBenchmark: "BiogoIgor", Type: Perf},
+			ok:   true,
+		},
+		{file: "EtcdPut@etcd.cpuprofile123456"},
+		{file: "EtcdPut@etcd.run1.x.cpuprofile"},
+		{file: "EtcdPut@etcd.run1.0.pprof"},
+		{file: ManifestName},
+	} {
+		got, ok := ParseDataFile(test.file)
+		if ok != test.ok || got != test.want {
+			t.Errorf("ParseDataFile(%q) = %+v, %v, want %+v, %v", test.file, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		DataFileName(ProcessPattern("EtcdPut", "etcd"), 0, 0, CPUProfile),
+		DataFileName(ProcessPattern("EtcdPut", "etcd"), 0, 1, CPUProfile),
+		DataFileName(ProcessPattern("EtcdPut", "etcd-bench"), 1, 0, MemProfile),
+		"merged.etcd.cpu",
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err := NewManifest(dir, "etcd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Write(dir); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]DataFile{
+		files[0]: {Benchmark: "etcd", SubBenchmark: "EtcdPut", Run: 0, Seq: 0, Process: "etcd", Type: CPUProfile},
+		files[1]: {Benchmark: "etcd", SubBenchmark: "EtcdPut", Run: 0, Seq: 1, Process: "etcd", Type: CPUProfile},
+		files[2]: {Benchmark: "etcd", SubBenchmark: "EtcdPut", Run: 1, Seq: 0, Process: "etcd-bench", Type: MemProfile},
+	}
+	if !reflect.DeepEqual(got.Files, want) {
+		t.Errorf("got manifest %+v, want %+v", got.Files, want)
+	}
+
+	// Removing a run leaves other runs' data and other files alone.
+	if err := RemoveRun(dir, 0); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var left []string
+	for _, e := range entries {
+		left = append(left, e.Name())
+	}
+	if want := []string{files[2], ManifestName, "merged.etcd.cpu"}; !reflect.DeepEqual(left, want) {
+		t.Errorf("after RemoveRun(0), directory has %q, want %q", left, want)
+	}
+}
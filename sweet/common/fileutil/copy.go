@@ -66,8 +66,10 @@ func CopyFile(dst, src string, sfinfo fs.FileInfo, srcFS fs.FS) error {
 		return err
 	}
 	defer df.Close()
-	_, err = io.Copy(df, sf)
-	return err
+	if _, err := io.Copy(df, sf); err != nil {
+		return fmt.Errorf("copying %s: %w", src, err)
+	}
+	return nil
 }
 
 // CopyDir recursively copies the directory at path src to
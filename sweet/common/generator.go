@@ -4,6 +4,8 @@
 
 package common
 
+import "errors"
+
 type GenConfig struct {
 	AssetsDir       string
 	SourceAssetsDir string
@@ -16,3 +18,22 @@ type Generator interface {
 	// from an old assets directory, given a configuration.
 	Generate(*GenConfig) error
 }
+
+// ErrNotComparable is returned by an AssetComparer for assets it has
+// no semantic comparison for.
+var ErrNotComparable = errors.New("asset has no semantic comparison")
+
+// AssetComparer may be implemented by a Generator whose output is not
+// reproducible byte-for-byte, to check that regenerated assets are
+// nonetheless equivalent to the old ones.
+type AssetComparer interface {
+	// CompareAsset compares the old and new versions of a generated
+	// asset, found at oldPath and newPath respectively. name is the
+	// slash-separated path of the asset relative to the benchmark's
+	// assets directory.
+	//
+	// Returns ErrNotComparable if the asset cannot be compared, and
+	// an error describing the difference if the assets aren't
+	// equivalent.
+	CompareAsset(name, oldPath, newPath string) error
+}
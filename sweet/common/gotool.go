@@ -5,12 +5,11 @@
 package common
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
-
-	"golang.org/x/benchmarks/sweet/common/log"
 )
 
 type Go struct {
@@ -40,12 +39,11 @@ func (g *Go) Do(dir string, args ...string) error {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 	}
-	log.TraceCommand(cmd, false)
 	if g.PassOutput {
-		return cmd.Run()
+		return RunCommand(cmd)
 	}
 	// Use cmd.Output to get an ExitError with Stderr populated.
-	_, err := cmd.Output()
+	_, err := CommandOutput(cmd)
 	if ee, ok := err.(*exec.ExitError); ok {
 		// ExitError includes stderr, but doesn't inclue it in Error.
 		// Create a new error that does display stderr.
@@ -57,8 +55,27 @@ func (g *Go) Do(dir string, args ...string) error {
 func (g *Go) List(args ...string) ([]byte, error) {
 	cmd := exec.Command(g.Tool, append([]string{"list"}, args...)...)
 	cmd.Env = g.Env.Collapse()
-	log.TraceCommand(cmd, false)
-	return cmd.Output()
+	return CommandOutput(cmd)
+}
+
+// EnvVars returns the values of the named Go environment variables
+// (see 'go help environment') as reported by 'go env'. In a dry run, no
+// variables are returned.
+func (g *Go) EnvVars(names ...string) (map[string]string, error) {
+	cmd := exec.Command(g.Tool, append([]string{"env", "-json"}, names...)...)
+	cmd.Env = g.Env.Collapse()
+	out, err := CommandOutput(cmd)
+	if err != nil {
+		return nil, err
+	}
+	vars := make(map[string]string)
+	if len(out) == 0 {
+		return vars, nil
+	}
+	if err := json.Unmarshal(out, &vars); err != nil {
+		return nil, fmt.Errorf("parsing go env output: %w", err)
+	}
+	return vars, nil
 }
 
 func (g *Go) GOROOT() string {
@@ -88,6 +105,15 @@ func (g *Go) BuildPath(path, out string) error {
 }
 
 func chdir(path string) error {
-	log.CommandPrintf("cd %s", path)
-	return os.Chdir(path)
+	return Do(fmt.Sprintf("cd %s", path), func() error {
+		return os.Chdir(path)
+	})
+}
+
+// VersionM returns the output of 'go version -m' for the binary at path,
+// which describes how it was built.
+func (g *Go) VersionM(path string) ([]byte, error) {
+	cmd := exec.Command(g.Tool, "version", "-m", path)
+	cmd.Env = g.Env.Collapse()
+	return CommandOutput(cmd)
 }
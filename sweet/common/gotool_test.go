@@ -0,0 +1,33 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package common_test
+
+import (
+	"runtime"
+	"testing"
+
+	"golang.org/x/benchmarks/sweet/common"
+)
+
+func TestGoEnvVars(t *testing.T) {
+	goTool, err := common.SystemGoTool()
+	if err != nil {
+		t.Skip(err)
+	}
+	goTool.Env = goTool.Env.MustSet("GOOS="+runtime.GOOS, "GOARCH="+runtime.GOARCH)
+	vars, err := goTool.EnvVars("GOOS", "GOARCH", "GOVERSION")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := vars["GOOS"]; got != runtime.GOOS {
+		t.Errorf("GOOS = %q, want %q", got, runtime.GOOS)
+	}
+	if got := vars["GOARCH"]; got != runtime.GOARCH {
+		t.Errorf("GOARCH = %q, want %q", got, runtime.GOARCH)
+	}
+	if vars["GOVERSION"] == "" {
+		t.Error("GOVERSION is empty")
+	}
+}
@@ -40,6 +40,27 @@ type BuildConfig struct {
 	// for testing. Guaranteed to be the same as GetConfig.Short and
 	// RunConfig.Short.
 	Short bool
+
+	// PGOFiles maps the names of the binaries built for the benchmark to
+	// the profiles they should be built with. The profile for the empty
+	// name, if any, is used for binaries with no profile of their own.
+	// Binaries without a profile must be built with PGO disabled.
+	//
+	// See PGOFile and Config.BenchmarkPGOFiles.
+	PGOFiles map[string]string
+}
+
+// PGOFile returns the profile that the binary called bin should be
+// built with, or "off" if it should be built without PGO. The result
+// is suitable for passing to the Go tool's -pgo flag.
+func (b *BuildConfig) PGOFile(bin string) string {
+	if p, ok := b.PGOFiles[bin]; ok {
+		return p
+	}
+	if p, ok := b.PGOFiles[""]; ok {
+		return p
+	}
+	return "off"
 }
 
 type RunConfig struct {
@@ -5,6 +5,7 @@
 package log
 
 import (
+	"io"
 	"log"
 	"os"
 	"os/exec"
@@ -47,6 +48,18 @@ func SetActivityLog(on bool) {
 	actOn = on
 }
 
+// SetOutput redirects both the command trace and the activity log to w.
+// If w is nil, they are restored to stdout and stderr respectively.
+func SetOutput(w io.Writer) {
+	if w == nil {
+		cmdLog.SetOutput(os.Stdout)
+		actLog.SetOutput(os.Stderr)
+		return
+	}
+	cmdLog.SetOutput(w)
+	actLog.SetOutput(w)
+}
+
 func filterEnviron(env []string) []string {
 	fenv := make([]string, 0, len(env))
 	for _, e := range env {
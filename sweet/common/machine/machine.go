@@ -0,0 +1,294 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package machine captures properties of the host that are known to
+// affect benchmark noise, and provides checks to run before benchmarking.
+package machine
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Info is a snapshot of the properties of the host machine that
+// tend to affect benchmark results.
+//
+// Any field that could not be determined is left empty.
+type Info struct {
+	// CPU is the CPU model name.
+	CPU string
+
+	// Kernel is the kernel release.
+	Kernel string
+
+	// Governor is the CPU frequency scaling governor. If different
+	// CPUs have different governors, all of them are listed, separated
+	// by commas.
+	Governor string
+
+	// Boost is whether turbo/boost frequencies are enabled ("on" or "off").
+	Boost string
+
+	// SMT is whether simultaneous multithreading is active ("on" or "off").
+	SMT string
+
+	// THP and THPDefrag are the transparent huge page settings.
+	THP       string
+	THPDefrag string
+}
+
+// Snapshot collects an Info for the current machine.
+func Snapshot() *Info {
+	return snapshot(rootFS)
+}
+
+func snapshot(fsys fs.FS) *Info {
+	return &Info{
+		CPU:       cpuModel(fsys),
+		Kernel:    readValue(fsys, "proc/sys/kernel/osrelease"),
+		Governor:  governor(fsys),
+		Boost:     boost(fsys),
+		SMT:       onOff(readValue(fsys, "sys/devices/system/cpu/smt/active")),
+		THP:       selected(readValue(fsys, "sys/kernel/mm/transparent_hugepage/enabled")),
+		THPDefrag: selected(readValue(fsys, "sys/kernel/mm/transparent_hugepage/defrag")),
+	}
+}
+
+// Lines returns the Info as key-value pairs in the form expected for
+// configuration lines in the Go benchmark format, in a stable order.
+// Empty fields are omitted.
+func (i *Info) Lines() []string {
+	var lines []string
+	for _, kv := range []struct{ key, value string }{
+		{"cpu", i.CPU},
+		{"kernel", i.Kernel},
+		{"cpu-governor", i.Governor},
+		{"cpu-boost", i.Boost},
+		{"smt", i.SMT},
+		{"thp", i.THP},
+		{"thp-defrag", i.THPDefrag},
+	} {
+		if kv.value == "" {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", kv.key, kv.value))
+	}
+	return lines
+}
+
+// WriteTo writes the Info to w as Go benchmark format configuration lines.
+func (i *Info) WriteTo(w io.Writer) (int64, error) {
+	var n int64
+	for _, line := range i.Lines() {
+		m, err := fmt.Fprintln(w, line)
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// PerformanceGovernor is the name of the CPU frequency governor that
+// keeps CPUs at their maximum frequency.
+const PerformanceGovernor = "performance"
+
+// CheckGovernor returns an error if the CPU frequency governor is known
+// and is not PerformanceGovernor on every CPU.
+func (i *Info) CheckGovernor() error {
+	if i.Governor == "" || i.Governor == PerformanceGovernor {
+		return nil
+	}
+	return fmt.Errorf("CPU frequency governor is %q, want %q", i.Governor, PerformanceGovernor)
+}
+
+// GovernorPolicy describes what to do when the CPU frequency governor
+// is not PerformanceGovernor.
+type GovernorPolicy int
+
+const (
+	GovernorIgnore GovernorPolicy = iota
+	GovernorWarn
+	GovernorRefuse
+	NumGovernorPolicies
+)
+
+var governorPolicyString = [NumGovernorPolicies]string{
+	"ignore",
+	"warn",
+	"refuse",
+}
+
+func (p *GovernorPolicy) String() string {
+	return governorPolicyString[*p]
+}
+
+func (p *GovernorPolicy) Set(input string) error {
+	for i := range governorPolicyString {
+		if governorPolicyString[i] == input {
+			*p = GovernorPolicy(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unrecognized governor policy: %s", input)
+}
+
+// LoadAvg returns the 1-minute load average.
+func LoadAvg() (float64, error) {
+	return loadAvg(rootFS)
+}
+
+func loadAvg(fsys fs.FS) (float64, error) {
+	if fsys == nil {
+		return 0, fmt.Errorf("load average is not supported on this platform")
+	}
+	b, err := fs.ReadFile(fsys, "proc/loadavg")
+	if err != nil {
+		return 0, fmt.Errorf("error reading /proc/loadavg: %w", err)
+	}
+	parts := strings.Fields(string(b))
+	if len(parts) == 0 {
+		return 0, fmt.Errorf("malformed load average %q", string(b))
+	}
+	avg, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed load average %q: %v", parts[0], err)
+	}
+	return avg, nil
+}
+
+// WaitForIdle blocks until the 1-minute load average drops below maxLoad,
+// checking every poll interval. Each time the machine is found to be busy,
+// busy is called with the current load average, if it is not nil. If the
+// load average has not dropped below maxLoad after timeout, WaitForIdle
+// gives up and returns an error reporting the last load average. A
+// timeout of zero or less means wait forever.
+//
+// On platforms where the load average is unavailable, WaitForIdle
+// returns immediately.
+func WaitForIdle(maxLoad float64, poll, timeout time.Duration, busy func(avg float64)) error {
+	if rootFS == nil {
+		return nil
+	}
+	return waitForIdle(rootFS, maxLoad, poll, timeout, busy)
+}
+
+func waitForIdle(fsys fs.FS, maxLoad float64, poll, timeout time.Duration, busy func(avg float64)) error {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	for {
+		avg, err := loadAvg(fsys)
+		if err != nil {
+			return err
+		}
+		if avg < maxLoad {
+			return nil
+		}
+		if !deadline.IsZero() && !time.Now().Add(poll).Before(deadline) {
+			return fmt.Errorf("load average is still %.2f after %s, above the maximum of %.2f", avg, timeout, maxLoad)
+		}
+		if busy != nil {
+			busy(avg)
+		}
+		time.Sleep(poll)
+	}
+}
+
+func readValue(fsys fs.FS, name string) string {
+	if fsys == nil {
+		return ""
+	}
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+func cpuModel(fsys fs.FS) string {
+	if fsys == nil {
+		return ""
+	}
+	b, err := fs.ReadFile(fsys, "proc/cpuinfo")
+	if err != nil {
+		return ""
+	}
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		key, value, ok := strings.Cut(s.Text(), ":")
+		if !ok {
+			continue
+		}
+		if strings.TrimSpace(key) == "model name" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+func governor(fsys fs.FS) string {
+	if fsys == nil {
+		return ""
+	}
+	files, err := fs.Glob(fsys, "sys/devices/system/cpu/cpu[0-9]*/cpufreq/scaling_governor")
+	if err != nil {
+		return ""
+	}
+	seen := make(map[string]bool)
+	var govs []string
+	for _, file := range files {
+		g := readValue(fsys, file)
+		if g == "" || seen[g] {
+			continue
+		}
+		seen[g] = true
+		govs = append(govs, g)
+	}
+	sort.Strings(govs)
+	return strings.Join(govs, ",")
+}
+
+func boost(fsys fs.FS) string {
+	// intel_pstate exposes the inverse setting.
+	if v := readValue(fsys, "sys/devices/system/cpu/intel_pstate/no_turbo"); v != "" {
+		switch v {
+		case "0":
+			return "on"
+		case "1":
+			return "off"
+		}
+		return v
+	}
+	return onOff(readValue(fsys, "sys/devices/system/cpu/cpufreq/boost"))
+}
+
+func onOff(v string) string {
+	switch v {
+	case "0":
+		return "off"
+	case "1":
+		return "on"
+	}
+	return v
+}
+
+// selected extracts the bracketed choice from sysfs files that list all
+// options, such as "always [madvise] never".
+func selected(v string) string {
+	for _, opt := range strings.Fields(v) {
+		if strings.HasPrefix(opt, "[") && strings.HasSuffix(opt, "]") {
+			return opt[1 : len(opt)-1]
+		}
+	}
+	return v
+}
@@ -0,0 +1,12 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !linux
+
+package machine
+
+import "io/fs"
+
+// rootFS is nil because machine information is only collected on Linux.
+var rootFS fs.FS
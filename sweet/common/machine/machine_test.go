@@ -0,0 +1,114 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package machine
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func file(s string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(s)}
+}
+
+func TestSnapshot(t *testing.T) {
+	fsys := fstest.MapFS{
+		"proc/cpuinfo":              file("processor\t: 0\nvendor_id\t: GenuineIntel\nmodel name\t: Intel(R) Xeon(R) CPU @ 2.20GHz\n\nprocessor\t: 1\nmodel name\t: Intel(R) Xeon(R) CPU @ 2.20GHz\n"),
+		"proc/sys/kernel/osrelease": file("6.1.0-18-amd64\n"),
+		"sys/devices/system/cpu/cpu0/cpufreq/scaling_governor": file("performance\n"),
+		"sys/devices/system/cpu/cpu1/cpufreq/scaling_governor": file("powersave\n"),
+		"sys/devices/system/cpu/intel_pstate/no_turbo":         file("1\n"),
+		"sys/devices/system/cpu/smt/active":                    file("1\n"),
+		"sys/kernel/mm/transparent_hugepage/enabled":           file("always [madvise] never\n"),
+		"sys/kernel/mm/transparent_hugepage/defrag":            file("always defer defer+madvise [madvise] never\n"),
+	}
+	got := snapshot(fsys)
+	want := &Info{
+		CPU:       "Intel(R) Xeon(R) CPU @ 2.20GHz",
+		Kernel:    "6.1.0-18-amd64",
+		Governor:  "performance,powersave",
+		Boost:     "off",
+		SMT:       "on",
+		THP:       "madvise",
+		THPDefrag: "madvise",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("snapshot() = %+v, want %+v", got, want)
+	}
+	if err := got.CheckGovernor(); err == nil {
+		t.Error("expected error from CheckGovernor for mixed governors")
+	}
+	wantLines := []string{
+		"cpu: Intel(R) Xeon(R) CPU @ 2.20GHz",
+		"kernel: 6.1.0-18-amd64",
+		"cpu-governor: performance,powersave",
+		"cpu-boost: off",
+		"smt: on",
+		"thp: madvise",
+		"thp-defrag: madvise",
+	}
+	if lines := got.Lines(); !reflect.DeepEqual(lines, wantLines) {
+		t.Errorf("Lines() = %q, want %q", lines, wantLines)
+	}
+}
+
+func TestSnapshotMissing(t *testing.T) {
+	got := snapshot(fstest.MapFS{
+		"sys/devices/system/cpu/cpufreq/boost": file("1\n"),
+	})
+	want := &Info{Boost: "on"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("snapshot() = %+v, want %+v", got, want)
+	}
+	if err := got.CheckGovernor(); err != nil {
+		t.Errorf("unexpected error from CheckGovernor with unknown governor: %v", err)
+	}
+	if lines := (&Info{}).Lines(); len(lines) != 0 {
+		t.Errorf("expected no lines for empty Info, got %q", lines)
+	}
+}
+
+func TestLoadAvg(t *testing.T) {
+	avg, err := loadAvg(fstest.MapFS{
+		"proc/loadavg": file("0.15 0.32 0.40 1/1024 4242\n"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if avg != 0.15 {
+		t.Errorf("loadAvg() = %f, want 0.15", avg)
+	}
+	if _, err := loadAvg(fstest.MapFS{"proc/loadavg": file("bogus")}); err == nil {
+		t.Error("expected error for malformed load average")
+	}
+}
+
+func TestWaitForIdle(t *testing.T) {
+	idle := fstest.MapFS{"proc/loadavg": file("0.05 0.10 0.20 1/1024 4242\n")}
+	if err := waitForIdle(idle, 0.2, time.Millisecond, time.Millisecond, nil); err != nil {
+		t.Errorf("unexpected error waiting for an idle machine: %v", err)
+	}
+
+	busy := fstest.MapFS{"proc/loadavg": file("3.50 2.00 1.00 1/1024 4242\n")}
+	n := 0
+	err := waitForIdle(busy, 0.2, time.Millisecond, 10*time.Millisecond, func(avg float64) {
+		if avg != 3.5 {
+			t.Errorf("busy called with %f, want 3.5", avg)
+		}
+		n++
+	})
+	if err == nil {
+		t.Fatal("expected error waiting for a busy machine")
+	}
+	if !strings.Contains(err.Error(), "3.50") {
+		t.Errorf("error %q does not report the last load average", err)
+	}
+	if n == 0 {
+		t.Error("busy was never called")
+	}
+}
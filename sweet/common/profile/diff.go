@@ -0,0 +1,142 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package profile
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/pprof/profile"
+)
+
+// baseLabel is the label pprof uses to identify samples from the base
+// profile in a diff profile (see pprof's -diff_base flag). Using it means
+// pprof will present profiles produced by Diff as diffs.
+const baseLabel = "pprof::base"
+
+// SampleIndex returns the index of the sample type called name in p.
+// If name is empty, it returns the index of the last sample type, which
+// is the default in pprof (for example, CPU time in a CPU profile).
+func SampleIndex(p *profile.Profile, name string) (int, error) {
+	if len(p.SampleType) == 0 {
+		return 0, fmt.Errorf("profile has no sample types")
+	}
+	if name == "" {
+		return len(p.SampleType) - 1, nil
+	}
+	for i, st := range p.SampleType {
+		if st.Type == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("profile has no sample type %q", name)
+}
+
+// Total returns the sum of the values at index idx of all the samples in p.
+func Total(p *profile.Profile, idx int) int64 {
+	var total int64
+	for _, s := range p.Sample {
+		total += s.Value[idx]
+	}
+	return total
+}
+
+// Diff returns a profile of the difference between exp and base, in the
+// same form as pprof's -diff_base flag produces: base's samples are
+// negated and labeled, then merged with exp's.
+//
+// Before negating base, it is scaled by expNorm/baseNorm, where each of
+// baseNorm and expNorm is some measure of how much work the profile
+// represents, such as its total samples (see Total) or the number of
+// benchmark operations it covers. The values in the diff are thus in the
+// units of exp. Neither base nor exp is modified.
+func Diff(base, exp *profile.Profile, baseNorm, expNorm float64) (*profile.Profile, error) {
+	if baseNorm <= 0 || expNorm <= 0 {
+		return nil, fmt.Errorf("normalization factors must be positive")
+	}
+	neg := base.Copy()
+	neg.Scale(-expNorm / baseNorm)
+	neg.SetLabel(baseLabel, []string{"true"})
+	return profile.Merge([]*profile.Profile{exp, neg})
+}
+
+// Delta is the change in the value attributed to some part of a program
+// between the base and experiment profiles in a diff profile.
+type Delta struct {
+	// Name describes the part of the program, such as a function.
+	Name string
+
+	// Base and Exp are the flat values attributed to the part of the
+	// program in the (scaled) base and experiment profiles respectively.
+	Base, Exp int64
+}
+
+// Diff returns the change in value from the base to the experiment.
+func (d Delta) Diff() int64 {
+	return d.Exp - d.Base
+}
+
+// FunctionDeltas returns the change in the flat value at index idx for
+// each function in the diff profile p, as produced by Diff, ordered from
+// largest regression (increase) to largest improvement.
+func FunctionDeltas(p *profile.Profile, idx int) []Delta {
+	return deltas(p, idx, func(loc *profile.Location) (string, bool) {
+		// The leaf function is the first line of the first location.
+		// Lines are ordered from innermost inlined call outward.
+		if len(loc.Line) == 0 || loc.Line[0].Function == nil {
+			return "", false
+		}
+		return loc.Line[0].Function.Name, true
+	})
+}
+
+// InlinedCallDeltas returns the change in the flat value at index idx for
+// each inlined call site in the diff profile p, as produced by Diff, ordered
+// from largest regression (increase) to largest improvement. Only samples
+// whose leaf function was inlined are attributed to a call site.
+func InlinedCallDeltas(p *profile.Profile, idx int) []Delta {
+	return deltas(p, idx, func(loc *profile.Location) (string, bool) {
+		if len(loc.Line) < 2 || loc.Line[0].Function == nil || loc.Line[1].Function == nil {
+			return "", false
+		}
+		callee, caller := loc.Line[0].Function, loc.Line[1]
+		return fmt.Sprintf("%s inlined into %s at %s:%d",
+			callee.Name, caller.Function.Name, caller.Function.Filename, caller.Line), true
+	})
+}
+
+func deltas(p *profile.Profile, idx int, key func(*profile.Location) (string, bool)) []Delta {
+	byKey := make(map[string]*Delta)
+	for _, s := range p.Sample {
+		if len(s.Location) == 0 {
+			continue
+		}
+		k, ok := key(s.Location[0])
+		if !ok {
+			continue
+		}
+		d := byKey[k]
+		if d == nil {
+			d = &Delta{Name: k}
+			byKey[k] = d
+		}
+		if s.HasLabel(baseLabel, "true") {
+			d.Base -= s.Value[idx]
+		} else {
+			d.Exp += s.Value[idx]
+		}
+	}
+	ds := make([]Delta, 0, len(byKey))
+	for _, d := range byKey {
+		ds = append(ds, *d)
+	}
+	sort.Slice(ds, func(i, j int) bool {
+		if ds[i].Diff() != ds[j].Diff() {
+			return ds[i].Diff() > ds[j].Diff()
+		}
+		return ds[i].Name < ds[j].Name
+	})
+	return ds
+}
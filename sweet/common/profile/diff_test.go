@@ -0,0 +1,77 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package profile_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/pprof/profile"
+	sprofile "golang.org/x/benchmarks/sweet/common/profile"
+)
+
+// testProfile returns a CPU profile with flat samples in the functions
+// f, g, and h inlined into g, with the given values.
+func testProfile(f, g, gh int64) *profile.Profile {
+	fnF := &profile.Function{ID: 1, Name: "f", Filename: "x.go"}
+	fnG := &profile.Function{ID: 2, Name: "g", Filename: "x.go"}
+	fnH := &profile.Function{ID: 3, Name: "h", Filename: "x.go"}
+	locF := &profile.Location{ID: 1, Address: 0x10, Line: []profile.Line{{Function: fnF, Line: 1}}}
+	locG := &profile.Location{ID: 2, Address: 0x20, Line: []profile.Line{{Function: fnG, Line: 2}}}
+	locGH := &profile.Location{ID: 3, Address: 0x30, Line: []profile.Line{{Function: fnH, Line: 3}, {Function: fnG, Line: 4}}}
+	return &profile.Profile{
+		SampleType: []*profile.ValueType{{Type: "samples", Unit: "count"}, {Type: "cpu", Unit: "nanoseconds"}},
+		PeriodType: &profile.ValueType{Type: "cpu", Unit: "nanoseconds"},
+		Period:     1,
+		Sample: []*profile.Sample{
+			{Location: []*profile.Location{locF}, Value: []int64{1, f}},
+			{Location: []*profile.Location{locG}, Value: []int64{1, g}},
+			{Location: []*profile.Location{locGH, locG}, Value: []int64{1, gh}},
+		},
+		Location: []*profile.Location{locF, locG, locGH},
+		Function: []*profile.Function{fnF, fnG, fnH},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	base := testProfile(100, 100, 0)
+	exp := testProfile(300, 100, 200)
+	idx, err := sprofile.SampleIndex(exp, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Normalizing by total samples scales base up by 3.
+	diff, err := sprofile.Diff(base, exp, float64(sprofile.Total(base, idx)), float64(sprofile.Total(exp, idx)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sprofile.Total(base, idx); got != 200 {
+		t.Errorf("base was modified: total is %d, want 200", got)
+	}
+	got := sprofile.FunctionDeltas(diff, idx)
+	want := []sprofile.Delta{
+		{Name: "h", Base: 0, Exp: 200},
+		{Name: "f", Base: 300, Exp: 300},
+		{Name: "g", Base: 300, Exp: 100},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got function deltas %+v, want %+v", got, want)
+	}
+	gotInl := sprofile.InlinedCallDeltas(diff, idx)
+	wantInl := []sprofile.Delta{{Name: "h inlined into g at x.go:4", Base: 0, Exp: 200}}
+	if !reflect.DeepEqual(gotInl, wantInl) {
+		t.Errorf("got inlined call deltas %+v, want %+v", gotInl, wantInl)
+	}
+
+	// Normalizing by ops, where exp covered twice as many.
+	diff, err = sprofile.Diff(base, exp, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sprofile.FunctionDeltas(diff, idx)[1]; got.Name != "f" || got.Diff() != 100 {
+		t.Errorf("got %+v for f, want a diff of 100", got)
+	}
+}
@@ -8,6 +8,7 @@ package profile
 import (
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/google/pprof/profile"
 )
@@ -49,3 +50,40 @@ func ReadDirPprof(dir string, match func(string) bool) ([]*profile.Profile, erro
 	}
 	return profiles, nil
 }
+
+// TopFunctions returns the names of up to n functions in p with the
+// largest flat value for the last sample type in p (for example, CPU
+// time in a CPU profile), from largest to smallest.
+func TopFunctions(p *profile.Profile, n int) []string {
+	if len(p.SampleType) == 0 {
+		return nil
+	}
+	idx := len(p.SampleType) - 1
+	flat := make(map[string]int64)
+	for _, s := range p.Sample {
+		if len(s.Location) == 0 {
+			continue
+		}
+		// The leaf function is the first line of the first location.
+		// Lines are ordered from innermost inlined call outward.
+		loc := s.Location[0]
+		if len(loc.Line) == 0 || loc.Line[0].Function == nil {
+			continue
+		}
+		flat[loc.Line[0].Function.Name] += s.Value[idx]
+	}
+	funcs := make([]string, 0, len(flat))
+	for name := range flat {
+		funcs = append(funcs, name)
+	}
+	sort.Slice(funcs, func(i, j int) bool {
+		if flat[funcs[i]] != flat[funcs[j]] {
+			return flat[funcs[i]] > flat[funcs[j]]
+		}
+		return funcs[i] < funcs[j]
+	})
+	if len(funcs) > n {
+		funcs = funcs[:n]
+	}
+	return funcs
+}
@@ -0,0 +1,40 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package profile_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/pprof/profile"
+	sprofile "golang.org/x/benchmarks/sweet/common/profile"
+)
+
+func TestTopFunctions(t *testing.T) {
+	// h is inlined into g, so samples in it are attributed to h alone.
+	p := testProfile(100, 300, 200)
+	for _, test := range []struct {
+		n    int
+		want []string
+	}{
+		{3, []string{"g", "h", "f"}},
+		{2, []string{"g", "h"}},
+		{10, []string{"g", "h", "f"}},
+		{0, []string{}},
+	} {
+		if got := sprofile.TopFunctions(p, test.n); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("TopFunctions(p, %d) = %q, want %q", test.n, got, test.want)
+		}
+	}
+
+	// Ties are broken by name.
+	if got, want := sprofile.TopFunctions(testProfile(100, 100, 100), 3), []string{"f", "g", "h"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TopFunctions with ties = %q, want %q", got, want)
+	}
+
+	if got := sprofile.TopFunctions(&profile.Profile{}, 3); got != nil {
+		t.Errorf("TopFunctions of profile without sample types = %q, want nil", got)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package results reads the results produced by 'sweet run', which are in
+// the Go benchmark format.
+package results
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Value is a single measurement in a result.
+type Value struct {
+	Value float64
+	Unit  string
+}
+
+// Result is a single benchmark result line.
+type Result struct {
+	// Name is the name of the benchmark, without the "Benchmark" prefix.
+	Name string
+
+	// Run is the index of the result among those with the same name in
+	// the same file, starting from zero.
+	Run int
+
+	Iters  int64
+	Values []Value
+
+	// Config holds the configuration lines (such as "goos: linux") in
+	// effect for the result. It may be shared with other results and
+	// must not be modified.
+	Config map[string]string
+}
+
+// Get returns the value of the result with the given unit.
+func (r *Result) Get(unit string) (float64, bool) {
+	for _, v := range r.Values {
+		if v.Unit == unit {
+			return v.Value, true
+		}
+	}
+	return 0, false
+}
+
+// Parse parses the results in r. Lines that are neither configuration
+// lines nor benchmark results, such as output from the benchmark, are
+// ignored.
+func Parse(r io.Reader) ([]Result, error) {
+	var results []Result
+	config := make(map[string]string)
+	runs := make(map[string]int)
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for line := 1; s.Scan(); line++ {
+		text := s.Text()
+		if key, value, ok := parseConfigLine(text); ok {
+			// Copy on write, since earlier results share config.
+			next := make(map[string]string, len(config)+1)
+			for k, v := range config {
+				next[k] = v
+			}
+			if value == "" {
+				delete(next, key)
+			} else {
+				next[key] = value
+			}
+			config = next
+			continue
+		}
+		res, ok, err := parseResultLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		} else if !ok {
+			continue
+		}
+		res.Run = runs[res.Name]
+		runs[res.Name]++
+		res.Config = config
+		results = append(results, res)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// parseConfigLine parses a line of the form "key: value", where key
+// begins with a lower case letter and contains no spaces.
+func parseConfigLine(line string) (key, value string, ok bool) {
+	key, value, ok = strings.Cut(line, ":")
+	if !ok || key == "" || !unicode.IsLower(rune(key[0])) || strings.ContainsAny(key, " \t") {
+		return "", "", false
+	}
+	if value != "" && value[0] != ' ' && value[0] != '\t' {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(value), true
+}
+
+func parseResultLine(line string) (Result, bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || len(fields)%2 != 0 || !strings.HasPrefix(fields[0], "Benchmark") {
+		return Result{}, false, nil
+	}
+	name := strings.TrimPrefix(fields[0], "Benchmark")
+	if name == "" || !unicode.IsUpper(rune(name[0])) {
+		return Result{}, false, nil
+	}
+	iters, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		// Not a result after all.
+		return Result{}, false, nil
+	}
+	res := Result{Name: name, Iters: iters}
+	for i := 2; i < len(fields); i += 2 {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return Result{}, false, fmt.Errorf("parsing %s for %s: %w", fields[i+1], name, err)
+		}
+		res.Values = append(res.Values, Value{Value: v, Unit: fields[i+1]})
+	}
+	return res, true, nil
+}
+
+// ReadFile parses the results in the file called name.
+func ReadFile(name string) ([]Result, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	results, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return results, nil
+}
+
+// File is the results of a benchmark for a single config.
+type File struct {
+	Benchmark string
+	Config    string
+	Results   []Result
+}
+
+// ReadDir reads the results in a results directory as produced by
+// 'sweet run', which contains a subdirectory for each benchmark with a
+// results file for each config. The files are ordered by benchmark, then
+// config.
+func ReadDir(dir string) ([]File, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*", "*.results"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	files := make([]File, 0, len(paths))
+	for _, path := range paths {
+		results, err := ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{
+			Benchmark: filepath.Base(filepath.Dir(path)),
+			Config:    strings.TrimSuffix(filepath.Base(path), ".results"),
+			Results:   results,
+		})
+	}
+	return files, nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package results_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/benchmarks/sweet/common/results"
+)
+
+const testResults = `goos: linux
+toolchain: base
+some output from the benchmark
+BenchmarkFoo 1 100 ns/op 2048 peak-RSS-bytes
+BenchmarkBar 10 5.5 ns/op
+toolchain: exp
+BenchmarkFoo 1 200 ns/op 4096 peak-RSS-bytes
+BenchmarkNotAResult
+`
+
+func TestParse(t *testing.T) {
+	rs, err := results.Parse(strings.NewReader(testResults))
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := map[string]string{"goos": "linux", "toolchain": "base"}
+	exp := map[string]string{"goos": "linux", "toolchain": "exp"}
+	want := []results.Result{
+		{Name: "Foo", Run: 0, Iters: 1, Values: []results.Value{{100, "ns/op"}, {2048, "peak-RSS-bytes"}}, Config: base},
+		{Name: "Bar", Run: 0, Iters: 10, Values: []results.Value{{5.5, "ns/op"}}, Config: base},
+		{Name: "Foo", Run: 1, Iters: 1, Values: []results.Value{{200, "ns/op"}, {4096, "peak-RSS-bytes"}}, Config: exp},
+	}
+	if !reflect.DeepEqual(rs, want) {
+		t.Errorf("got %+v, want %+v", rs, want)
+	}
+	if v, ok := rs[2].Get("peak-RSS-bytes"); !ok || v != 4096 {
+		t.Errorf("got %v, %v for peak-RSS-bytes, want 4096, true", v, ok)
+	}
+}
+
+func TestParseBadValue(t *testing.T) {
+	if _, err := results.Parse(strings.NewReader("BenchmarkFoo 1 x ns/op\n")); err == nil {
+		t.Error("expected an error for a malformed value")
+	}
+}
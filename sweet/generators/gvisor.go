@@ -6,12 +6,16 @@ package generators
 
 import (
 	"bytes"
+	"debug/buildinfo"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"reflect"
+	"strings"
 
 	"golang.org/x/benchmarks/sweet/common"
 	"golang.org/x/benchmarks/sweet/common/fileutil"
@@ -167,3 +171,65 @@ func (GVisor) Generate(cfg *common.GenConfig) error {
 		nil,
 	)
 }
+
+// CompareAsset compares workload binaries by the way they were built,
+// since Go binaries aren't necessarily reproducible across machines, and
+// the runsc spec by its contents rather than its formatting.
+func (GVisor) CompareAsset(name, oldPath, newPath string) error {
+	switch {
+	case path.Base(name) == "workload":
+		oldInfo, err := workloadBuildInfo(oldPath)
+		if err != nil {
+			return err
+		}
+		newInfo, err := workloadBuildInfo(newPath)
+		if err != nil {
+			return err
+		}
+		if oldInfo != newInfo {
+			return fmt.Errorf("built differently:\n--- old\n%s--- new\n%s", oldInfo, newInfo)
+		}
+		return nil
+	case name == "startup/config.json":
+		var oldSpec, newSpec osi.Spec
+		if err := readJSONFile(oldPath, &oldSpec); err != nil {
+			return err
+		}
+		if err := readJSONFile(newPath, &newSpec); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(oldSpec, newSpec) {
+			return fmt.Errorf("specs differ")
+		}
+		return nil
+	}
+	return common.ErrNotComparable
+}
+
+// workloadBuildInfo returns the build information for the Go binary
+// at bin, omitting version control details, which depend on where
+// the binary was built.
+func workloadBuildInfo(bin string) (string, error) {
+	bi, err := buildinfo.ReadFile(bin)
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for _, line := range strings.SplitAfter(bi.String(), "\n") {
+		if !strings.HasPrefix(line, "build\tvcs") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, ""), nil
+}
+
+func readJSONFile(file string, v interface{}) error {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("parsing %s: %w", file, err)
+	}
+	return nil
+}
@@ -11,7 +11,9 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -298,3 +300,133 @@ func storeGeoJSON(c redis.Conn, jsonFile string) error {
 	_, err = c.Do("SET", "key:bench", "id:countries", "OBJECT", string(b))
 	return err
 }
+
+// CompareAsset compares Tile38 persistent stores by the number of objects
+// in each collection, since the server makes no guarantees about the
+// exact contents of its append-only file.
+func (Tile38) CompareAsset(name, oldPath, newPath string) error {
+	if path.Ext(name) != ".aof" {
+		return common.ErrNotComparable
+	}
+	oldCounts, err := countAOFObjects(oldPath)
+	if err != nil {
+		return err
+	}
+	newCounts, err := countAOFObjects(newPath)
+	if err != nil {
+		return err
+	}
+	var diffs []string
+	for key, n := range oldCounts {
+		if m := newCounts[key]; m != n {
+			diffs = append(diffs, fmt.Sprintf("%s has %d objects, previously %d", key, m, n))
+		}
+	}
+	for key, m := range newCounts {
+		if _, ok := oldCounts[key]; !ok {
+			diffs = append(diffs, fmt.Sprintf("%s has %d objects, previously absent", key, m))
+		}
+	}
+	if len(diffs) != 0 {
+		sort.Strings(diffs)
+		return fmt.Errorf("%s", strings.Join(diffs, "; "))
+	}
+	return nil
+}
+
+// countAOFObjects returns the number of objects in each collection
+// in the Tile38 append-only file at aofPath.
+func countAOFObjects(aofPath string) (map[string]int, error) {
+	f, err := os.Open(aofPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// The append-only file is a sequence of commands in the Redis
+	// serialization protocol, each one an array of bulk strings.
+	r := bufio.NewReader(f)
+	objs := make(map[string]map[string]struct{})
+	for {
+		cmd, err := readRESPCommand(r)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", aofPath, err)
+		}
+		if len(cmd) < 2 {
+			continue
+		}
+		key := cmd[1]
+		switch strings.ToLower(cmd[0]) {
+		case "set":
+			if len(cmd) < 3 {
+				continue
+			}
+			if objs[key] == nil {
+				objs[key] = make(map[string]struct{})
+			}
+			objs[key][cmd[2]] = struct{}{}
+		case "del":
+			if len(cmd) < 3 {
+				continue
+			}
+			delete(objs[key], cmd[2])
+		case "drop":
+			delete(objs, key)
+		}
+	}
+	counts := make(map[string]int)
+	for key, ids := range objs {
+		counts[key] = len(ids)
+	}
+	return counts, nil
+}
+
+// readRESPCommand reads a single command, encoded as an array of bulk
+// strings in the Redis serialization protocol, from r.
+func readRESPCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		if err == io.EOF && line != "" {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("expected array, found %q", line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, fmt.Errorf("bad array length %q", line)
+	}
+	cmd := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if !strings.HasPrefix(line, "$") {
+			return nil, fmt.Errorf("expected bulk string, found %q", line)
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("bad bulk string length %q", line)
+		}
+		buf := make([]byte, size+2) // Include trailing CRLF.
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		cmd = append(cmd, string(buf[:size]))
+	}
+	return cmd, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generators
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/benchmarks/sweet/common"
+)
+
+func resp(args ...string) string {
+	var sb strings.Builder
+	sb.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		sb.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+	return sb.String()
+}
+
+func TestTile38CompareAsset(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, cmds ...string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(strings.Join(cmds, "")), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	old := write("old.aof",
+		resp("set", "key:bench", "id:countries", "OBJECT", "{}"),
+		resp("set", "key:bench", "id:1", "POINT", "1.00000", "2.00000"),
+		resp("set", "key:bench", "id:2", "POINT", "3.00000", "4.00000"),
+	)
+	// Same objects, written in a different order and with a redundant update.
+	same := write("same.aof",
+		resp("set", "key:bench", "id:2", "POINT", "3.00000", "4.00000"),
+		resp("SET", "key:bench", "id:1", "POINT", "1.00000", "2.00000"),
+		resp("set", "key:bench", "id:countries", "OBJECT", "{}"),
+		resp("set", "key:bench", "id:1", "POINT", "1.00000", "2.00000"),
+	)
+	fewer := write("fewer.aof",
+		resp("set", "key:bench", "id:countries", "OBJECT", "{}"),
+		resp("set", "key:bench", "id:1", "POINT", "1.00000", "2.00000"),
+		resp("set", "key:bench", "id:2", "POINT", "3.00000", "4.00000"),
+		resp("del", "key:bench", "id:2"),
+	)
+	truncated := write("truncated.aof", resp("set", "key:bench", "id:1", "POINT", "1.00000", "2.00000")[:20])
+
+	var g Tile38
+	if err := g.CompareAsset("data/appendonly.aof", old, same); err != nil {
+		t.Errorf("unexpected error comparing equivalent stores: %v", err)
+	}
+	if err := g.CompareAsset("data/appendonly.aof", old, fewer); err == nil || !strings.Contains(err.Error(), "2 objects, previously 3") {
+		t.Errorf("expected error reporting object counts, got %v", err)
+	}
+	if err := g.CompareAsset("data/appendonly.aof", old, truncated); err == nil {
+		t.Error("expected error comparing against truncated store")
+	}
+	if err := g.CompareAsset("gen-data/README.md", old, same); !errors.Is(err, common.ErrNotComparable) {
+		t.Errorf("expected ErrNotComparable for non-store asset, got %v", err)
+	}
+}
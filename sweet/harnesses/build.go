@@ -0,0 +1,53 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package harnesses
+
+import (
+	"os/exec"
+	"path/filepath"
+
+	"golang.org/x/benchmarks/sweet/common"
+)
+
+// Build is the harness for a port of the legacy build benchmark, which
+// runs 'go build -a -p $GOMAXPROCS' on cmd/go. Like GoBuild, it uses a copy of the
+// toolchain's GOROOT with the compiler and linker rebuilt, so that build
+// options such as PGO apply, and collects diagnostic data from them
+// through -toolexec, but it needs no sources of its own.
+type Build struct{}
+
+func (h Build) CheckPrerequisites() error {
+	return nil
+}
+
+func (h Build) Get(_ *common.GetConfig) error {
+	return nil
+}
+
+func (h Build) Build(pcfg *common.Config, bcfg *common.BuildConfig) error {
+	cfg, err := buildGoRoot(pcfg, bcfg)
+	if err != nil {
+		return err
+	}
+	return goTool(cfg, bcfg, "build-bench").BuildPath(bcfg.BenchDir, filepath.Join(bcfg.BinDir, "build-bench"))
+}
+
+func (h Build) Run(pcfg *common.Config, rcfg *common.RunConfig) error {
+	// Local copy of config for updating GOROOT.
+	cfg := pcfg.Copy()
+	cfg.GoRoot = filepath.Join(rcfg.BinDir, "goroot") // see buildGoRoot.
+
+	cmd := exec.Command(
+		filepath.Join(rcfg.BinDir, "build-bench"),
+		append(rcfg.Args, []string{
+			"-go", cfg.GoTool().Tool,
+			"-tmp", rcfg.TmpDir,
+		}...)...,
+	)
+	cmd.Env = cfg.ExecEnv.Collapse()
+	cmd.Stdout = rcfg.Results
+	cmd.Stderr = rcfg.Results
+	return common.RunCommand(cmd)
+}
@@ -10,7 +10,6 @@ import (
 	"path/filepath"
 
 	"golang.org/x/benchmarks/sweet/common"
-	"golang.org/x/benchmarks/sweet/common/log"
 )
 
 type CockroachDB struct{}
@@ -64,7 +63,7 @@ func (h CockroachDB) Build(cfg *common.Config, bcfg *common.BuildConfig) error {
 	//	return err
 	//}
 	//// Build the benchmark wrapper.
-	if err := cfg.GoTool().BuildPath(bcfg.BenchDir, filepath.Join(bcfg.BinDir, "cockroachdb-bench")); err != nil {
+	if err := goTool(cfg, bcfg, "cockroachdb-bench").BuildPath(bcfg.BenchDir, filepath.Join(bcfg.BinDir, "cockroachdb-bench")); err != nil {
 		return err
 	}
 	cmd := exec.Command("ls")
@@ -72,7 +71,7 @@ func (h CockroachDB) Build(cfg *common.Config, bcfg *common.BuildConfig) error {
 	//	fmt.Printf("darryl: error=%s\n", err.Error())
 	//	return err
 	//}
-	if output, err := cmd.CombinedOutput(); err != nil {
+	if output, err := common.CommandCombinedOutput(cmd); err != nil {
 		fmt.Printf("Output: %s, error: %s\n", string(output[:]), err.Error())
 		return err
 	}
@@ -82,7 +81,7 @@ func (h CockroachDB) Build(cfg *common.Config, bcfg *common.BuildConfig) error {
 	//	fmt.Printf("darryl: error=%s\n", err.Error())
 	//	return err
 	//}
-	if output, err := cmd.CombinedOutput(); err != nil {
+	if output, err := common.CommandCombinedOutput(cmd); err != nil {
 		fmt.Printf("Output: %s, error: %s\n", string(output[:]), err.Error())
 		return err
 	}
@@ -107,8 +106,7 @@ func (h CockroachDB) Run(cfg *common.Config, rcfg *common.RunConfig) error {
 		cmd.Env = cfg.ExecEnv.Collapse()
 		cmd.Stdout = rcfg.Results
 		cmd.Stderr = rcfg.Results
-		log.TraceCommand(cmd, false)
-		if err := cmd.Run(); err != nil {
+		if err := common.RunCommand(cmd); err != nil {
 			return err
 		}
 		// Delete tmp because cockroachdb will have written something there and
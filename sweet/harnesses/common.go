@@ -5,52 +5,79 @@
 package harnesses
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 
+	"golang.org/x/benchmarks/sweet/common"
 	"golang.org/x/benchmarks/sweet/common/fileutil"
-	"golang.org/x/benchmarks/sweet/common/log"
 )
 
 func gitShallowClone(dir, url, ref string) error {
 	cmd := exec.Command("git", "clone", "--depth", "1", "-b", ref, url, dir)
-	log.TraceCommand(cmd, false)
-	_, err := cmd.Output()
+	_, err := common.CommandOutput(cmd)
 	return err
 }
 
 func gitCloneToCommit(dir, url, branch, hash string) error {
 	cloneCmd := exec.Command("git", "clone", "-b", branch, url, dir)
-	log.TraceCommand(cloneCmd, false)
-	if _, err := cloneCmd.Output(); err != nil {
+	if _, err := common.CommandOutput(cloneCmd); err != nil {
 		return err
 	}
 	checkoutCmd := exec.Command("git", "-C", dir, "checkout", hash)
-	log.TraceCommand(checkoutCmd, false)
-	_, err := checkoutCmd.Output()
+	_, err := common.CommandOutput(checkoutCmd)
 	return err
 }
 
 func copyFile(dst, src string) error {
-	log.CommandPrintf("cp %s %s", src, dst)
-	return fileutil.CopyFile(dst, src, nil, nil)
+	return common.Do(fmt.Sprintf("cp %s %s", src, dst), func() error {
+		return fileutil.CopyFile(dst, src, nil, nil)
+	})
 }
 
 func makeWriteable(dir string) error {
-	log.CommandPrintf("chmod -R a+w %s", dir)
-	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.Mode()&0222 == 0222 {
-			return nil
-		}
-		return os.Chmod(path, info.Mode()|0222)
+	return common.Do(fmt.Sprintf("chmod -R a+w %s", dir), func() error {
+		return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.Mode()&0222 == 0222 {
+				return nil
+			}
+			return os.Chmod(path, info.Mode()|0222)
+		})
+	})
+}
+
+// copyDir copies the contents of the directory src into dst.
+func copyDir(dst, src string) error {
+	return common.Do(fmt.Sprintf("cp -r %s/* %s", src, dst), func() error {
+		return fileutil.CopyDir(dst, src, nil)
 	})
 }
 
 func symlink(dst, src string) error {
-	log.CommandPrintf("ln -s %s %s", src, dst)
-	return os.Symlink(src, dst)
+	return common.Do(fmt.Sprintf("ln -s %s %s", src, dst), func() error {
+		return os.Symlink(src, dst)
+	})
+}
+
+// pgoEnv returns env with GOFLAGS set up to build the binary called bin
+// with the profile bcfg specifies for it.
+func pgoEnv(env *common.Env, bcfg *common.BuildConfig, bin string) *common.Env {
+	goflags, ok := env.Lookup("GOFLAGS")
+	if ok {
+		goflags += " "
+	}
+	goflags += "-pgo=" + bcfg.PGOFile(bin)
+	return env.MustSet("GOFLAGS=" + goflags)
+}
+
+// goTool returns the Go tool for cfg, set up to build the binary called
+// bin with the profile bcfg specifies for it.
+func goTool(cfg *common.Config, bcfg *common.BuildConfig, bin string) *common.Go {
+	g := cfg.GoTool()
+	g.Env = pgoEnv(g.Env, bcfg, bin)
+	return g
 }
@@ -5,12 +5,12 @@
 package harnesses
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 
 	"golang.org/x/benchmarks/sweet/common"
-	"golang.org/x/benchmarks/sweet/common/log"
 )
 
 type Etcd struct{}
@@ -34,35 +34,22 @@ func (h Etcd) Get(gcfg *common.GetConfig) error {
 }
 
 func (h Etcd) Build(cfg *common.Config, bcfg *common.BuildConfig) error {
-	env := cfg.BuildEnv.Env
-
-	// Add the Go tool to PATH, since etcd's Makefile doesn't provide enough
-	// visibility into how etcd is built to allow us to pass this information
-	// directly. Also set the GOROOT explicitly because it might have propagated
-	// differently from the environment.
-	env = env.Prefix("PATH", filepath.Join(cfg.GoRoot, "bin")+":")
-	env = env.MustSet("GOROOT=" + cfg.GoRoot)
-
-	cmd := exec.Command("make", "-C", bcfg.SrcDir, "build")
-	cmd.Env = env.Collapse()
-	log.TraceCommand(cmd, false)
-	// Call Output here to get an *ExitError with a populated Stderr field.
-	if _, err := cmd.Output(); err != nil {
-		return err
-	}
-	// Note that no matter what we do, the build script insists on putting the
-	// binaries into the source directory, so copy the one we care about into
-	// BinDir.
-	if err := copyFile(filepath.Join(bcfg.BinDir, "etcd"), filepath.Join(bcfg.SrcDir, "bin", "etcd")); err != nil {
+	// Build the etcd server directly, rather than through etcd's Makefile,
+	// which builds all its binaries in one go build invocation and so
+	// can't give the server a profile of its own. This is what the
+	// Makefile's build script does for the server, minus the version
+	// information in its ldflags.
+	serverPkg := filepath.Join(bcfg.SrcDir, "server")
+	if err := goTool(cfg, bcfg, "etcd").BuildPath(serverPkg, filepath.Join(bcfg.BinDir, "etcd")); err != nil {
 		return err
 	}
 	// Build etcd's benchmarking tool. Our benchmark is just a wrapper around that.
 	benchmarkPkg := filepath.Join(bcfg.SrcDir, "tools", "benchmark")
-	if err := cfg.GoTool().BuildPath(benchmarkPkg, filepath.Join(bcfg.BinDir, "benchmark")); err != nil {
+	if err := goTool(cfg, bcfg, "benchmark").BuildPath(benchmarkPkg, filepath.Join(bcfg.BinDir, "benchmark")); err != nil {
 		return err
 	}
 	// Build the benchmark wrapper.
-	return cfg.GoTool().BuildPath(bcfg.BenchDir, filepath.Join(bcfg.BinDir, "etcd-bench"))
+	return goTool(cfg, bcfg, "etcd-bench").BuildPath(bcfg.BenchDir, filepath.Join(bcfg.BinDir, "etcd-bench"))
 }
 
 func (h Etcd) Run(cfg *common.Config, rcfg *common.RunConfig) error {
@@ -83,8 +70,7 @@ func (h Etcd) Run(cfg *common.Config, rcfg *common.RunConfig) error {
 		cmd.Env = cfg.ExecEnv.Collapse()
 		cmd.Stdout = rcfg.Results
 		cmd.Stderr = rcfg.Results
-		log.TraceCommand(cmd, false)
-		if err := cmd.Run(); err != nil {
+		if err := common.RunCommand(cmd); err != nil {
 			return err
 		}
 		// Delete tmp because etcd will have written something there and
@@ -97,15 +83,16 @@ func (h Etcd) Run(cfg *common.Config, rcfg *common.RunConfig) error {
 }
 
 func rmDirContents(dir string) error {
-	log.CommandPrintf("rm -rf %s/*", dir)
-	fs, err := os.ReadDir(dir)
-	if err != nil {
-		return err
-	}
-	for _, fi := range fs {
-		if err := os.RemoveAll(filepath.Join(dir, fi.Name())); err != nil {
+	return common.Do(fmt.Sprintf("rm -rf %s/*", dir), func() error {
+		fs, err := os.ReadDir(dir)
+		if err != nil {
 			return err
 		}
-	}
-	return nil
+		for _, fi := range fs {
+			if err := os.RemoveAll(filepath.Join(dir, fi.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
@@ -10,8 +10,6 @@ import (
 	"path/filepath"
 
 	"golang.org/x/benchmarks/sweet/common"
-	"golang.org/x/benchmarks/sweet/common/fileutil"
-	"golang.org/x/benchmarks/sweet/common/log"
 )
 
 type buildBenchmark struct {
@@ -79,25 +77,38 @@ func (h GoBuild) Get(gcfg *common.GetConfig) error {
 	return nil
 }
 
-func (h GoBuild) Build(pcfg *common.Config, bcfg *common.BuildConfig) error {
+// buildGoRoot copies pcfg's GOROOT into bcfg.BinDir, where Run
+// expects it, and rebuilds the compiler and linker in it, and returns a
+// copy of pcfg that uses it.
+func buildGoRoot(pcfg *common.Config, bcfg *common.BuildConfig) (*common.Config, error) {
 	// Local copy of config for updating GOROOT.
 	cfg := pcfg.Copy()
 
-	// Get the benchmarks we're going to build.
-	benchmarks := goBuildBenchmarks(bcfg.Short)
-
 	// cfg.GoRoot is our source toolchain. We need to rebuild cmd/compile
 	// and cmd/link with cfg.BuildEnv to apply any configured build options
 	// (e.g., PGO).
 	//
 	// Do so by `go install`ing them into a copied GOROOT.
 	goroot := filepath.Join(bcfg.BinDir, "goroot")
-	if err := fileutil.CopyDir(goroot, cfg.GoRoot, nil); err != nil {
-		return fmt.Errorf("error copying GOROOT: %v", err)
+	if err := copyDir(goroot, cfg.GoRoot); err != nil {
+		return nil, fmt.Errorf("error copying GOROOT: %v", err)
 	}
 	cfg.GoRoot = goroot
-	if err := cfg.GoTool().Do("", "install", "cmd/compile", "cmd/link"); err != nil {
-		return fmt.Errorf("error building cmd/compile and cmd/link: %v", err)
+	for _, tool := range []string{"compile", "link"} {
+		if err := goTool(cfg, bcfg, tool).Do("", "install", "cmd/"+tool); err != nil {
+			return nil, fmt.Errorf("error building cmd/%s: %v", tool, err)
+		}
+	}
+	return cfg, nil
+}
+
+func (h GoBuild) Build(pcfg *common.Config, bcfg *common.BuildConfig) error {
+	// Get the benchmarks we're going to build.
+	benchmarks := goBuildBenchmarks(bcfg.Short)
+
+	cfg, err := buildGoRoot(pcfg, bcfg)
+	if err != nil {
+		return err
 	}
 
 	for _, bench := range benchmarks {
@@ -127,7 +138,7 @@ func (h GoBuild) Build(pcfg *common.Config, bcfg *common.BuildConfig) error {
 		}
 	}
 
-	if err := cfg.GoTool().BuildPath(bcfg.BenchDir, filepath.Join(bcfg.BinDir, "go-build-bench")); err != nil {
+	if err := goTool(cfg, bcfg, "go-build-bench").BuildPath(bcfg.BenchDir, filepath.Join(bcfg.BinDir, "go-build-bench")); err != nil {
 		return fmt.Errorf("error building go-build tool: %w", err)
 	}
 	return nil
@@ -136,7 +147,7 @@ func (h GoBuild) Build(pcfg *common.Config, bcfg *common.BuildConfig) error {
 func (h GoBuild) Run(pcfg *common.Config, rcfg *common.RunConfig) error {
 	// Local copy of config for updating GOROOT.
 	cfg := pcfg.Copy()
-	cfg.GoRoot = filepath.Join(rcfg.BinDir, "goroot") // see Build, above.
+	cfg.GoRoot = filepath.Join(rcfg.BinDir, "goroot") // see buildGoRoot, above.
 
 	benchmarks := goBuildBenchmarks(rcfg.Short)
 	for _, bench := range benchmarks {
@@ -151,8 +162,7 @@ func (h GoBuild) Run(pcfg *common.Config, rcfg *common.RunConfig) error {
 		cmd.Env = cfg.ExecEnv.Collapse()
 		cmd.Stdout = rcfg.Results
 		cmd.Stderr = rcfg.Results
-		log.TraceCommand(cmd, false)
-		if err := cmd.Run(); err != nil {
+		if err := common.RunCommand(cmd); err != nil {
 			return err
 		}
 	}
@@ -12,7 +12,6 @@ import (
 	"runtime"
 
 	"golang.org/x/benchmarks/sweet/common"
-	"golang.org/x/benchmarks/sweet/common/log"
 )
 
 type GVisor struct{}
@@ -35,7 +34,7 @@ func (h GVisor) Get(gcfg *common.GetConfig) error {
 
 func (h GVisor) Build(cfg *common.Config, bcfg *common.BuildConfig) error {
 	// Build benchmarking client which will handle a bunch of coordination.
-	if err := cfg.GoTool().BuildPath(filepath.Join(bcfg.BenchDir), filepath.Join(bcfg.BinDir, "gvisor-bench")); err != nil {
+	if err := goTool(cfg, bcfg, "gvisor-bench").BuildPath(filepath.Join(bcfg.BenchDir), filepath.Join(bcfg.BinDir, "gvisor-bench")); err != nil {
 		return err
 	}
 
@@ -43,17 +42,18 @@ func (h GVisor) Build(cfg *common.Config, bcfg *common.BuildConfig) error {
 	// See https://github.com/google/gvisor#using-go-get.
 	cfg.BuildEnv.Env = cfg.BuildEnv.MustSet("CGO_ENABLED=0")
 	bin := filepath.Join(bcfg.BinDir, "runsc")
-	if err := cfg.GoTool().BuildPath(filepath.Join(bcfg.SrcDir, "runsc"), bin); err != nil {
+	if err := goTool(cfg, bcfg, "runsc").BuildPath(filepath.Join(bcfg.SrcDir, "runsc"), bin); err != nil {
 		return err
 	}
 
 	// Make sure the binary has all the right permissions set.
 	// See https://gvisor.dev/docs/user_guide/install/#install-directly
-	log.CommandPrintf("chmod 755 %s", bin)
-	if err := os.Chmod(bin, 0755); err != nil {
-		return fmt.Errorf("failed to set permissions on runsc: %w", err)
-	}
-	return nil
+	return common.Do(fmt.Sprintf("chmod 755 %s", bin), func() error {
+		if err := os.Chmod(bin, 0755); err != nil {
+			return fmt.Errorf("failed to set permissions on runsc: %w", err)
+		}
+		return nil
+	})
 }
 
 func (h GVisor) Run(cfg *common.Config, rcfg *common.RunConfig) error {
@@ -72,6 +72,5 @@ func (h GVisor) Run(cfg *common.Config, rcfg *common.RunConfig) error {
 	cmd.Env = cfg.ExecEnv.Collapse()
 	cmd.Stdout = rcfg.Results
 	cmd.Stderr = rcfg.Results
-	log.TraceCommand(cmd, false)
-	return cmd.Run()
+	return common.RunCommand(cmd)
 }
@@ -9,7 +9,6 @@ import (
 	"path/filepath"
 
 	"golang.org/x/benchmarks/sweet/common"
-	"golang.org/x/benchmarks/sweet/common/log"
 )
 
 type localBenchHarness struct {
@@ -28,7 +27,7 @@ func (h *localBenchHarness) Get(_ *common.GetConfig) error {
 }
 
 func (h *localBenchHarness) Build(cfg *common.Config, bcfg *common.BuildConfig) error {
-	return cfg.GoTool().BuildPath(bcfg.BenchDir, filepath.Join(bcfg.BinDir, h.binName))
+	return goTool(cfg, bcfg, h.binName).BuildPath(bcfg.BenchDir, filepath.Join(bcfg.BinDir, h.binName))
 }
 
 func (h *localBenchHarness) Run(cfg *common.Config, rcfg *common.RunConfig) error {
@@ -46,8 +45,7 @@ func (h *localBenchHarness) Run(cfg *common.Config, rcfg *common.RunConfig) erro
 		cmd.Stdout = rcfg.Results
 	}
 	cmd.Stderr = rcfg.Results
-	log.TraceCommand(cmd, false)
-	return cmd.Run()
+	return common.RunCommand(cmd)
 }
 
 func BiogoIgor() common.Harness {
@@ -122,3 +120,33 @@ func Markdown() common.Harness {
 		},
 	}
 }
+
+// shortArgs returns the arguments of benchmarks that take no arguments
+// except -short.
+func shortArgs(cfg *common.Config, rcfg *common.RunConfig) []string {
+	if rcfg.Short {
+		return []string{"-short"}
+	}
+	return nil
+}
+
+func Garbage() common.Harness {
+	return &localBenchHarness{
+		binName: "garbage-bench",
+		genArgs: shortArgs,
+	}
+}
+
+func JSON() common.Harness {
+	return &localBenchHarness{
+		binName: "json-bench",
+		genArgs: shortArgs,
+	}
+}
+
+func HTTP() common.Harness {
+	return &localBenchHarness{
+		binName: "http-bench",
+		genArgs: shortArgs,
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"path/filepath"
 
 	"golang.org/x/benchmarks/sweet/common"
-	"golang.org/x/benchmarks/sweet/common/log"
 )
 
 const (
@@ -31,29 +30,16 @@ func (h Tile38) Get(gcfg *common.GetConfig) error {
 }
 
 func (h Tile38) Build(cfg *common.Config, bcfg *common.BuildConfig) error {
-	env := cfg.BuildEnv.Env
-
-	// Add the Go tool to PATH, since tile38's Makefile doesn't provide enough
-	// visibility into how tile38 is built to allow us to pass this information
-	// directly. Also set the GOROOT explicitly because it might have propagated
-	// differently from the environment.
-	env = env.Prefix("PATH", filepath.Join(cfg.GoRoot, "bin")+":")
-	env = env.MustSet("GOROOT=" + cfg.GoRoot)
-
-	cmd := exec.Command("make", "-C", bcfg.SrcDir)
-	cmd.Env = env.Collapse()
-	log.TraceCommand(cmd, false)
-	// Call Output here to get an *ExitError with a populated Stderr field.
-	if _, err := cmd.Output(); err != nil {
-		return err
-	}
-	// Note that no matter what we do, the build script insists on putting the
-	// binaries into the source directory, so copy the one we care about into
-	// BinDir.
-	if err := copyFile(filepath.Join(bcfg.BinDir, server), filepath.Join(bcfg.SrcDir, server)); err != nil {
+	// Build the server directly, rather than through tile38's Makefile,
+	// which builds all its binaries with the same flags and so can't
+	// give the server a profile of its own. This is what the Makefile's
+	// build script does for the server, minus the version information in
+	// its ldflags.
+	serverPkg := filepath.Join(bcfg.SrcDir, "cmd", server)
+	if err := goTool(cfg, bcfg, server).BuildPath(serverPkg, filepath.Join(bcfg.BinDir, server)); err != nil {
 		return err
 	}
-	return cfg.GoTool().BuildPath(bcfg.BenchDir, filepath.Join(bcfg.BinDir, "tile38-bench"))
+	return goTool(cfg, bcfg, "tile38-bench").BuildPath(bcfg.BenchDir, filepath.Join(bcfg.BinDir, "tile38-bench"))
 }
 
 func (h Tile38) Run(cfg *common.Config, rcfg *common.RunConfig) error {
@@ -86,6 +72,5 @@ func (h Tile38) Run(cfg *common.Config, rcfg *common.RunConfig) error {
 	cmd.Env = cfg.ExecEnv.Collapse()
 	cmd.Stdout = rcfg.Results
 	cmd.Stderr = rcfg.Results
-	log.TraceCommand(cmd, false)
-	return cmd.Run()
+	return common.RunCommand(cmd)
 }